@@ -17,6 +17,21 @@ type (
 		JWT      JWTConfig  `mapstructure:"jwt"`
 		PSQL     PSQLConfig `mapstructure:"psql"`
 		Firebase Firebase   `mapstructure:"firebase"`
+		OTP      OTPConfig  `mapstructure:"otp"`
+		Redis    Redis      `mapstructure:"redis"`
+
+		Disbursement DisbursementConfig `mapstructure:"disbursement"`
+		Payment      PaymentConfig      `mapstructure:"payment"`
+		Payout       PayoutConfig       `mapstructure:"payout"`
+		Refund       RefundConfig       `mapstructure:"refund"`
+		Expiry       ExpiryConfig       `mapstructure:"expiry"`
+		Inspection   InspectionConfig   `mapstructure:"inspection"`
+		Dispute      DisputeConfig      `mapstructure:"dispute"`
+		Notification NotificationConfig `mapstructure:"notification"`
+		Attachment   AttachmentConfig   `mapstructure:"attachment"`
+		Shipment     ShipmentConfig     `mapstructure:"shipment"`
+		Outbox       OutboxConfig       `mapstructure:"outbox"`
+		Webhook      WebhookConfig      `mapstructure:"webhook"`
 	}
 
 	AppConfig struct {
@@ -27,6 +42,10 @@ type (
 	JWTConfig struct {
 		AccessToken  TokenConfig `mapstructure:"access_token"`
 		RefreshToken TokenConfig `mapstructure:"refresh_token"`
+
+		// RevocationCacheTTL is how long a token known to be not revoked is cached,
+		// revocation from another replica takes effect after at most this duration.
+		RevocationCacheTTL time.Duration `mapstructure:"revocation_cache_ttl"`
 	}
 
 	TokenConfig struct {
@@ -47,6 +66,199 @@ type (
 		APIKey  string `mapstructure:"api_key"`
 		AuthURL string `mapstructure:"url"`
 	}
+
+	OTPConfig struct {
+		// Provider is either firebase or local, local provider needs no network and is meant for development and testing.
+		Provider string         `mapstructure:"provider"`
+		Local    LocalOTPConfig `mapstructure:"local"`
+
+		// VerifiedStore keeps verified OTP until register or login consumes it, either postgres, redis or memory.
+		// Memory is not shared between replicas and is lost on restart.
+		VerifiedStore string        `mapstructure:"verified_store"`
+		VerifiedTTL   time.Duration `mapstructure:"verified_ttl"`
+
+		RateLimit OTPRateLimitConfig `mapstructure:"rate_limit"`
+	}
+
+	LocalOTPConfig struct {
+		TTL time.Duration `mapstructure:"ttl"`
+	}
+
+	// OTPRateLimitConfig limits sending and verifying OTP, a zero limit or window disables that limit.
+	OTPRateLimitConfig struct {
+		// Store keeps the counters, either postgres, redis or memory.
+		Store string `mapstructure:"store"`
+
+		SendCooldown     time.Duration `mapstructure:"send_cooldown"`
+		SendWindow       time.Duration `mapstructure:"send_window"`
+		MaxSendsPerPhone int           `mapstructure:"max_sends_per_phone"`
+		MaxSendsPerIP    int           `mapstructure:"max_sends_per_ip"`
+
+		// MaxFailedAttempts attempts within Lockout without a successful one lock the phone number out
+		// until Lockout has passed since the first attempt.
+		MaxFailedAttempts int           `mapstructure:"max_failed_attempts"`
+		Lockout           time.Duration `mapstructure:"lockout"`
+		VerifyWindow      time.Duration `mapstructure:"verify_window"`
+		MaxVerifiesPerIP  int           `mapstructure:"max_verifies_per_ip"`
+	}
+
+	DisbursementConfig struct {
+		// Provider is the disbursement provider used for account inquiry, only local is supported for now.
+		Provider string                  `mapstructure:"provider"`
+		Local    LocalDisbursementConfig `mapstructure:"local"`
+	}
+
+	LocalDisbursementConfig struct {
+		// Accounts are the bank accounts known by the fake bank.
+		Accounts []LocalBankAccount `mapstructure:"accounts"`
+	}
+
+	LocalBankAccount struct {
+		BankCode   string `mapstructure:"bank_code"`
+		Number     string `mapstructure:"number"`
+		HolderName string `mapstructure:"holder_name"`
+	}
+
+	PaymentConfig struct {
+		// Provider is the payment gateway, only local is supported for now.
+		Provider string `mapstructure:"provider"`
+		// CallbackSecret is shared with the provider to sign payment callbacks.
+		CallbackSecret string `mapstructure:"callback_secret"`
+		// ChargeTTL is how long a virtual account or QRIS charge can be paid.
+		ChargeTTL time.Duration `mapstructure:"charge_ttl"`
+	}
+
+	PayoutConfig struct {
+		// Interval is how often due payouts are picked up.
+		Interval    time.Duration `mapstructure:"interval"`
+		MaxAttempts int           `mapstructure:"max_attempts"`
+		// Backoff is the delay before the first retry, it doubles on every failed attempt.
+		Backoff time.Duration `mapstructure:"backoff"`
+	}
+
+	RefundConfig struct {
+		// Interval is how often due refunds are picked up.
+		Interval    time.Duration `mapstructure:"interval"`
+		MaxAttempts int           `mapstructure:"max_attempts"`
+		// Backoff is the delay before the first retry, it doubles on every failed attempt.
+		Backoff time.Duration `mapstructure:"backoff"`
+	}
+
+	DisputeConfig struct {
+		// Interval is how often disputes past their deadline are escalated.
+		Interval time.Duration `mapstructure:"interval"`
+		// ResponseWindow is how long the seller has to respond to a dispute, 72 hours when empty.
+		ResponseWindow time.Duration `mapstructure:"response_window"`
+	}
+
+	ExpiryConfig struct {
+		// Interval is how often overdue transactions are expired.
+		Interval time.Duration `mapstructure:"interval"`
+		// ApprovalWindow is how long a transaction waits to be accepted or rejected.
+		ApprovalWindow time.Duration `mapstructure:"approval_window"`
+		// PaymentWindow is how long an accepted transaction waits for payment.
+		PaymentWindow time.Duration `mapstructure:"payment_window"`
+	}
+
+	InspectionConfig struct {
+		// Interval is how often transactions past their inspection period are released.
+		Interval time.Duration `mapstructure:"interval"`
+		// Period is how long the buyer has to confirm after the seller marks done or the goods are
+		// delivered, zero disables auto release.
+		Period       time.Duration `mapstructure:"period"`
+		RemindBefore time.Duration `mapstructure:"remind_before"`
+		// DeliveryTimeout is how long after the seller marks a shipped transaction done the
+		// inspection starts without the courier reporting the delivery, zero waits for the courier.
+		DeliveryTimeout time.Duration `mapstructure:"delivery_timeout"`
+	}
+
+	NotificationConfig struct {
+		Provider string `mapstructure:"provider"`
+	}
+
+	AttachmentConfig struct {
+		// Storage is local or s3.
+		Storage string `mapstructure:"storage"`
+		// MaxSize is the largest file accepted in bytes.
+		MaxSize int64                 `mapstructure:"max_size"`
+		Local   LocalAttachmentConfig `mapstructure:"local"`
+		S3      S3AttachmentConfig    `mapstructure:"s3"`
+	}
+
+	LocalAttachmentConfig struct {
+		Dir string `mapstructure:"dir"`
+	}
+
+	S3AttachmentConfig struct {
+		// Endpoint of any S3 compatible storage, e.g. http://localhost:9000 for MinIO.
+		Endpoint  string `mapstructure:"endpoint"`
+		Region    string `mapstructure:"region"`
+		Bucket    string `mapstructure:"bucket"`
+		AccessKey string `mapstructure:"access_key"`
+		SecretKey string `mapstructure:"secret_key"`
+	}
+
+	ShipmentConfig struct {
+		// Interval is how often shipments in transit are tracked.
+		Interval time.Duration `mapstructure:"interval"`
+		// CheckEvery is how long a shipment is left alone after it is tracked.
+		CheckEvery time.Duration `mapstructure:"check_every"`
+		Courier    CourierConfig `mapstructure:"courier"`
+	}
+
+	CourierConfig struct {
+		Provider string             `mapstructure:"provider"`
+		Local    LocalCourierConfig `mapstructure:"local"`
+	}
+
+	LocalCourierConfig struct {
+		// DeliverAfter is how long after it is first tracked a shipment is reported delivered.
+		DeliverAfter time.Duration `mapstructure:"deliver_after"`
+	}
+
+	OutboxConfig struct {
+		// Interval is how often pending messages are published.
+		Interval    time.Duration `mapstructure:"interval"`
+		MaxAttempts int           `mapstructure:"max_attempts"`
+		// Backoff is the delay before the first retry, it doubles on every failed attempt.
+		Backoff   time.Duration   `mapstructure:"backoff"`
+		Publisher PublisherConfig `mapstructure:"publisher"`
+	}
+
+	PublisherConfig struct {
+		// Provider is log, webhook or nats.
+		Provider string                 `mapstructure:"provider"`
+		Webhook  WebhookPublisherConfig `mapstructure:"webhook"`
+		NATS     NATSPublisherConfig    `mapstructure:"nats"`
+	}
+
+	WebhookPublisherConfig struct {
+		URL     string        `mapstructure:"url"`
+		Timeout time.Duration `mapstructure:"timeout"`
+	}
+
+	NATSPublisherConfig struct {
+		Addr string `mapstructure:"addr"`
+		// SubjectPrefix is prepended to the topic, e.g. rekber gives rekber.transaction.accept.
+		SubjectPrefix string        `mapstructure:"subject_prefix"`
+		Timeout       time.Duration `mapstructure:"timeout"`
+	}
+
+	WebhookConfig struct {
+		// Interval is how often due deliveries to partner endpoints are sent.
+		Interval    time.Duration `mapstructure:"interval"`
+		MaxAttempts int           `mapstructure:"max_attempts"`
+		// Backoff is the delay before the first retry, it doubles on every failed attempt.
+		Backoff time.Duration `mapstructure:"backoff"`
+		// Timeout is how long an endpoint has to respond.
+		Timeout time.Duration `mapstructure:"timeout"`
+	}
+
+	Redis struct {
+		Addr     string `mapstructure:"addr"`
+		Password string `mapstructure:"password"`
+		DB       int    `mapstructure:"db"`
+	}
 )
 
 func Get() *Config {
@@ -0,0 +1,57 @@
+package courier
+
+import (
+	"context"
+	"rekber/internal/transaction"
+	"sync"
+	"time"
+)
+
+// LocalTracker is a stub courier tracker for development and testing. It does not call any
+// courier, a shipment is picked up the first time it is tracked and delivered deliverAfter later.
+type LocalTracker struct {
+	deliverAfter time.Duration
+
+	mu        sync.Mutex
+	firstSeen map[string]time.Time
+}
+
+func (l *LocalTracker) Track(ctx context.Context, s transaction.Shipping) (transaction.Tracking, error) {
+	pickedUpAt := l.pickedUpAt(s)
+	tr := transaction.Tracking{
+		Events: []transaction.TrackingEvent{
+			{Status: "picked_up", Description: "shipment is picked up by " + s.CourierCode, OccurredAt: pickedUpAt},
+		},
+	}
+
+	deliveredAt := pickedUpAt.Add(l.deliverAfter)
+	if time.Now().Before(deliveredAt) {
+		return tr, nil
+	}
+
+	tr.Events = append(tr.Events, transaction.TrackingEvent{Status: "delivered", Description: "shipment is delivered to the recipient", OccurredAt: deliveredAt})
+	tr.Delivered = true
+	tr.DeliveredAt = deliveredAt
+	return tr, nil
+}
+
+func (l *LocalTracker) pickedUpAt(s transaction.Shipping) time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := s.CourierCode + "/" + s.AirwayBill
+	t, ok := l.firstSeen[key]
+	if !ok {
+		t = time.Now()
+		l.firstSeen[key] = t
+	}
+
+	return t
+}
+
+func NewLocalTracker(deliverAfter time.Duration) *LocalTracker {
+	return &LocalTracker{
+		deliverAfter: deliverAfter,
+		firstSeen:    make(map[string]time.Time),
+	}
+}
@@ -0,0 +1,57 @@
+package disbursement
+
+import (
+	"context"
+	"rekber/ierr"
+	"rekber/internal/transaction"
+)
+
+// LocalClient is a fake disbursement provider for development and testing, it knows only the
+// accounts it is given and never calls a bank.
+type LocalClient struct {
+	accounts map[string]string // bank code and number to holder name
+}
+
+func (c *LocalClient) InquireAccount(ctx context.Context, bankCode, number string) (string, error) {
+	holderName, ok := c.accounts[accountKey(bankCode, number)]
+	if !ok {
+		return "", ierr.BankAccountInquiryNotFound{BankCode: bankCode, Number: number}
+	}
+
+	return holderName, nil
+}
+
+// Disburse pretends to transfer the payout, it only succeeds for accounts the fake bank knows.
+func (c *LocalClient) Disburse(ctx context.Context, p transaction.Payout) (string, error) {
+	if _, ok := c.accounts[accountKey(p.BankCode, p.AccountNumber)]; !ok {
+		return "", ierr.BankAccountInquiryNotFound{BankCode: p.BankCode, Number: p.AccountNumber}
+	}
+
+	// derived from the payout id, so retrying the same payout gives the same transfer
+	return "local-" + p.ID.String(), nil
+}
+
+func accountKey(bankCode, number string) string {
+	return bankCode + ":" + number
+}
+
+func NewLocalClient(options ...LocalOptions) *LocalClient {
+	c := &LocalClient{
+		accounts: make(map[string]string),
+	}
+
+	for _, opt := range options {
+		opt(c)
+	}
+
+	return c
+}
+
+type LocalOptions func(c *LocalClient)
+
+// WithAccount registers a bank account the fake bank knows about.
+func WithAccount(bankCode, number, holderName string) LocalOptions {
+	return func(c *LocalClient) {
+		c.accounts[accountKey(bankCode, number)] = holderName
+	}
+}
@@ -4,14 +4,10 @@ import (
 	"context"
 	"fmt"
 	"rekber/firebase/auth"
-	"rekber/ierr"
-	"sync"
 )
 
 type Client struct {
-	auth  *auth.Client
-	cache map[string]interface{} // TODO: implement with redis
-	mu    sync.Mutex
+	auth *auth.Client
 }
 
 func (c *Client) VerifyOTP(ctx context.Context, phoneNumber, otp, sessionInfo string) error {
@@ -39,34 +35,8 @@ func (c *Client) SendOTP(ctx context.Context, phoneNumber, captcha string) (stri
 	return sessionInfo.SessionInfo, nil
 }
 
-func (c *Client) SaveVerifiedOTP(ctx context.Context, phoneNumber string, state int) error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	c.cache[phoneNumber] = state
-	return nil
-}
-
-func (c *Client) GetVerifiedOTP(ctx context.Context, phoneNumber string, state int) error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	earlierState, ok := c.cache[phoneNumber].(int)
-	if !ok {
-		return ierr.UserForbiddenAccess{PhoneNumber: phoneNumber}
-	}
-
-	if earlierState != state {
-		return ierr.UserForbiddenAccess{PhoneNumber: phoneNumber}
-	}
-
-	return nil
-}
-
 func NewClient(APIKey string, options ...Options) *Client {
-	c := Client{
-		cache: make(map[string]interface{}),
-	}
+	c := Client{}
 
 	for _, opt := range options {
 		opt(APIKey, &c)
@@ -0,0 +1,151 @@
+package attachment
+
+import (
+	"context"
+	"fmt"
+	"io"
+	httpHandler "rekber/http"
+	"rekber/ierr"
+	"rekber/internal/attachment"
+	"rekber/internal/transaction"
+	"rekber/internal/user"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type Service interface {
+	Upload(ctx context.Context, userID, transactionID uuid.UUID, req attachment.UploadRequest) (attachment.Response, error)
+	List(ctx context.Context, transactionID uuid.UUID) ([]attachment.Response, error)
+	Download(ctx context.Context, transactionID, id uuid.UUID) (attachment.Response, io.ReadCloser, error)
+}
+
+// TransactionService tells whether the user may access the transaction, it returns
+// ierr.TransactionNotFound for a user who may not.
+type TransactionService interface {
+	Get(ctx context.Context, userID, id uuid.UUID) (transaction.Response, error)
+}
+
+type Handler struct {
+	svc            Service
+	transactionSvc TransactionService
+	authMiddleware fiber.Handler
+}
+
+func (h Handler) InitRouter(r fiber.Router) {
+	attachmentGroup := r.Group("/transactions/:id/attachments")
+	attachmentGroup.Post("/", h.authMiddleware, h.transactionAccess, h.Upload)
+	attachmentGroup.Get("/", h.authMiddleware, h.transactionAccess, h.List)
+	attachmentGroup.Get("/:attachmentID", h.authMiddleware, h.transactionAccess, h.Download)
+}
+
+// transactionAccess only lets the buyer, the seller and admins of the transaction through.
+func (h Handler) transactionAccess(c *fiber.Ctx) error {
+	id, err := transactionID(c)
+	if err != nil {
+		return err
+	}
+
+	if _, err := h.transactionSvc.Get(c.Context(), userID(c), id); err != nil {
+		return fmt.Errorf("failed when calling transaction service: %w", err)
+	}
+
+	return c.Next()
+}
+
+func (h Handler) Upload(c *fiber.Ctx) error {
+	id, err := transactionID(c)
+	if err != nil {
+		return err
+	}
+
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return ierr.AttachmentNotValid{Reason: "file is required"}
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open uploaded file: %w", err)
+	}
+	defer f.Close()
+
+	resp, err := h.svc.Upload(c.Context(), userID(c), id, attachment.UploadRequest{
+		Purpose:  c.FormValue("purpose"),
+		FileName: fh.Filename,
+		Size:     fh.Size,
+		Content:  f,
+	})
+	if err != nil {
+		return fmt.Errorf("failed when calling attachment service: %w", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(httpHandler.JSONResponse{
+		Message: "successfully upload attachment",
+		Data:    resp,
+	})
+}
+
+func (h Handler) List(c *fiber.Ctx) error {
+	id, err := transactionID(c)
+	if err != nil {
+		return err
+	}
+
+	resp, err := h.svc.List(c.Context(), id)
+	if err != nil {
+		return fmt.Errorf("failed when calling attachment service: %w", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(httpHandler.JSONResponse{
+		Message: "successfully get attachments",
+		Data:    resp,
+	})
+}
+
+func (h Handler) Download(c *fiber.Ctx) error {
+	id, err := transactionID(c)
+	if err != nil {
+		return err
+	}
+
+	attachmentID, err := uuid.Parse(c.Params("attachmentID"))
+	if err != nil {
+		return ierr.AttachmentNotFound{}
+	}
+
+	resp, content, err := h.svc.Download(c.Context(), id, attachmentID)
+	if err != nil {
+		return fmt.Errorf("failed when calling attachment service: %w", err)
+	}
+
+	c.Set(fiber.HeaderContentType, resp.ContentType)
+	c.Set(fiber.HeaderContentDisposition, "attachment; filename="+strconv.Quote(resp.FileName))
+	// the content type is detected on upload, browsers must not guess another one
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+
+	// the stream is closed by fiber once it is sent
+	return c.Status(fiber.StatusOK).SendStream(content, int(resp.Size))
+}
+
+func userID(c *fiber.Ctx) uuid.UUID {
+	return c.Locals("user-data").(user.User).ID
+}
+
+func transactionID(c *fiber.Ctx) (uuid.UUID, error) {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return uuid.Nil, ierr.TransactionIDNotValid{ID: c.Params("id")}
+	}
+
+	return id, nil
+}
+
+func NewHandler(svc Service, transactionSvc TransactionService, authMiddleware fiber.Handler) *Handler {
+	return &Handler{
+		svc:            svc,
+		transactionSvc: transactionSvc,
+		authMiddleware: authMiddleware,
+	}
+}
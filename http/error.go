@@ -2,7 +2,9 @@ package http
 
 import (
 	"errors"
+	"math"
 	"rekber/ierr"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -18,6 +20,11 @@ func ErrorHandler(c *fiber.Ctx, err error) error {
 		code = e.HTTPStatusCode()
 		message = e.HTTPMessage()
 
+		var r ierr.RetryAfterHandler
+		if errors.As(err, &r) {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(r.RetryAfter().Seconds()))))
+		}
+
 		return c.Status(code).JSON(JSONResponse{
 			Error: message,
 		})
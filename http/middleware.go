@@ -1,34 +1,74 @@
 package http
 
 import (
+	"context"
+	"fmt"
 	"rekber/ierr"
 	"rekber/internal/token"
+	"rekber/internal/user"
+	"rekber/requestid"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
-func AuthMiddleware(c *fiber.Ctx) error {
-	authHeader := c.Get("authorization")
-	if authHeader == "" {
-		return ierr.AuthorizationHeaderNotFound{}
-	}
+type RevocationList interface {
+	IsRevoked(ctx context.Context, t user.AccessToken) (bool, error)
+}
 
-	receiveToken := strings.Split(authHeader, " ")
-	if len(receiveToken) != 2 {
-		return ierr.TokenIsNotProvided{}
-	}
+// NewAuthMiddleware only lets through requests with a valid access token which has not been revoked.
+func NewAuthMiddleware(revocationList RevocationList) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		authHeader := c.Get("authorization")
+		if authHeader == "" {
+			return ierr.AuthorizationHeaderNotFound{}
+		}
 
-	if strings.ToLower(receiveToken[0]) != "bearer" {
-		return ierr.TokenIsNotProvided{}
-	}
+		receiveToken := strings.Split(authHeader, " ")
+		if len(receiveToken) != 2 {
+			return ierr.TokenIsNotProvided{}
+		}
+
+		if strings.ToLower(receiveToken[0]) != "bearer" {
+			return ierr.TokenIsNotProvided{}
+		}
+
+		t := token.NewToken(token.WithAccessToken(receiveToken[1]))
+		accessToken, err := t.ParseAccessTokenClaims()
+		if err != nil {
+			return err
+		}
 
-	t := token.NewToken(token.WithAccessToken(receiveToken[1]))
-	userData, err := t.ParseAccessToken()
-	if err != nil {
-		return err
+		revoked, err := revocationList.IsRevoked(c.Context(), accessToken)
+		if err != nil {
+			return fmt.Errorf("failed to check access token revocation: %w", err)
+		}
+
+		if revoked {
+			return ierr.JWTError{}
+		}
+
+		c.Locals("user-data", user.User{ID: accessToken.UserID})
+		c.Locals("access-token", accessToken)
+		return c.Next()
 	}
+}
 
-	c.Locals("user-data", userData)
-	return c.Next()
+const maxRequestIDLength = 64
+
+// NewRequestIDMiddleware tags every request with an ID, the one sent by the client in the
+// X-Request-ID header or a new one, and echoes it back so a request can be traced in the logs
+// and the transaction timeline.
+func NewRequestIDMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Get(fiber.HeaderXRequestID)
+		if id == "" || len(id) > maxRequestIDLength {
+			id = uuid.NewString()
+		}
+
+		c.Set(fiber.HeaderXRequestID, id)
+		c.Locals(requestid.Key, id)
+		return c.Next()
+	}
 }
@@ -0,0 +1,304 @@
+package transaction
+
+import (
+	"context"
+	"fmt"
+	httpHandler "rekber/http"
+	"rekber/ierr"
+	"rekber/internal/ledger"
+	"rekber/internal/transaction"
+	"rekber/internal/user"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const signatureHeader = "X-Callback-Signature"
+
+type Service interface {
+	Create(ctx context.Context, userID uuid.UUID, req transaction.CreateRequest) (transaction.Response, error)
+	Get(ctx context.Context, userID, id uuid.UUID) (transaction.Response, error)
+	List(ctx context.Context, userID uuid.UUID) ([]transaction.Response, error)
+	Accept(ctx context.Context, userID, id uuid.UUID) (transaction.Response, error)
+	Reject(ctx context.Context, userID, id uuid.UUID, req transaction.RejectRequest) (transaction.Response, error)
+	Pay(ctx context.Context, userID, id uuid.UUID, req transaction.PayRequest) (transaction.PaymentResponse, error)
+	HandlePaymentCallback(ctx context.Context, body []byte, signature string) error
+	ListRefundDuePayments(ctx context.Context, userID uuid.UUID) ([]transaction.PaymentResponse, error)
+	Done(ctx context.Context, userID, id uuid.UUID, req transaction.DoneRequest) (transaction.Response, error)
+	Confirm(ctx context.Context, userID, id uuid.UUID) (transaction.Response, error)
+	OpenDispute(ctx context.Context, userID, id uuid.UUID, req transaction.DisputeRequest) (transaction.Response, error)
+	RespondDispute(ctx context.Context, userID, id uuid.UUID, req transaction.RespondDisputeRequest) (transaction.Response, error)
+	ResolveDispute(ctx context.Context, userID, id uuid.UUID, req transaction.ResolveDisputeRequest) (transaction.Response, error)
+	ListEscalatedDisputes(ctx context.Context, userID uuid.UUID) ([]transaction.Response, error)
+	Ledger(ctx context.Context, userID, id uuid.UUID) (ledger.Response, error)
+	Timeline(ctx context.Context, userID, id uuid.UUID) ([]transaction.TimelineEventResponse, error)
+}
+
+type Handler struct {
+	svc            Service
+	authMiddleware fiber.Handler
+}
+
+func (h Handler) InitRouter(r fiber.Router) {
+	trxGroup := r.Group("/transactions", h.authMiddleware)
+	trxGroup.Post("/", h.Create)
+	trxGroup.Get("/", h.List)
+	trxGroup.Get("/disputes/escalated", h.ListEscalatedDisputes)
+	trxGroup.Get("/:id", h.Get)
+	trxGroup.Post("/:id/accept", h.Accept)
+	trxGroup.Post("/:id/reject", h.Reject)
+	trxGroup.Post("/:id/pay", h.Pay)
+	trxGroup.Post("/:id/done", h.Done)
+	trxGroup.Post("/:id/confirm", h.Confirm)
+	trxGroup.Post("/:id/dispute", h.OpenDispute)
+	trxGroup.Post("/:id/dispute/respond", h.RespondDispute)
+	trxGroup.Post("/:id/dispute/resolve", h.ResolveDispute)
+	trxGroup.Get("/:id/ledger", h.Ledger)
+	trxGroup.Get("/:id/timeline", h.Timeline)
+
+	// called by the payment provider, it is authenticated by the signature instead of access token
+	r.Post("/payments/callback", h.PaymentCallback)
+	r.Get("/payments/refund-due", h.authMiddleware, h.ListRefundDuePayments)
+}
+
+func (h Handler) Create(c *fiber.Ctx) error {
+	var req transaction.CreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fmt.Errorf("failed to parse body: %w", err)
+	}
+
+	resp, err := h.svc.Create(c.Context(), userID(c), req)
+	if err != nil {
+		return fmt.Errorf("failed when calling transaction service: %w", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(httpHandler.JSONResponse{
+		Message: "successfully create transaction",
+		Data:    resp,
+	})
+}
+
+func (h Handler) List(c *fiber.Ctx) error {
+	resp, err := h.svc.List(c.Context(), userID(c))
+	if err != nil {
+		return fmt.Errorf("failed when calling transaction service: %w", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(httpHandler.JSONResponse{
+		Message: "successfully get transactions",
+		Data:    resp,
+	})
+}
+
+// ListEscalatedDisputes lists the disputes the seller did not respond to in time, for admins to resolve.
+func (h Handler) ListEscalatedDisputes(c *fiber.Ctx) error {
+	resp, err := h.svc.ListEscalatedDisputes(c.Context(), userID(c))
+	if err != nil {
+		return fmt.Errorf("failed when calling transaction service: %w", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(httpHandler.JSONResponse{
+		Message: "successfully get escalated disputes",
+		Data:    resp,
+	})
+}
+
+func (h Handler) Get(c *fiber.Ctx) error {
+	id, err := transactionID(c)
+	if err != nil {
+		return err
+	}
+
+	resp, err := h.svc.Get(c.Context(), userID(c), id)
+	if err != nil {
+		return fmt.Errorf("failed when calling transaction service: %w", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(httpHandler.JSONResponse{
+		Message: "successfully get transaction",
+		Data:    resp,
+	})
+}
+
+func (h Handler) Accept(c *fiber.Ctx) error {
+	return h.act(c, "accept", h.svc.Accept)
+}
+
+func (h Handler) Reject(c *fiber.Ctx) error {
+	var req transaction.RejectRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fmt.Errorf("failed to parse body: %w", err)
+	}
+
+	return h.act(c, "reject", func(ctx context.Context, userID, id uuid.UUID) (transaction.Response, error) {
+		return h.svc.Reject(ctx, userID, id, req)
+	})
+}
+
+func (h Handler) Pay(c *fiber.Ctx) error {
+	id, err := transactionID(c)
+	if err != nil {
+		return err
+	}
+
+	var req transaction.PayRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fmt.Errorf("failed to parse body: %w", err)
+	}
+
+	resp, err := h.svc.Pay(c.Context(), userID(c), id, req)
+	if err != nil {
+		return fmt.Errorf("failed when calling transaction service: %w", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(httpHandler.JSONResponse{
+		Message: "successfully create payment",
+		Data:    resp,
+	})
+}
+
+func (h Handler) PaymentCallback(c *fiber.Ctx) error {
+	if err := h.svc.HandlePaymentCallback(c.Context(), c.Body(), c.Get(signatureHeader)); err != nil {
+		return fmt.Errorf("failed when calling transaction service: %w", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(httpHandler.JSONResponse{
+		Message: "successfully handle payment callback",
+	})
+}
+
+// ListRefundDuePayments lists the payments which arrived too late to pay their transaction, for
+// admins to refund.
+func (h Handler) ListRefundDuePayments(c *fiber.Ctx) error {
+	resp, err := h.svc.ListRefundDuePayments(c.Context(), userID(c))
+	if err != nil {
+		return fmt.Errorf("failed when calling transaction service: %w", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(httpHandler.JSONResponse{
+		Message: "successfully get refund due payments",
+		Data:    resp,
+	})
+}
+
+// Done marks the transaction done, the body is optional when the goods are not shipped.
+func (h Handler) Done(c *fiber.Ctx) error {
+	var req transaction.DoneRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return fmt.Errorf("failed to parse body: %w", err)
+		}
+	}
+
+	return h.act(c, "mark done", func(ctx context.Context, userID, id uuid.UUID) (transaction.Response, error) {
+		return h.svc.Done(ctx, userID, id, req)
+	})
+}
+
+func (h Handler) Confirm(c *fiber.Ctx) error {
+	return h.act(c, "confirm", h.svc.Confirm)
+}
+
+func (h Handler) OpenDispute(c *fiber.Ctx) error {
+	var req transaction.DisputeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fmt.Errorf("failed to parse body: %w", err)
+	}
+
+	return h.act(c, "open dispute on", func(ctx context.Context, userID, id uuid.UUID) (transaction.Response, error) {
+		return h.svc.OpenDispute(ctx, userID, id, req)
+	})
+}
+
+func (h Handler) RespondDispute(c *fiber.Ctx) error {
+	var req transaction.RespondDisputeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fmt.Errorf("failed to parse body: %w", err)
+	}
+
+	return h.act(c, "respond dispute on", func(ctx context.Context, userID, id uuid.UUID) (transaction.Response, error) {
+		return h.svc.RespondDispute(ctx, userID, id, req)
+	})
+}
+
+func (h Handler) ResolveDispute(c *fiber.Ctx) error {
+	var req transaction.ResolveDisputeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fmt.Errorf("failed to parse body: %w", err)
+	}
+
+	return h.act(c, "resolve dispute on", func(ctx context.Context, userID, id uuid.UUID) (transaction.Response, error) {
+		return h.svc.ResolveDispute(ctx, userID, id, req)
+	})
+}
+
+func (h Handler) Ledger(c *fiber.Ctx) error {
+	id, err := transactionID(c)
+	if err != nil {
+		return err
+	}
+
+	resp, err := h.svc.Ledger(c.Context(), userID(c), id)
+	if err != nil {
+		return fmt.Errorf("failed when calling transaction service: %w", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(httpHandler.JSONResponse{
+		Message: "successfully get transaction ledger",
+		Data:    resp,
+	})
+}
+
+func (h Handler) Timeline(c *fiber.Ctx) error {
+	id, err := transactionID(c)
+	if err != nil {
+		return err
+	}
+
+	resp, err := h.svc.Timeline(c.Context(), userID(c), id)
+	if err != nil {
+		return fmt.Errorf("failed when calling transaction service: %w", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(httpHandler.JSONResponse{
+		Message: "successfully get transaction timeline",
+		Data:    resp,
+	})
+}
+
+func (h Handler) act(c *fiber.Ctx, action string, fn func(ctx context.Context, userID, id uuid.UUID) (transaction.Response, error)) error {
+	id, err := transactionID(c)
+	if err != nil {
+		return err
+	}
+
+	resp, err := fn(c.Context(), userID(c), id)
+	if err != nil {
+		return fmt.Errorf("failed when calling transaction service: %w", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(httpHandler.JSONResponse{
+		Message: fmt.Sprintf("successfully %s transaction", action),
+		Data:    resp,
+	})
+}
+
+func userID(c *fiber.Ctx) uuid.UUID {
+	return c.Locals("user-data").(user.User).ID
+}
+
+func transactionID(c *fiber.Ctx) (uuid.UUID, error) {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return uuid.Nil, ierr.TransactionIDNotValid{ID: c.Params("id")}
+	}
+
+	return id, nil
+}
+
+func NewHandler(svc Service, authMiddleware fiber.Handler) *Handler {
+	return &Handler{
+		svc:            svc,
+		authMiddleware: authMiddleware,
+	}
+}
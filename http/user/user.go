@@ -5,30 +5,53 @@ import (
 	"fmt"
 	"net/http"
 	httpHandler "rekber/http"
-	internalHttp "rekber/http"
+	"rekber/ierr"
+	"rekber/internal/token"
 	"rekber/internal/user"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
 type Service interface {
 	Login(ctx context.Context, req user.LoginRequest) (user.LoginResponse, error)
 	Register(ctx context.Context, req user.RegisterRequest) error
+	SendOTP(ctx context.Context, req user.SendOTPRequest) (user.SendOTPResponse, error)
+	VerifyOTP(ctx context.Context, req user.VerifyOTP) error
+	RefreshToken(ctx context.Context, claims user.RefreshToken) (user.LoginResponse, error)
+	Logout(ctx context.Context, accessToken user.AccessToken, refreshToken *user.RefreshToken) error
+	LogoutAll(ctx context.Context, accessToken user.AccessToken) error
+	ListBanks(ctx context.Context) ([]user.BankResponse, error)
+	AddBankAccount(ctx context.Context, userID uuid.UUID, req user.AddBankAccountRequest) (user.BankAccountResponse, error)
+	ListBankAccounts(ctx context.Context, userID uuid.UUID) ([]user.BankAccountResponse, error)
+	SetPrimaryBankAccount(ctx context.Context, userID, id uuid.UUID) error
+	RemoveBankAccount(ctx context.Context, userID, id uuid.UUID) error
+	VerifyBankAccount(ctx context.Context, userID, id uuid.UUID) (user.BankAccountResponse, error)
 }
 
 type Handler struct {
-	svc Service
+	svc            Service
+	authMiddleware fiber.Handler
 }
 
 func (h Handler) InitRouter(r fiber.Router) {
 	userGroup := r.Group("/user")
 	userGroup.Post("/login", h.Login)
 	userGroup.Post("/register", h.Register)
-	userGroup.Get("/restricted", internalHttp.AuthMiddleware, func(c *fiber.Ctx) error {
-		userData := c.Locals("userData-data").(user.User)
-
+	userGroup.Post("/otp/send", h.SendOTP)
+	userGroup.Post("/otp/verify", h.VerifyOTP)
+	userGroup.Post("/token/refresh", h.RefreshToken)
+	userGroup.Post("/logout", h.authMiddleware, h.Logout)
+	userGroup.Post("/logout/all", h.authMiddleware, h.LogoutAll)
+	userGroup.Get("/banks", h.ListBanks)
+	userGroup.Post("/bank-accounts", h.authMiddleware, h.AddBankAccount)
+	userGroup.Get("/bank-accounts", h.authMiddleware, h.ListBankAccounts)
+	userGroup.Put("/bank-accounts/:id/primary", h.authMiddleware, h.SetPrimaryBankAccount)
+	userGroup.Delete("/bank-accounts/:id", h.authMiddleware, h.RemoveBankAccount)
+	userGroup.Post("/bank-accounts/:id/verify", h.authMiddleware, h.VerifyBankAccount)
+	userGroup.Get("/restricted", h.authMiddleware, func(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
-			"test": "hello" + userData.ID.String(),
+			"test": "hello" + userID(c).String(),
 		})
 	})
 }
@@ -65,8 +88,205 @@ func (h Handler) Register(c *fiber.Ctx) error {
 	})
 }
 
-func NewHandler(svc Service) *Handler {
+func (h Handler) SendOTP(c *fiber.Ctx) error {
+	var req user.SendOTPRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fmt.Errorf("failed to parse body: %w", err)
+	}
+
+	req.IP = c.IP()
+	resp, err := h.svc.SendOTP(c.Context(), req)
+	if err != nil {
+		return fmt.Errorf("failed when calling user service: %w", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(httpHandler.JSONResponse{
+		Message: "successfully send otp",
+		Data:    resp,
+	})
+}
+
+func (h Handler) VerifyOTP(c *fiber.Ctx) error {
+	var req user.VerifyOTP
+	if err := c.BodyParser(&req); err != nil {
+		return fmt.Errorf("failed to parse body: %w", err)
+	}
+
+	req.IP = c.IP()
+	if err := h.svc.VerifyOTP(c.Context(), req); err != nil {
+		return fmt.Errorf("failed when calling user service: %w", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(httpHandler.JSONResponse{
+		Message: "successfully verify otp",
+	})
+}
+
+func (h Handler) RefreshToken(c *fiber.Ctx) error {
+	var req user.RefreshTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fmt.Errorf("failed to parse body: %w", err)
+	}
+
+	claims, err := token.NewToken(token.WithRefreshToken(req.RefreshToken)).ParseRefreshToken()
+	if err != nil {
+		return err
+	}
+
+	resp, err := h.svc.RefreshToken(c.Context(), claims)
+	if err != nil {
+		return fmt.Errorf("failed when calling user service: %w", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(httpHandler.JSONResponse{
+		Message: "successfully refresh token",
+		Data:    resp,
+	})
+}
+
+func (h Handler) Logout(c *fiber.Ctx) error {
+	var req user.LogoutRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return fmt.Errorf("failed to parse body: %w", err)
+		}
+	}
+
+	var refreshToken *user.RefreshToken
+	if req.RefreshToken != "" {
+		claims, err := token.NewToken(token.WithRefreshToken(req.RefreshToken)).ParseRefreshToken()
+		if err != nil {
+			return err
+		}
+
+		refreshToken = &claims
+	}
+
+	accessToken := c.Locals("access-token").(user.AccessToken)
+	if err := h.svc.Logout(c.Context(), accessToken, refreshToken); err != nil {
+		return fmt.Errorf("failed when calling user service: %w", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(httpHandler.JSONResponse{
+		Message: "successfully logout",
+	})
+}
+
+func (h Handler) LogoutAll(c *fiber.Ctx) error {
+	accessToken := c.Locals("access-token").(user.AccessToken)
+	if err := h.svc.LogoutAll(c.Context(), accessToken); err != nil {
+		return fmt.Errorf("failed when calling user service: %w", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(httpHandler.JSONResponse{
+		Message: "successfully logout from all devices",
+	})
+}
+
+func (h Handler) ListBanks(c *fiber.Ctx) error {
+	resp, err := h.svc.ListBanks(c.Context())
+	if err != nil {
+		return fmt.Errorf("failed when calling user service: %w", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(httpHandler.JSONResponse{
+		Message: "successfully get banks",
+		Data:    resp,
+	})
+}
+
+func (h Handler) AddBankAccount(c *fiber.Ctx) error {
+	var req user.AddBankAccountRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fmt.Errorf("failed to parse body: %w", err)
+	}
+
+	resp, err := h.svc.AddBankAccount(c.Context(), userID(c), req)
+	if err != nil {
+		return fmt.Errorf("failed when calling user service: %w", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(httpHandler.JSONResponse{
+		Message: "successfully add bank account",
+		Data:    resp,
+	})
+}
+
+func (h Handler) ListBankAccounts(c *fiber.Ctx) error {
+	resp, err := h.svc.ListBankAccounts(c.Context(), userID(c))
+	if err != nil {
+		return fmt.Errorf("failed when calling user service: %w", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(httpHandler.JSONResponse{
+		Message: "successfully get bank accounts",
+		Data:    resp,
+	})
+}
+
+func (h Handler) SetPrimaryBankAccount(c *fiber.Ctx) error {
+	id, err := bankAccountID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.svc.SetPrimaryBankAccount(c.Context(), userID(c), id); err != nil {
+		return fmt.Errorf("failed when calling user service: %w", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(httpHandler.JSONResponse{
+		Message: "successfully set primary bank account",
+	})
+}
+
+func (h Handler) RemoveBankAccount(c *fiber.Ctx) error {
+	id, err := bankAccountID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.svc.RemoveBankAccount(c.Context(), userID(c), id); err != nil {
+		return fmt.Errorf("failed when calling user service: %w", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(httpHandler.JSONResponse{
+		Message: "successfully remove bank account",
+	})
+}
+
+func (h Handler) VerifyBankAccount(c *fiber.Ctx) error {
+	id, err := bankAccountID(c)
+	if err != nil {
+		return err
+	}
+
+	resp, err := h.svc.VerifyBankAccount(c.Context(), userID(c), id)
+	if err != nil {
+		return fmt.Errorf("failed when calling user service: %w", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(httpHandler.JSONResponse{
+		Message: "successfully verify bank account",
+		Data:    resp,
+	})
+}
+
+func userID(c *fiber.Ctx) uuid.UUID {
+	return c.Locals("user-data").(user.User).ID
+}
+
+func bankAccountID(c *fiber.Ctx) (uuid.UUID, error) {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return uuid.Nil, ierr.BankAccountIDNotValid{ID: c.Params("id")}
+	}
+
+	return id, nil
+}
+
+func NewHandler(svc Service, authMiddleware fiber.Handler) *Handler {
 	return &Handler{
-		svc: svc,
+		svc:            svc,
+		authMiddleware: authMiddleware,
 	}
 }
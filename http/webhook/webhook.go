@@ -0,0 +1,135 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	httpHandler "rekber/http"
+	"rekber/ierr"
+	"rekber/internal/user"
+	"rekber/internal/webhook"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type Service interface {
+	RegisterEndpoint(ctx context.Context, userID uuid.UUID, req webhook.RegisterEndpointRequest) (webhook.EndpointResponse, error)
+	ListEndpoints(ctx context.Context, userID uuid.UUID) ([]webhook.EndpointResponse, error)
+	DisableEndpoint(ctx context.Context, userID, id uuid.UUID) (webhook.EndpointResponse, error)
+	ListDeliveries(ctx context.Context, userID, endpointID uuid.UUID) ([]webhook.DeliveryResponse, error)
+	Redeliver(ctx context.Context, userID, id uuid.UUID) (webhook.DeliveryResponse, error)
+}
+
+type Handler struct {
+	svc            Service
+	authMiddleware fiber.Handler
+}
+
+func (h Handler) InitRouter(r fiber.Router) {
+	webhookGroup := r.Group("/webhooks", h.authMiddleware)
+	webhookGroup.Post("/endpoints", h.RegisterEndpoint)
+	webhookGroup.Get("/endpoints", h.ListEndpoints)
+	webhookGroup.Delete("/endpoints/:id", h.DisableEndpoint)
+	webhookGroup.Get("/endpoints/:id/deliveries", h.ListDeliveries)
+	webhookGroup.Post("/deliveries/:id/redeliver", h.Redeliver)
+}
+
+func (h Handler) RegisterEndpoint(c *fiber.Ctx) error {
+	var req webhook.RegisterEndpointRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fmt.Errorf("failed to parse body: %w", err)
+	}
+
+	resp, err := h.svc.RegisterEndpoint(c.Context(), userID(c), req)
+	if err != nil {
+		return fmt.Errorf("failed when calling webhook service: %w", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(httpHandler.JSONResponse{
+		Message: "successfully register webhook endpoint",
+		Data:    resp,
+	})
+}
+
+func (h Handler) ListEndpoints(c *fiber.Ctx) error {
+	resp, err := h.svc.ListEndpoints(c.Context(), userID(c))
+	if err != nil {
+		return fmt.Errorf("failed when calling webhook service: %w", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(httpHandler.JSONResponse{
+		Message: "successfully get webhook endpoints",
+		Data:    resp,
+	})
+}
+
+func (h Handler) DisableEndpoint(c *fiber.Ctx) error {
+	id, err := endpointID(c)
+	if err != nil {
+		return err
+	}
+
+	resp, err := h.svc.DisableEndpoint(c.Context(), userID(c), id)
+	if err != nil {
+		return fmt.Errorf("failed when calling webhook service: %w", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(httpHandler.JSONResponse{
+		Message: "successfully disable webhook endpoint",
+		Data:    resp,
+	})
+}
+
+func (h Handler) ListDeliveries(c *fiber.Ctx) error {
+	id, err := endpointID(c)
+	if err != nil {
+		return err
+	}
+
+	resp, err := h.svc.ListDeliveries(c.Context(), userID(c), id)
+	if err != nil {
+		return fmt.Errorf("failed when calling webhook service: %w", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(httpHandler.JSONResponse{
+		Message: "successfully get webhook deliveries",
+		Data:    resp,
+	})
+}
+
+func (h Handler) Redeliver(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return ierr.WebhookDeliveryNotFound{}
+	}
+
+	resp, err := h.svc.Redeliver(c.Context(), userID(c), id)
+	if err != nil {
+		return fmt.Errorf("failed when calling webhook service: %w", err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(httpHandler.JSONResponse{
+		Message: "successfully queue webhook redelivery",
+		Data:    resp,
+	})
+}
+
+func userID(c *fiber.Ctx) uuid.UUID {
+	return c.Locals("user-data").(user.User).ID
+}
+
+func endpointID(c *fiber.Ctx) (uuid.UUID, error) {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return uuid.Nil, ierr.WebhookEndpointNotFound{}
+	}
+
+	return id, nil
+}
+
+func NewHandler(svc Service, authMiddleware fiber.Handler) *Handler {
+	return &Handler{
+		svc:            svc,
+		authMiddleware: authMiddleware,
+	}
+}
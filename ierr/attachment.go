@@ -0,0 +1,72 @@
+package ierr
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+type AttachmentNotValid struct {
+	Reason string
+}
+
+func (u AttachmentNotValid) Error() string {
+	return fmt.Sprintf("attachment is not valid because %s", u.Reason)
+}
+
+func (u AttachmentNotValid) HTTPStatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (u AttachmentNotValid) HTTPMessage() string {
+	return u.Error()
+}
+
+type AttachmentTooLarge struct {
+	MaxSize int64
+}
+
+func (u AttachmentTooLarge) Error() string {
+	return fmt.Sprintf("attachment is larger than %d bytes", u.MaxSize)
+}
+
+func (u AttachmentTooLarge) HTTPStatusCode() int {
+	return http.StatusRequestEntityTooLarge
+}
+
+func (u AttachmentTooLarge) HTTPMessage() string {
+	return u.Error()
+}
+
+type AttachmentTypeNotAllowed struct {
+	ContentType string
+}
+
+func (u AttachmentTypeNotAllowed) Error() string {
+	return fmt.Sprintf("attachment of type %s is not allowed, only jpeg, png, webp and pdf are", u.ContentType)
+}
+
+func (u AttachmentTypeNotAllowed) HTTPStatusCode() int {
+	return http.StatusUnsupportedMediaType
+}
+
+func (u AttachmentTypeNotAllowed) HTTPMessage() string {
+	return u.Error()
+}
+
+type AttachmentNotFound struct {
+	ID uuid.UUID
+}
+
+func (u AttachmentNotFound) Error() string {
+	return fmt.Sprintf("attachment with id %s not found", u.ID.String())
+}
+
+func (u AttachmentNotFound) HTTPStatusCode() int {
+	return http.StatusNotFound
+}
+
+func (u AttachmentNotFound) HTTPMessage() string {
+	return u.Error()
+}
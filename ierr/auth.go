@@ -29,3 +29,17 @@ func (u TokenIsNotProvided) HTTPStatusCode() int {
 func (u TokenIsNotProvided) HTTPMessage() string {
 	return u.Error()
 }
+
+type RefreshTokenReused struct{}
+
+func (u RefreshTokenReused) Error() string {
+	return "refresh token has already been used, please login again"
+}
+
+func (u RefreshTokenReused) HTTPStatusCode() int {
+	return http.StatusUnauthorized
+}
+
+func (u RefreshTokenReused) HTTPMessage() string {
+	return u.Error()
+}
@@ -0,0 +1,154 @@
+package ierr
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+type BankNotFound struct {
+	Code string `json:"code"`
+}
+
+func (u BankNotFound) Error() string {
+	return fmt.Sprintf("bank with code %s not found", u.Code)
+}
+
+func (u BankNotFound) HTTPStatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (u BankNotFound) HTTPMessage() string {
+	return u.Error()
+}
+
+type BankAccountNotFound struct {
+	ID uuid.UUID `json:"id"`
+}
+
+func (u BankAccountNotFound) Error() string {
+	return fmt.Sprintf("bank account with id %s not found", u.ID)
+}
+
+func (u BankAccountNotFound) HTTPStatusCode() int {
+	return http.StatusNotFound
+}
+
+func (u BankAccountNotFound) HTTPMessage() string {
+	return u.Error()
+}
+
+type BankAccountNotValid struct {
+	Reason string `json:"reason"`
+}
+
+func (u BankAccountNotValid) Error() string {
+	return fmt.Sprintf("bank account is not valid: %s", u.Reason)
+}
+
+func (u BankAccountNotValid) HTTPStatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (u BankAccountNotValid) HTTPMessage() string {
+	return u.Error()
+}
+
+type BankAccountAlreadyExists struct {
+	BankCode string `json:"bank_code"`
+	Number   string `json:"number"`
+}
+
+func (u BankAccountAlreadyExists) Error() string {
+	return fmt.Sprintf("bank account %s at bank %s already exists", u.Number, u.BankCode)
+}
+
+func (u BankAccountAlreadyExists) HTTPStatusCode() int {
+	return http.StatusConflict
+}
+
+func (u BankAccountAlreadyExists) HTTPMessage() string {
+	return u.Error()
+}
+
+type BankAccountInUse struct {
+	ID uuid.UUID `json:"id"`
+}
+
+func (u BankAccountInUse) Error() string {
+	return fmt.Sprintf("bank account with id %s has a payout pending, it can be removed once the payout is done", u.ID.String())
+}
+
+func (u BankAccountInUse) HTTPStatusCode() int {
+	return http.StatusConflict
+}
+
+func (u BankAccountInUse) HTTPMessage() string {
+	return u.Error()
+}
+
+type BankAccountIDNotValid struct {
+	ID string `json:"id"`
+}
+
+func (u BankAccountIDNotValid) Error() string {
+	return fmt.Sprintf("bank account id %s is not valid", u.ID)
+}
+
+func (u BankAccountIDNotValid) HTTPStatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (u BankAccountIDNotValid) HTTPMessage() string {
+	return u.Error()
+}
+
+type BankAccountInquiryNotFound struct {
+	BankCode string `json:"bank_code"`
+	Number   string `json:"number"`
+}
+
+func (u BankAccountInquiryNotFound) Error() string {
+	return fmt.Sprintf("bank account %s is not found at bank %s", u.Number, u.BankCode)
+}
+
+func (u BankAccountInquiryNotFound) HTTPStatusCode() int {
+	return http.StatusUnprocessableEntity
+}
+
+func (u BankAccountInquiryNotFound) HTTPMessage() string {
+	return u.Error()
+}
+
+type BankAccountHolderNameMismatch struct {
+	ID uuid.UUID `json:"id"`
+}
+
+func (u BankAccountHolderNameMismatch) Error() string {
+	return fmt.Sprintf("name of bank account %s does not match the account holder name at the bank", u.ID)
+}
+
+func (u BankAccountHolderNameMismatch) HTTPStatusCode() int {
+	return http.StatusUnprocessableEntity
+}
+
+func (u BankAccountHolderNameMismatch) HTTPMessage() string {
+	return u.Error()
+}
+
+type BankAccountNotVerified struct {
+	ID uuid.UUID `json:"id"`
+}
+
+func (u BankAccountNotVerified) Error() string {
+	return fmt.Sprintf("bank account %s is not verified, verify it before making it primary while a paid transaction is not paid out yet", u.ID)
+}
+
+func (u BankAccountNotVerified) HTTPStatusCode() int {
+	return http.StatusUnprocessableEntity
+}
+
+func (u BankAccountNotVerified) HTTPMessage() string {
+	return u.Error()
+}
@@ -1,6 +1,13 @@
 package ierr
 
+import "time"
+
 type HTTPErrorHandler interface {
 	HTTPStatusCode() int
 	HTTPMessage() string
 }
+
+// RetryAfterHandler is implemented by errors which tell the client when to retry.
+type RetryAfterHandler interface {
+	RetryAfter() time.Duration
+}
@@ -2,7 +2,9 @@ package ierr
 
 import (
 	"fmt"
+	"math"
 	"net/http"
+	"time"
 )
 
 type InvalidOTP struct {
@@ -21,3 +23,82 @@ func (u InvalidOTP) HTTPStatusCode() int {
 func (u InvalidOTP) HTTPMessage() string {
 	return u.Error()
 }
+
+type InvalidOTPState struct {
+	State int `json:"state"`
+}
+
+func (u InvalidOTPState) Error() string {
+	return fmt.Sprintf("invalid OTP state %d", u.State)
+}
+
+func (u InvalidOTPState) HTTPStatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (u InvalidOTPState) HTTPMessage() string {
+	return u.Error()
+}
+
+type OTPExpired struct {
+	PhoneNumber string `json:"phone_number"`
+}
+
+func (u OTPExpired) Error() string {
+	return fmt.Sprintf("OTP code for phone number %v is expired or already used", u.PhoneNumber)
+}
+
+func (u OTPExpired) HTTPStatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (u OTPExpired) HTTPMessage() string {
+	return u.Error()
+}
+
+type OTPSendLimited struct {
+	PhoneNumber string        `json:"phone_number"`
+	Retry       time.Duration `json:"retry_after"`
+}
+
+func (u OTPSendLimited) Error() string {
+	return fmt.Sprintf("too many OTP requests for phone number %v, retry after %d seconds", u.PhoneNumber, retryAfterSeconds(u.Retry))
+}
+
+func (u OTPSendLimited) HTTPStatusCode() int {
+	return http.StatusTooManyRequests
+}
+
+func (u OTPSendLimited) HTTPMessage() string {
+	return u.Error()
+}
+
+func (u OTPSendLimited) RetryAfter() time.Duration {
+	return u.Retry
+}
+
+type OTPVerifyLocked struct {
+	PhoneNumber string        `json:"phone_number"`
+	Retry       time.Duration `json:"retry_after"`
+}
+
+func (u OTPVerifyLocked) Error() string {
+	return fmt.Sprintf("too many failed OTP attempts for phone number %v, retry after %d seconds", u.PhoneNumber, retryAfterSeconds(u.Retry))
+}
+
+func (u OTPVerifyLocked) HTTPStatusCode() int {
+	return http.StatusTooManyRequests
+}
+
+func (u OTPVerifyLocked) HTTPMessage() string {
+	return u.Error()
+}
+
+func (u OTPVerifyLocked) RetryAfter() time.Duration {
+	return u.Retry
+}
+
+// retryAfterSeconds rounds up so the client does not retry a moment too early.
+func retryAfterSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
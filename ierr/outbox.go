@@ -0,0 +1,19 @@
+package ierr
+
+import (
+	"net/http"
+)
+
+type OutboxMessageNotFound struct{}
+
+func (u OutboxMessageNotFound) Error() string {
+	return "outbox message not found"
+}
+
+func (u OutboxMessageNotFound) HTTPStatusCode() int {
+	return http.StatusNotFound
+}
+
+func (u OutboxMessageNotFound) HTTPMessage() string {
+	return u.Error()
+}
@@ -0,0 +1,135 @@
+package ierr
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PaymentMethodNotValid struct {
+	Method string `json:"method"`
+}
+
+func (u PaymentMethodNotValid) Error() string {
+	return fmt.Sprintf("payment method %s is not valid, it must be virtual_account or qris", u.Method)
+}
+
+func (u PaymentMethodNotValid) HTTPStatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (u PaymentMethodNotValid) HTTPMessage() string {
+	return u.Error()
+}
+
+type PaymentNotFound struct {
+	ExternalID    string    `json:"external_id,omitempty"`
+	TransactionID uuid.UUID `json:"transaction_id,omitempty"`
+}
+
+func (u PaymentNotFound) Error() string {
+	if u.ExternalID != "" {
+		return fmt.Sprintf("payment with external id %s not found", u.ExternalID)
+	}
+
+	return fmt.Sprintf("payment of transaction %s not found", u.TransactionID)
+}
+
+func (u PaymentNotFound) HTTPStatusCode() int {
+	return http.StatusNotFound
+}
+
+func (u PaymentNotFound) HTTPMessage() string {
+	return u.Error()
+}
+
+type PaymentSignatureNotValid struct{}
+
+func (u PaymentSignatureNotValid) Error() string {
+	return "payment callback signature is not valid"
+}
+
+func (u PaymentSignatureNotValid) HTTPStatusCode() int {
+	return http.StatusUnauthorized
+}
+
+func (u PaymentSignatureNotValid) HTTPMessage() string {
+	return u.Error()
+}
+
+type PaymentAmountMismatch struct {
+	ExternalID string `json:"external_id"`
+	Expected   int64  `json:"expected"`
+	Actual     int64  `json:"actual"`
+}
+
+func (u PaymentAmountMismatch) Error() string {
+	return fmt.Sprintf("payment %s amount %d does not match the charged amount %d", u.ExternalID, u.Actual, u.Expected)
+}
+
+func (u PaymentAmountMismatch) HTTPStatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (u PaymentAmountMismatch) HTTPMessage() string {
+	return u.Error()
+}
+
+type PaymentDeadlinePassed struct {
+	TransactionID uuid.UUID `json:"transaction_id"`
+	Deadline      time.Time `json:"deadline"`
+}
+
+func (u PaymentDeadlinePassed) Error() string {
+	return fmt.Sprintf("payment deadline of transaction %s passed at %s", u.TransactionID, u.Deadline.Format(time.RFC3339))
+}
+
+func (u PaymentDeadlinePassed) HTTPStatusCode() int {
+	return http.StatusConflict
+}
+
+func (u PaymentDeadlinePassed) HTTPMessage() string {
+	return u.Error()
+}
+
+type PayoutNotFound struct {
+	TransactionID uuid.UUID `json:"transaction_id"`
+}
+
+func (u PayoutNotFound) Error() string {
+	if u.TransactionID == uuid.Nil {
+		return "no payout is due"
+	}
+
+	return fmt.Sprintf("payout of transaction %s not found", u.TransactionID)
+}
+
+func (u PayoutNotFound) HTTPStatusCode() int {
+	return http.StatusNotFound
+}
+
+func (u PayoutNotFound) HTTPMessage() string {
+	return u.Error()
+}
+
+type RefundNotFound struct {
+	TransactionID uuid.UUID `json:"transaction_id"`
+}
+
+func (u RefundNotFound) Error() string {
+	if u.TransactionID == uuid.Nil {
+		return "no refund is due"
+	}
+
+	return fmt.Sprintf("refund of transaction %s not found", u.TransactionID)
+}
+
+func (u RefundNotFound) HTTPStatusCode() int {
+	return http.StatusNotFound
+}
+
+func (u RefundNotFound) HTTPMessage() string {
+	return u.Error()
+}
@@ -24,6 +24,23 @@ func (u BuyerIsNotEligible) HTTPMessage() string {
 	return u.Error()
 }
 
+type SellerIsNotEligible struct {
+	ID     uuid.UUID
+	Reason string
+}
+
+func (u SellerIsNotEligible) Error() string {
+	return fmt.Sprintf("user with id %s is not eligible because %s", u.ID.String(), u.Reason)
+}
+
+func (u SellerIsNotEligible) HTTPStatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (u SellerIsNotEligible) HTTPMessage() string {
+	return u.Error()
+}
+
 type TransactionNotCreatedBySeller struct{}
 
 func (u TransactionNotCreatedBySeller) Error() string {
@@ -38,6 +55,20 @@ func (u TransactionNotCreatedBySeller) HTTPMessage() string {
 	return u.Error()
 }
 
+type TransactionNotCreatedByBuyer struct{}
+
+func (u TransactionNotCreatedByBuyer) Error() string {
+	return "transaction should be created by buyer to be accepted by seller"
+}
+
+func (u TransactionNotCreatedByBuyer) HTTPStatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (u TransactionNotCreatedByBuyer) HTTPMessage() string {
+	return u.Error()
+}
+
 type TransactionStatusNotValid struct {
 	LastStatus string
 	NewStatus  string
@@ -54,3 +85,298 @@ func (u TransactionStatusNotValid) HTTPStatusCode() int {
 func (u TransactionStatusNotValid) HTTPMessage() string {
 	return u.Error()
 }
+
+type TransactionAmountNotValid struct {
+	Reason string
+}
+
+func (u TransactionAmountNotValid) Error() string {
+	return fmt.Sprintf("transaction amount is not valid because %s", u.Reason)
+}
+
+func (u TransactionAmountNotValid) HTTPStatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (u TransactionAmountNotValid) HTTPMessage() string {
+	return u.Error()
+}
+
+type TransactionItemNotValid struct {
+	Name   string
+	Reason string
+}
+
+func (u TransactionItemNotValid) Error() string {
+	return fmt.Sprintf("transaction item %q is not valid because %s", u.Name, u.Reason)
+}
+
+func (u TransactionItemNotValid) HTTPStatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (u TransactionItemNotValid) HTTPMessage() string {
+	return u.Error()
+}
+
+type CurrencyNotSupported struct {
+	Currency string
+}
+
+func (u CurrencyNotSupported) Error() string {
+	return fmt.Sprintf("currency %q is not supported", u.Currency)
+}
+
+func (u CurrencyNotSupported) HTTPStatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (u CurrencyNotSupported) HTTPMessage() string {
+	return u.Error()
+}
+
+type CurrencyMismatch struct {
+	Expected string
+	Actual   string
+}
+
+func (u CurrencyMismatch) Error() string {
+	return fmt.Sprintf("currency mismatch, expected %s but got %s", u.Expected, u.Actual)
+}
+
+func (u CurrencyMismatch) HTTPStatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (u CurrencyMismatch) HTTPMessage() string {
+	return u.Error()
+}
+
+type TransactionActionNotAllowed struct {
+	Action string
+	Actor  string
+}
+
+func (u TransactionActionNotAllowed) Error() string {
+	return fmt.Sprintf("action %s is not allowed for %s", u.Action, u.Actor)
+}
+
+func (u TransactionActionNotAllowed) HTTPStatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (u TransactionActionNotAllowed) HTTPMessage() string {
+	return u.Error()
+}
+
+type TransactionNotFound struct {
+	ID uuid.UUID
+}
+
+func (u TransactionNotFound) Error() string {
+	return fmt.Sprintf("transaction with id %s not found", u.ID.String())
+}
+
+func (u TransactionNotFound) HTTPStatusCode() int {
+	return http.StatusNotFound
+}
+
+func (u TransactionNotFound) HTTPMessage() string {
+	return u.Error()
+}
+
+type TransactionUpdateConflict struct {
+	ID uuid.UUID
+}
+
+func (u TransactionUpdateConflict) Error() string {
+	return fmt.Sprintf("transaction with id %s has been updated by another request", u.ID.String())
+}
+
+func (u TransactionUpdateConflict) HTTPStatusCode() int {
+	return http.StatusConflict
+}
+
+func (u TransactionUpdateConflict) HTTPMessage() string {
+	return u.Error()
+}
+
+type TransactionIDNotValid struct {
+	ID string
+}
+
+func (u TransactionIDNotValid) Error() string {
+	return fmt.Sprintf("transaction id %q is not valid", u.ID)
+}
+
+func (u TransactionIDNotValid) HTTPStatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (u TransactionIDNotValid) HTTPMessage() string {
+	return u.Error()
+}
+
+type TransactionRoleNotValid struct {
+	Role string
+}
+
+func (u TransactionRoleNotValid) Error() string {
+	return fmt.Sprintf("role %q is not valid, should be buyer or seller", u.Role)
+}
+
+func (u TransactionRoleNotValid) HTTPStatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (u TransactionRoleNotValid) HTTPMessage() string {
+	return u.Error()
+}
+
+type TransactionCounterpartNotValid struct {
+	Reason string
+}
+
+func (u TransactionCounterpartNotValid) Error() string {
+	return fmt.Sprintf("transaction counterpart is not valid because %s", u.Reason)
+}
+
+func (u TransactionCounterpartNotValid) HTTPStatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (u TransactionCounterpartNotValid) HTTPMessage() string {
+	return u.Error()
+}
+
+type LedgerEntryNotValid struct {
+	Reason string `json:"reason"`
+}
+
+func (u LedgerEntryNotValid) Error() string {
+	return fmt.Sprintf("ledger entry is not valid: %s", u.Reason)
+}
+
+func (u LedgerEntryNotValid) HTTPStatusCode() int {
+	return http.StatusInternalServerError
+}
+
+func (u LedgerEntryNotValid) HTTPMessage() string {
+	return "internal server error"
+}
+
+type LedgerEntryNotBalanced struct {
+	Debit  int64 `json:"debit"`
+	Credit int64 `json:"credit"`
+}
+
+func (u LedgerEntryNotBalanced) Error() string {
+	return fmt.Sprintf("ledger entry is not balanced, debit %d and credit %d", u.Debit, u.Credit)
+}
+
+func (u LedgerEntryNotBalanced) HTTPStatusCode() int {
+	return http.StatusInternalServerError
+}
+
+func (u LedgerEntryNotBalanced) HTTPMessage() string {
+	return "internal server error"
+}
+
+type DisputeNotValid struct {
+	Reason string
+}
+
+func (u DisputeNotValid) Error() string {
+	return fmt.Sprintf("dispute is not valid because %s", u.Reason)
+}
+
+func (u DisputeNotValid) HTTPStatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (u DisputeNotValid) HTTPMessage() string {
+	return u.Error()
+}
+
+type DisputeResolutionNotValid struct {
+	Reason string
+}
+
+func (u DisputeResolutionNotValid) Error() string {
+	return fmt.Sprintf("dispute resolution is not valid because %s", u.Reason)
+}
+
+func (u DisputeResolutionNotValid) HTTPStatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (u DisputeResolutionNotValid) HTTPMessage() string {
+	return u.Error()
+}
+
+type ShipmentNotValid struct {
+	Reason string
+}
+
+func (u ShipmentNotValid) Error() string {
+	return fmt.Sprintf("shipment is not valid because %s", u.Reason)
+}
+
+func (u ShipmentNotValid) HTTPStatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (u ShipmentNotValid) HTTPMessage() string {
+	return u.Error()
+}
+
+type ShipmentNotFound struct {
+	TransactionID uuid.UUID
+}
+
+func (u ShipmentNotFound) Error() string {
+	return fmt.Sprintf("shipment of transaction %s not found", u.TransactionID.String())
+}
+
+func (u ShipmentNotFound) HTTPStatusCode() int {
+	return http.StatusNotFound
+}
+
+func (u ShipmentNotFound) HTTPMessage() string {
+	return u.Error()
+}
+
+type EventVersionConflict struct {
+	TransactionID uuid.UUID
+	Version       int
+}
+
+func (u EventVersionConflict) Error() string {
+	return fmt.Sprintf("event version %d of transaction %s has been appended by another request", u.Version, u.TransactionID.String())
+}
+
+func (u EventVersionConflict) HTTPStatusCode() int {
+	return http.StatusConflict
+}
+
+func (u EventVersionConflict) HTTPMessage() string {
+	return "transaction has been updated by another request"
+}
+
+type EventNotValid struct {
+	TransactionID uuid.UUID
+	Version       int
+	Reason        string
+}
+
+func (u EventNotValid) Error() string {
+	return fmt.Sprintf("event version %d of transaction %s is not valid because %s", u.Version, u.TransactionID.String(), u.Reason)
+}
+
+func (u EventNotValid) HTTPStatusCode() int {
+	return http.StatusInternalServerError
+}
+
+func (u EventNotValid) HTTPMessage() string {
+	return "internal server error"
+}
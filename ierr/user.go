@@ -3,6 +3,8 @@ package ierr
 import (
 	"fmt"
 	"net/http"
+
+	"github.com/google/uuid"
 )
 
 type UserNotFound struct {
@@ -21,6 +23,22 @@ func (u UserNotFound) HTTPMessage() string {
 	return u.Error()
 }
 
+type UserAlreadyExists struct {
+	PhoneNumber string `json:"phone_number"`
+}
+
+func (u UserAlreadyExists) Error() string {
+	return fmt.Sprintf("user with phone number %s already exists", u.PhoneNumber)
+}
+
+func (u UserAlreadyExists) HTTPStatusCode() int {
+	return http.StatusConflict
+}
+
+func (u UserAlreadyExists) HTTPMessage() string {
+	return u.Error()
+}
+
 type JWTError struct{}
 
 func (u JWTError) Error() string {
@@ -50,3 +68,19 @@ func (u UserForbiddenAccess) HTTPStatusCode() int {
 func (u UserForbiddenAccess) HTTPMessage() string {
 	return u.Error()
 }
+
+type UserIDNotFound struct {
+	ID uuid.UUID `json:"id"`
+}
+
+func (u UserIDNotFound) Error() string {
+	return fmt.Sprintf("user with id %s not found", u.ID.String())
+}
+
+func (u UserIDNotFound) HTTPStatusCode() int {
+	return http.StatusNotFound
+}
+
+func (u UserIDNotFound) HTTPMessage() string {
+	return u.Error()
+}
@@ -0,0 +1,56 @@
+package ierr
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+type WebhookEndpointNotValid struct {
+	Reason string
+}
+
+func (u WebhookEndpointNotValid) Error() string {
+	return fmt.Sprintf("webhook endpoint is not valid because %s", u.Reason)
+}
+
+func (u WebhookEndpointNotValid) HTTPStatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (u WebhookEndpointNotValid) HTTPMessage() string {
+	return u.Error()
+}
+
+type WebhookEndpointNotFound struct {
+	ID uuid.UUID
+}
+
+func (u WebhookEndpointNotFound) Error() string {
+	return fmt.Sprintf("webhook endpoint with id %s not found", u.ID.String())
+}
+
+func (u WebhookEndpointNotFound) HTTPStatusCode() int {
+	return http.StatusNotFound
+}
+
+func (u WebhookEndpointNotFound) HTTPMessage() string {
+	return u.Error()
+}
+
+type WebhookDeliveryNotFound struct {
+	ID uuid.UUID
+}
+
+func (u WebhookDeliveryNotFound) Error() string {
+	return fmt.Sprintf("webhook delivery with id %s not found", u.ID.String())
+}
+
+func (u WebhookDeliveryNotFound) HTTPStatusCode() int {
+	return http.StatusNotFound
+}
+
+func (u WebhookDeliveryNotFound) HTTPMessage() string {
+	return u.Error()
+}
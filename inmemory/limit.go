@@ -0,0 +1,67 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type window struct {
+	hits    int
+	resetAt time.Time
+}
+
+// RateLimiter counts hits in process memory, counters are per replica and lost on restart
+// so it is only meant for development.
+type RateLimiter struct {
+	mu        sync.Mutex
+	windows   map[string]window
+	lastPurge time.Time
+}
+
+func (l *RateLimiter) Hit(ctx context.Context, key string, d time.Duration) (int, time.Time, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.purge(now)
+
+	w, ok := l.windows[key]
+	if !ok || !now.Before(w.resetAt) {
+		w = window{resetAt: now.Add(d)}
+	}
+
+	w.hits++
+	l.windows[key] = w
+
+	return w.hits, w.resetAt, nil
+}
+
+func (l *RateLimiter) Reset(ctx context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.windows, key)
+	return nil
+}
+
+// purge removes ended windows, it runs at most once a minute. Caller must hold the lock.
+func (l *RateLimiter) purge(now time.Time) {
+	if now.Sub(l.lastPurge) < time.Minute {
+		return
+	}
+
+	for key, w := range l.windows {
+		if !now.Before(w.resetAt) {
+			delete(l.windows, key)
+		}
+	}
+
+	l.lastPurge = now
+}
+
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{
+		windows: make(map[string]window),
+	}
+}
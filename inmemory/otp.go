@@ -0,0 +1,54 @@
+package inmemory
+
+import (
+	"context"
+	"rekber/ierr"
+	"sync"
+	"time"
+)
+
+type verifiedOTPKey struct {
+	phoneNumber string
+	state       int
+}
+
+// VerifiedOTPStore keeps verified OTP in process memory, it is not shared between replicas
+// and is lost on restart so it is only meant for development.
+type VerifiedOTPStore struct {
+	ttl   time.Duration
+	mu    sync.Mutex
+	cache map[verifiedOTPKey]time.Time // key to expiry
+}
+
+func (s *VerifiedOTPStore) SaveVerifiedOTP(ctx context.Context, phoneNumber string, state int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cache[verifiedOTPKey{phoneNumber: phoneNumber, state: state}] = time.Now().Add(s.ttl)
+	return nil
+}
+
+func (s *VerifiedOTPStore) ConsumeVerifiedOTP(ctx context.Context, phoneNumber string, state int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := verifiedOTPKey{phoneNumber: phoneNumber, state: state}
+	expiredAt, ok := s.cache[key]
+	if !ok {
+		return ierr.UserForbiddenAccess{PhoneNumber: phoneNumber}
+	}
+
+	delete(s.cache, key)
+	if time.Now().After(expiredAt) {
+		return ierr.UserForbiddenAccess{PhoneNumber: phoneNumber}
+	}
+
+	return nil
+}
+
+func NewVerifiedOTPStore(ttl time.Duration) *VerifiedOTPStore {
+	return &VerifiedOTPStore{
+		ttl:   ttl,
+		cache: make(map[verifiedOTPKey]time.Time),
+	}
+}
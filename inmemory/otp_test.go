@@ -0,0 +1,69 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"rekber/ierr"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+)
+
+func TestVerifiedOTPStore_ConsumeVerifiedOTP(t *testing.T) {
+	timeNow := time.Date(2023, 9, 30, 21, 0, 0, 0, time.UTC)
+	now := timeNow
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time {
+		return now
+	})
+	defer patches.Reset()
+
+	phoneNumber := "+6281234567890"
+	forbidden := ierr.UserForbiddenAccess{PhoneNumber: phoneNumber}
+
+	tests := []struct {
+		name      string
+		saveState int
+		state     int
+		elapsed   time.Duration
+		wantErrs  []error
+	}{
+		{
+			name:      "verified otp is consumed only once",
+			saveState: 1,
+			state:     1,
+			elapsed:   4 * time.Minute,
+			wantErrs:  []error{nil, forbidden},
+		},
+		{
+			name:      "expired verified otp is refused",
+			saveState: 1,
+			state:     1,
+			elapsed:   5*time.Minute + time.Second,
+			wantErrs:  []error{forbidden, forbidden},
+		},
+		{
+			name:      "verified otp of another state is refused",
+			saveState: 1,
+			state:     2,
+			wantErrs:  []error{forbidden},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewVerifiedOTPStore(5 * time.Minute)
+
+			now = timeNow
+			if err := s.SaveVerifiedOTP(context.Background(), phoneNumber, tt.saveState); err != nil {
+				t.Fatalf("VerifiedOTPStore.SaveVerifiedOTP() error = %v", err)
+			}
+
+			now = timeNow.Add(tt.elapsed)
+			for i, wantErr := range tt.wantErrs {
+				if err := s.ConsumeVerifiedOTP(context.Background(), phoneNumber, tt.state); !errors.Is(err, wantErr) {
+					t.Errorf("VerifiedOTPStore.ConsumeVerifiedOTP() #%d error = %v, wantErr %v", i, err, wantErr)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package inmemory
+
+import (
+	"context"
+	"rekber/internal/user"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RevocationStore interface {
+	Revoke(ctx context.Context, t user.AccessToken) error
+	RevokeAllBefore(ctx context.Context, userID uuid.UUID, before time.Time) error
+	IsRevoked(ctx context.Context, t user.AccessToken) (bool, error)
+}
+
+// RevocationCache caches the access token revocation store in memory so checking
+// a token on every request does not always hit the store. Revoked tokens are kept
+// until they expire, tokens known to be not revoked are kept for ttl only so
+// revocations from other replicas are seen after at most ttl.
+type RevocationCache struct {
+	store RevocationStore
+	ttl   time.Duration
+
+	mu        sync.RWMutex
+	revoked   map[uuid.UUID]time.Time // token id to token expiry
+	allBefore map[uuid.UUID]time.Time // user id to the time tokens issued before are revoked
+	allowed   map[uuid.UUID]time.Time // token id to cache expiry
+	lastPurge time.Time
+}
+
+func (c *RevocationCache) Revoke(ctx context.Context, t user.AccessToken) error {
+	if err := c.store.Revoke(ctx, t); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.revoked[t.ID] = t.ExpiredAt
+	delete(c.allowed, t.ID)
+
+	return nil
+}
+
+func (c *RevocationCache) RevokeAllBefore(ctx context.Context, userID uuid.UUID, before time.Time) error {
+	if err := c.store.RevokeAllBefore(ctx, userID, before); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.allBefore[userID] = before
+	// allowed entries do not keep the user id, so drop them all rather than serving a stale answer
+	c.allowed = make(map[uuid.UUID]time.Time)
+
+	return nil
+}
+
+func (c *RevocationCache) IsRevoked(ctx context.Context, t user.AccessToken) (bool, error) {
+	now := time.Now()
+
+	if revoked, ok := c.cached(t, now); ok {
+		return revoked, nil
+	}
+
+	revoked, err := c.store.IsRevoked(ctx, t)
+	if err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if revoked {
+		c.revoked[t.ID] = t.ExpiredAt
+	} else {
+		c.allowed[t.ID] = now.Add(c.ttl)
+	}
+
+	c.purge(now)
+
+	return revoked, nil
+}
+
+func (c *RevocationCache) cached(t user.AccessToken, now time.Time) (revoked bool, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if _, ok := c.revoked[t.ID]; ok {
+		return true, true
+	}
+
+	if before, ok := c.allBefore[t.UserID]; ok && !t.IssuedAt.After(before) {
+		return true, true
+	}
+
+	if expiredAt, ok := c.allowed[t.ID]; ok && now.Before(expiredAt) {
+		return false, true
+	}
+
+	return false, false
+}
+
+// purge removes expired entries, it runs at most once per ttl. Caller must hold the write lock.
+func (c *RevocationCache) purge(now time.Time) {
+	if now.Sub(c.lastPurge) < c.ttl {
+		return
+	}
+
+	for id, expiredAt := range c.revoked {
+		if now.After(expiredAt) {
+			delete(c.revoked, id)
+		}
+	}
+
+	for id, expiredAt := range c.allowed {
+		if now.After(expiredAt) {
+			delete(c.allowed, id)
+		}
+	}
+
+	c.lastPurge = now
+}
+
+func NewRevocationCache(store RevocationStore, ttl time.Duration) *RevocationCache {
+	return &RevocationCache{
+		store:     store,
+		ttl:       ttl,
+		revoked:   make(map[uuid.UUID]time.Time),
+		allBefore: make(map[uuid.UUID]time.Time),
+		allowed:   make(map[uuid.UUID]time.Time),
+	}
+}
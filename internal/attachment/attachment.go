@@ -0,0 +1,98 @@
+package attachment
+
+import (
+	"context"
+	"io"
+	"rekber/ierr"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// sniffLength is how many bytes are read to detect the content type, as much as http.DetectContentType considers.
+const sniffLength = 512
+
+type Purpose string
+
+const (
+	// Dispute supports a dispute, e.g. a photo of a broken item.
+	Dispute Purpose = "dispute"
+	// Shipment proves delivery, e.g. a shipping label or receipt.
+	Shipment Purpose = "shipment"
+)
+
+func (p Purpose) IsValid() bool {
+	return p == Dispute || p == Shipment
+}
+
+// allowedContentTypes are the files accepted as evidence, photos and documents only.
+var allowedContentTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+// Attachment is the metadata of an uploaded file, the content is kept in the storage under StorageKey.
+type Attachment struct {
+	ID            uuid.UUID
+	TransactionID uuid.UUID
+	UploadedBy    uuid.UUID
+	Purpose       Purpose
+	FileName      string
+	ContentType   string
+	Size          int64
+	StorageKey    string
+	CreatedAt     time.Time
+}
+
+func newAttachment(transactionID, uploadedBy uuid.UUID, purpose Purpose, fileName, contentType string, size int64) Attachment {
+	id := uuid.New()
+	return Attachment{
+		ID:            id,
+		TransactionID: transactionID,
+		UploadedBy:    uploadedBy,
+		Purpose:       purpose,
+		FileName:      fileName,
+		ContentType:   contentType,
+		Size:          size,
+		// generated rather than derived from the file name so it is always a safe path
+		StorageKey: "transactions/" + transactionID.String() + "/" + id.String() + allowedContentTypes[contentType],
+		CreatedAt:  time.Now(),
+	}
+}
+
+func validate(purpose Purpose, contentType string, size, maxSize int64) error {
+	if !purpose.IsValid() {
+		return ierr.AttachmentNotValid{Reason: "purpose should be dispute or shipment"}
+	}
+
+	if size <= 0 {
+		return ierr.AttachmentNotValid{Reason: "file is empty"}
+	}
+
+	if size > maxSize {
+		return ierr.AttachmentTooLarge{MaxSize: maxSize}
+	}
+
+	if _, ok := allowedContentTypes[contentType]; !ok {
+		return ierr.AttachmentTypeNotAllowed{ContentType: contentType}
+	}
+
+	return nil
+}
+
+// Storage keeps the content of attachments, e.g. on local disk or in an S3 compatible bucket.
+type Storage interface {
+	Put(ctx context.Context, key, contentType string, content io.Reader, size int64) error
+	// Get returns the content of the key, or ierr.AttachmentNotFound when there is none.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type Repository interface {
+	Save(ctx context.Context, a Attachment) error
+	// Get returns the attachment of the transaction, or ierr.AttachmentNotFound.
+	Get(ctx context.Context, transactionID, id uuid.UUID) (Attachment, error)
+	ListByTransactionID(ctx context.Context, transactionID uuid.UUID) ([]Attachment, error)
+}
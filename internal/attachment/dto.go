@@ -0,0 +1,39 @@
+package attachment
+
+import (
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UploadRequest struct {
+	Purpose  string
+	FileName string
+	Size     int64
+	Content  io.Reader
+}
+
+type Response struct {
+	ID            uuid.UUID `json:"id"`
+	TransactionID uuid.UUID `json:"transaction_id"`
+	UploadedBy    uuid.UUID `json:"uploaded_by"`
+	Purpose       string    `json:"purpose"`
+	FileName      string    `json:"file_name"`
+	ContentType   string    `json:"content_type"`
+	Size          int64     `json:"size"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func newResponse(a Attachment) Response {
+	return Response{
+		ID:            a.ID,
+		TransactionID: a.TransactionID,
+		UploadedBy:    a.UploadedBy,
+		Purpose:       string(a.Purpose),
+		FileName:      a.FileName,
+		ContentType:   a.ContentType,
+		Size:          a.Size,
+		CreatedAt:     a.CreatedAt,
+	}
+}
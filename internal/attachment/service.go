@@ -0,0 +1,85 @@
+package attachment
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+
+	"github.com/google/uuid"
+)
+
+// Service stores attachments of transactions. It trusts the caller to have checked the user may
+// access the transaction.
+type Service struct {
+	repository Repository
+	storage    Storage
+	maxSize    int64
+}
+
+// Upload stores the file and its metadata. The content type is detected from the content rather
+// than trusted from the client.
+func (s Service) Upload(ctx context.Context, userID, transactionID uuid.UUID, req UploadRequest) (Response, error) {
+	content := bufio.NewReaderSize(req.Content, sniffLength)
+	head, err := content.Peek(sniffLength)
+	if err != nil && err != io.EOF {
+		return Response{}, fmt.Errorf("failed to read attachment: %w", err)
+	}
+
+	contentType := http.DetectContentType(head)
+	if err := validate(Purpose(req.Purpose), contentType, req.Size, s.maxSize); err != nil {
+		return Response{}, err
+	}
+
+	a := newAttachment(transactionID, userID, Purpose(req.Purpose), filepath.Base(req.FileName), contentType, req.Size)
+	if err := s.storage.Put(ctx, a.StorageKey, a.ContentType, io.LimitReader(content, a.Size), a.Size); err != nil {
+		return Response{}, fmt.Errorf("failed to store attachment: %w", err)
+	}
+
+	if err := s.repository.Save(ctx, a); err != nil {
+		// do not leave content nobody can reach behind
+		_ = s.storage.Delete(ctx, a.StorageKey)
+		return Response{}, fmt.Errorf("failed to save attachment: %w", err)
+	}
+
+	return newResponse(a), nil
+}
+
+func (s Service) List(ctx context.Context, transactionID uuid.UUID) ([]Response, error) {
+	attachments, err := s.repository.ListByTransactionID(ctx, transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+
+	result := make([]Response, 0, len(attachments))
+	for _, a := range attachments {
+		result = append(result, newResponse(a))
+	}
+
+	return result, nil
+}
+
+// Download returns the metadata and the content of the attachment, the caller must close the content.
+func (s Service) Download(ctx context.Context, transactionID, id uuid.UUID) (Response, io.ReadCloser, error) {
+	a, err := s.repository.Get(ctx, transactionID, id)
+	if err != nil {
+		return Response{}, nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+
+	content, err := s.storage.Get(ctx, a.StorageKey)
+	if err != nil {
+		return Response{}, nil, fmt.Errorf("failed to get attachment content: %w", err)
+	}
+
+	return newResponse(a), content, nil
+}
+
+func NewService(repo Repository, storage Storage, maxSize int64) *Service {
+	return &Service{
+		repository: repo,
+		storage:    storage,
+		maxSize:    maxSize,
+	}
+}
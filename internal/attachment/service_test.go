@@ -0,0 +1,115 @@
+package attachment
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"rekber/ierr"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+type fakeStorage struct {
+	Storage
+	objects map[string][]byte
+}
+
+func (f *fakeStorage) Put(ctx context.Context, key, contentType string, content io.Reader, size int64) error {
+	b, err := io.ReadAll(content)
+	if err != nil {
+		return err
+	}
+
+	f.objects[key] = b
+	return nil
+}
+
+type fakeRepository struct {
+	Repository
+	attachments []Attachment
+}
+
+func (f *fakeRepository) Save(ctx context.Context, a Attachment) error {
+	f.attachments = append(f.attachments, a)
+	return nil
+}
+
+func TestService_Upload(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 100)...)
+	pdf := []byte("%PDF-1.7\n%...")
+	trxUUID := uuid.New()
+
+	tests := []struct {
+		name            string
+		req             UploadRequest
+		wantContentType string
+		wantFileName    string
+		wantErr         error
+	}{
+		{
+			name:            "photo for dispute",
+			req:             UploadRequest{Purpose: "dispute", FileName: "broken.png", Size: int64(len(png)), Content: bytes.NewReader(png)},
+			wantContentType: "image/png",
+			wantFileName:    "broken.png",
+		},
+		{
+			name:            "receipt for shipment, directories in file name are dropped",
+			req:             UploadRequest{Purpose: "shipment", FileName: "../../receipt.pdf", Size: int64(len(pdf)), Content: bytes.NewReader(pdf)},
+			wantContentType: "application/pdf",
+			wantFileName:    "receipt.pdf",
+		},
+		{
+			name:    "content type is detected rather than trusted from file name",
+			req:     UploadRequest{Purpose: "dispute", FileName: "script.png", Size: 20, Content: strings.NewReader("<html><script></script>")},
+			wantErr: ierr.AttachmentTypeNotAllowed{ContentType: "text/html; charset=utf-8"},
+		},
+		{
+			name:    "file larger than max size",
+			req:     UploadRequest{Purpose: "dispute", FileName: "big.png", Size: 1025, Content: bytes.NewReader(png)},
+			wantErr: ierr.AttachmentTooLarge{MaxSize: 1024},
+		},
+		{
+			name:    "empty file",
+			req:     UploadRequest{Purpose: "dispute", FileName: "empty.png", Size: 0, Content: bytes.NewReader(nil)},
+			wantErr: ierr.AttachmentNotValid{Reason: "file is empty"},
+		},
+		{
+			name:    "unknown purpose",
+			req:     UploadRequest{Purpose: "profile", FileName: "me.png", Size: int64(len(png)), Content: bytes.NewReader(png)},
+			wantErr: ierr.AttachmentNotValid{Reason: "purpose should be dispute or shipment"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := &fakeStorage{objects: make(map[string][]byte)}
+			repo := &fakeRepository{}
+			s := NewService(repo, storage, 1024)
+
+			got, err := s.Upload(context.Background(), uuid.New(), trxUUID, tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Service.Upload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(storage.objects) != 0 || len(repo.attachments) != 0 {
+					t.Errorf("Service.Upload() stored %v objects and %v attachments, want none", len(storage.objects), len(repo.attachments))
+				}
+				return
+			}
+
+			if got.ContentType != tt.wantContentType || got.FileName != tt.wantFileName {
+				t.Errorf("Service.Upload() = %v %v, want %v %v", got.ContentType, got.FileName, tt.wantContentType, tt.wantFileName)
+			}
+
+			a := repo.attachments[0]
+			if !strings.HasPrefix(a.StorageKey, "transactions/"+trxUUID.String()+"/") {
+				t.Errorf("Service.Upload() storage key = %v, want under the transaction", a.StorageKey)
+			}
+			if int64(len(storage.objects[a.StorageKey])) != tt.req.Size {
+				t.Errorf("Service.Upload() stored %v bytes, want %v", len(storage.objects[a.StorageKey]), tt.req.Size)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package ledger
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BalanceResponse struct {
+	Account  string `json:"account"`
+	Currency string `json:"currency"`
+	Debit    int64  `json:"debit"`
+	Credit   int64  `json:"credit"`
+	Balance  int64  `json:"balance"`
+}
+
+type LineResponse struct {
+	Account string `json:"account"`
+	Debit   int64  `json:"debit"`
+	Credit  int64  `json:"credit"`
+}
+
+type EntryResponse struct {
+	ID        uuid.UUID      `json:"id"`
+	Kind      string         `json:"kind"`
+	Currency  string         `json:"currency"`
+	Lines     []LineResponse `json:"lines"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+type Response struct {
+	TransactionID uuid.UUID         `json:"transaction_id"`
+	Balances      []BalanceResponse `json:"balances"`
+	Entries       []EntryResponse   `json:"entries"`
+}
+
+func newBalanceResponses(balances []Balance) []BalanceResponse {
+	result := make([]BalanceResponse, 0, len(balances))
+	for _, b := range balances {
+		result = append(result, BalanceResponse{
+			Account:  string(b.Account),
+			Currency: b.Currency,
+			Debit:    b.Debit,
+			Credit:   b.Credit,
+			Balance:  b.Amount(),
+		})
+	}
+
+	return result
+}
+
+func newResponse(transactionID uuid.UUID, balances []Balance, entries []Entry) Response {
+	entryResponses := make([]EntryResponse, 0, len(entries))
+	for _, e := range entries {
+		lines := make([]LineResponse, 0, len(e.Lines))
+		for _, l := range e.Lines {
+			lines = append(lines, LineResponse{
+				Account: string(l.Account),
+				Debit:   l.Debit,
+				Credit:  l.Credit,
+			})
+		}
+
+		entryResponses = append(entryResponses, EntryResponse{
+			ID:        e.ID,
+			Kind:      string(e.Kind),
+			Currency:  e.Currency,
+			Lines:     lines,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+
+	return Response{
+		TransactionID: transactionID,
+		Balances:      newBalanceResponses(balances),
+		Entries:       entryResponses,
+	}
+}
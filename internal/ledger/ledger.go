@@ -0,0 +1,159 @@
+package ledger
+
+import (
+	"rekber/ierr"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Account string
+
+const (
+	// BuyerFunding is the money the buyer has put into escrow.
+	BuyerFunding Account = "buyer_funding"
+	// EscrowHolding is the money held in escrow for a transaction.
+	EscrowHolding Account = "escrow_holding"
+	// PlatformFeeRevenue is the escrow fee earned by the platform.
+	PlatformFeeRevenue Account = "platform_fee_revenue"
+	// SellerPayable is the money owed to the seller until it is paid out.
+	SellerPayable Account = "seller_payable"
+)
+
+func (a Account) IsValid() bool {
+	switch a {
+	case BuyerFunding, EscrowHolding, PlatformFeeRevenue, SellerPayable:
+		return true
+	default:
+		return false
+	}
+}
+
+type Kind string
+
+const (
+	Paid    Kind = "paid"
+	Success Kind = "success"
+	Refund  Kind = "refund"
+	// Resolved splits the escrow of a disputed transaction between buyer, seller and platform.
+	Resolved Kind = "resolved"
+)
+
+// Line moves amount into or out of an account, exactly one of Debit and Credit is set.
+type Line struct {
+	Account Account
+	Debit   int64
+	Credit  int64
+}
+
+// Entry is a balanced journal entry of a transaction. Entries are never changed once posted,
+// a mistake is corrected by posting another entry.
+type Entry struct {
+	ID            uuid.UUID
+	TransactionID uuid.UUID
+	Kind          Kind
+	// Currency of every line, amounts are in its minor unit.
+	Currency  string
+	Lines     []Line
+	CreatedAt time.Time
+}
+
+// Validate enforces the invariants of double-entry bookkeeping: every line moves a positive
+// amount in one direction on a known account, and total debit equals total credit.
+func (e Entry) Validate() error {
+	if e.TransactionID == uuid.Nil || e.Currency == "" {
+		return ierr.LedgerEntryNotValid{Reason: "transaction and currency are required"}
+	}
+
+	if len(e.Lines) < 2 {
+		return ierr.LedgerEntryNotValid{Reason: "entry needs at least two lines"}
+	}
+
+	var debit, credit int64
+	for _, l := range e.Lines {
+		if !l.Account.IsValid() {
+			return ierr.LedgerEntryNotValid{Reason: "unknown account " + string(l.Account)}
+		}
+
+		if l.Debit < 0 || l.Credit < 0 || (l.Debit == 0) == (l.Credit == 0) {
+			return ierr.LedgerEntryNotValid{Reason: "line must either debit or credit a positive amount"}
+		}
+
+		debit += l.Debit
+		credit += l.Credit
+	}
+
+	if debit != credit {
+		return ierr.LedgerEntryNotBalanced{Debit: debit, Credit: credit}
+	}
+
+	return nil
+}
+
+func newEntry(transactionID uuid.UUID, kind Kind, currency string, lines ...Line) Entry {
+	return Entry{
+		ID:            uuid.New(),
+		TransactionID: transactionID,
+		Kind:          kind,
+		Currency:      currency,
+		Lines:         lines,
+		CreatedAt:     time.Now(),
+	}
+}
+
+// PaidEntry moves the buyer payment into escrow.
+func PaidEntry(transactionID uuid.UUID, currency string, amount int64) Entry {
+	return newEntry(transactionID, Paid, currency,
+		Line{Account: BuyerFunding, Debit: amount},
+		Line{Account: EscrowHolding, Credit: amount},
+	)
+}
+
+// SuccessEntry releases the escrow, the seller is owed the net amount and the platform earns the fee.
+func SuccessEntry(transactionID uuid.UUID, currency string, sellerNet, escrowFee int64) Entry {
+	lines := []Line{
+		{Account: EscrowHolding, Debit: sellerNet + escrowFee},
+		{Account: SellerPayable, Credit: sellerNet},
+	}
+	if escrowFee > 0 {
+		lines = append(lines, Line{Account: PlatformFeeRevenue, Credit: escrowFee})
+	}
+
+	return newEntry(transactionID, Success, currency, lines...)
+}
+
+// RefundEntry returns the money held in escrow to the buyer.
+func RefundEntry(transactionID uuid.UUID, currency string, amount int64) Entry {
+	return newEntry(transactionID, Refund, currency,
+		Line{Account: EscrowHolding, Debit: amount},
+		Line{Account: BuyerFunding, Credit: amount},
+	)
+}
+
+// ResolvedEntry splits the escrow, the buyer gets the refund back, the seller is owed the net amount
+// and the platform earns the fee.
+func ResolvedEntry(transactionID uuid.UUID, currency string, refund, sellerNet, escrowFee int64) Entry {
+	lines := []Line{
+		{Account: EscrowHolding, Debit: refund + sellerNet + escrowFee},
+		{Account: BuyerFunding, Credit: refund},
+		{Account: SellerPayable, Credit: sellerNet},
+	}
+	if escrowFee > 0 {
+		lines = append(lines, Line{Account: PlatformFeeRevenue, Credit: escrowFee})
+	}
+
+	return newEntry(transactionID, Resolved, currency, lines...)
+}
+
+// Balance of an account is its total credit minus total debit, e.g. a positive escrow holding
+// balance is the money still held in escrow.
+type Balance struct {
+	Account  Account
+	Currency string
+	Debit    int64
+	Credit   int64
+}
+
+func (b Balance) Amount() int64 {
+	return b.Credit - b.Debit
+}
@@ -0,0 +1,121 @@
+package ledger
+
+import (
+	"errors"
+	"rekber/ierr"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestEntry_Validate(t *testing.T) {
+	trxID := uuid.New()
+
+	tests := []struct {
+		name    string
+		e       Entry
+		wantErr error
+	}{
+		{
+			name: "balanced entry",
+			e: Entry{TransactionID: trxID, Currency: "IDR", Lines: []Line{
+				{Account: EscrowHolding, Debit: 100},
+				{Account: SellerPayable, Credit: 90},
+				{Account: PlatformFeeRevenue, Credit: 10},
+			}},
+		},
+		{
+			name:    "entry without transaction",
+			e:       Entry{Currency: "IDR", Lines: []Line{{Account: BuyerFunding, Debit: 100}, {Account: EscrowHolding, Credit: 100}}},
+			wantErr: ierr.LedgerEntryNotValid{Reason: "transaction and currency are required"},
+		},
+		{
+			name:    "entry with a single line",
+			e:       Entry{TransactionID: trxID, Currency: "IDR", Lines: []Line{{Account: BuyerFunding, Debit: 100}}},
+			wantErr: ierr.LedgerEntryNotValid{Reason: "entry needs at least two lines"},
+		},
+		{
+			name:    "unknown account",
+			e:       Entry{TransactionID: trxID, Currency: "IDR", Lines: []Line{{Account: "cash", Debit: 100}, {Account: EscrowHolding, Credit: 100}}},
+			wantErr: ierr.LedgerEntryNotValid{Reason: "unknown account cash"},
+		},
+		{
+			name:    "line with both debit and credit",
+			e:       Entry{TransactionID: trxID, Currency: "IDR", Lines: []Line{{Account: BuyerFunding, Debit: 100, Credit: 100}, {Account: EscrowHolding, Credit: 100}}},
+			wantErr: ierr.LedgerEntryNotValid{Reason: "line must either debit or credit a positive amount"},
+		},
+		{
+			name:    "line with negative amount",
+			e:       Entry{TransactionID: trxID, Currency: "IDR", Lines: []Line{{Account: BuyerFunding, Debit: -100}, {Account: EscrowHolding, Credit: -100}}},
+			wantErr: ierr.LedgerEntryNotValid{Reason: "line must either debit or credit a positive amount"},
+		},
+		{
+			name:    "unbalanced entry",
+			e:       Entry{TransactionID: trxID, Currency: "IDR", Lines: []Line{{Account: BuyerFunding, Debit: 100}, {Account: EscrowHolding, Credit: 90}}},
+			wantErr: ierr.LedgerEntryNotBalanced{Debit: 100, Credit: 90},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.e.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Entry.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEntries_HoldAndReleaseEscrow(t *testing.T) {
+	trxID := uuid.New()
+
+	tests := []struct {
+		name        string
+		entries     []Entry
+		wantBalance map[Account]int64
+	}{
+		{
+			name:        "paid transaction holds the payment in escrow",
+			entries:     []Entry{PaidEntry(trxID, "IDR", 100)},
+			wantBalance: map[Account]int64{BuyerFunding: -100, EscrowHolding: 100},
+		},
+		{
+			name:        "success transaction releases escrow to the seller and platform",
+			entries:     []Entry{PaidEntry(trxID, "IDR", 100), SuccessEntry(trxID, "IDR", 90, 10)},
+			wantBalance: map[Account]int64{BuyerFunding: -100, EscrowHolding: 0, SellerPayable: 90, PlatformFeeRevenue: 10},
+		},
+		{
+			name:        "success transaction without fee",
+			entries:     []Entry{PaidEntry(trxID, "IDR", 100), SuccessEntry(trxID, "IDR", 100, 0)},
+			wantBalance: map[Account]int64{BuyerFunding: -100, EscrowHolding: 0, SellerPayable: 100},
+		},
+		{
+			name:        "resolved transaction splits escrow between buyer, seller and platform",
+			entries:     []Entry{PaidEntry(trxID, "IDR", 100), ResolvedEntry(trxID, "IDR", 30, 60, 10)},
+			wantBalance: map[Account]int64{BuyerFunding: -70, EscrowHolding: 0, SellerPayable: 60, PlatformFeeRevenue: 10},
+		},
+		{
+			name:        "refunded transaction returns escrow to the buyer",
+			entries:     []Entry{PaidEntry(trxID, "IDR", 100), RefundEntry(trxID, "IDR", 100)},
+			wantBalance: map[Account]int64{BuyerFunding: 0, EscrowHolding: 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make(map[Account]int64)
+			for _, e := range tt.entries {
+				if err := e.Validate(); err != nil {
+					t.Fatalf("Entry.Validate() error = %v", err)
+				}
+
+				for _, l := range e.Lines {
+					got[l.Account] += Balance{Debit: l.Debit, Credit: l.Credit}.Amount()
+				}
+			}
+
+			for account, want := range tt.wantBalance {
+				if got[account] != want {
+					t.Errorf("balance of %v = %v, want %v", account, got[account], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	// Save stores the entry with its lines atomically, an entry of the same kind can only be
+	// posted once for a transaction.
+	Save(ctx context.Context, e Entry) error
+	ListEntries(ctx context.Context, transactionID uuid.UUID) ([]Entry, error)
+	ListBalances(ctx context.Context, transactionID uuid.UUID) ([]Balance, error)
+	// ListTotalBalances returns the balances of every account over all transactions.
+	ListTotalBalances(ctx context.Context) ([]Balance, error)
+}
+
+type Service struct {
+	repository Repository
+}
+
+// Post validates and stores the entry, an unbalanced entry is never stored.
+func (s Service) Post(ctx context.Context, e Entry) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+
+	if err := s.repository.Save(ctx, e); err != nil {
+		return fmt.Errorf("failed to save ledger entry: %w", err)
+	}
+
+	return nil
+}
+
+// TransactionLedger returns the balances and entries of a transaction, the escrow holding balance
+// is the money held in escrow for it.
+func (s Service) TransactionLedger(ctx context.Context, transactionID uuid.UUID) (Response, error) {
+	balances, err := s.repository.ListBalances(ctx, transactionID)
+	if err != nil {
+		return Response{}, fmt.Errorf("failed to list balances: %w", err)
+	}
+
+	entries, err := s.repository.ListEntries(ctx, transactionID)
+	if err != nil {
+		return Response{}, fmt.Errorf("failed to list entries: %w", err)
+	}
+
+	return newResponse(transactionID, balances, entries), nil
+}
+
+// TotalBalances returns the balances of every account over all transactions.
+func (s Service) TotalBalances(ctx context.Context) ([]BalanceResponse, error) {
+	balances, err := s.repository.ListTotalBalances(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list total balances: %w", err)
+	}
+
+	return newBalanceResponses(balances), nil
+}
+
+func NewService(repo Repository) *Service {
+	return &Service{
+		repository: repo,
+	}
+}
@@ -0,0 +1,91 @@
+package outbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxBackoff caps the exponential backoff between publish attempts.
+const maxBackoff = time.Hour
+
+type Status string
+
+const (
+	Pending   Status = "pending"
+	Published Status = "published"
+	// Failed messages ran out of attempts, they are kept for inspection and never published.
+	Failed Status = "failed"
+)
+
+// Message is written to the outbox within the database transaction of the change it tells about,
+// so it is published if and only if the change is committed. Messages are published at least
+// once, consumers deduplicate them by ID.
+type Message struct {
+	ID uuid.UUID
+	// Consumer is the publisher the message is relayed to. Every consumer gets its own copy with
+	// its own attempts, so a failing consumer neither holds back nor repeats the others.
+	Consumer string
+	// Topic is what the message is about, e.g. transaction.accept.
+	Topic string
+	// Key groups the messages of the same entity, e.g. the transaction id.
+	Key           string
+	Payload       []byte
+	Status        Status
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	PublishedAt   time.Time
+	FailedAt      time.Time
+	CreatedAt     time.Time
+}
+
+// NewMessage returns a pending message with the JSON encoding of payload.
+func NewMessage(topic, key string, payload interface{}) (Message, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return Message{}, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	now := time.Now()
+	return Message{
+		ID:            uuid.New(),
+		Topic:         topic,
+		Key:           key,
+		Payload:       b,
+		Status:        Pending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}, nil
+}
+
+func (m Message) publish() Message {
+	m.Attempts++
+	m.Status = Published
+	m.LastError = ""
+	m.PublishedAt = time.Now()
+
+	return m
+}
+
+// fail records a failed attempt, the message is retried with exponential backoff until maxAttempts is reached.
+func (m Message) fail(err error, maxAttempts int, backoff time.Duration) Message {
+	m.Attempts++
+	m.LastError = err.Error()
+
+	if m.Attempts >= maxAttempts {
+		m.Status = Failed
+		m.FailedAt = time.Now()
+		return m
+	}
+
+	delay := backoff << (m.Attempts - 1)
+	if delay <= 0 || delay > maxBackoff {
+		delay = maxBackoff
+	}
+
+	m.NextAttemptAt = time.Now().Add(delay)
+	return m
+}
@@ -0,0 +1,89 @@
+package outbox
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/google/uuid"
+)
+
+func TestMessage_fail(t *testing.T) {
+	timeNow := time.Date(2023, 9, 30, 21, 0, 0, 0, time.UTC)
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time {
+		return timeNow
+	})
+	defer patches.Reset()
+
+	messageUUID := uuid.New()
+	errBroker := errors.New("broker is offline")
+
+	type args struct {
+		maxAttempts int
+		backoff     time.Duration
+	}
+	tests := []struct {
+		name    string
+		message Message
+		args    args
+		want    Message
+	}{
+		{
+			name:    "first failed attempt is retried after backoff",
+			message: Message{ID: messageUUID, Status: Pending},
+			args:    args{maxAttempts: 5, backoff: time.Minute},
+			want: Message{
+				ID:            messageUUID,
+				Status:        Pending,
+				Attempts:      1,
+				NextAttemptAt: timeNow.Add(time.Minute),
+				LastError:     errBroker.Error(),
+			},
+		},
+		{
+			name:    "backoff doubles on every failed attempt",
+			message: Message{ID: messageUUID, Status: Pending, Attempts: 2},
+			args:    args{maxAttempts: 5, backoff: time.Minute},
+			want: Message{
+				ID:            messageUUID,
+				Status:        Pending,
+				Attempts:      3,
+				NextAttemptAt: timeNow.Add(4 * time.Minute),
+				LastError:     errBroker.Error(),
+			},
+		},
+		{
+			name:    "backoff is capped",
+			message: Message{ID: messageUUID, Status: Pending, Attempts: 10},
+			args:    args{maxAttempts: 20, backoff: time.Minute},
+			want: Message{
+				ID:            messageUUID,
+				Status:        Pending,
+				Attempts:      11,
+				NextAttemptAt: timeNow.Add(maxBackoff),
+				LastError:     errBroker.Error(),
+			},
+		},
+		{
+			name:    "message fails after the last attempt",
+			message: Message{ID: messageUUID, Status: Pending, Attempts: 4},
+			args:    args{maxAttempts: 5, backoff: time.Minute},
+			want: Message{
+				ID:        messageUUID,
+				Status:    Failed,
+				Attempts:  5,
+				LastError: errBroker.Error(),
+				FailedAt:  timeNow,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.message.fail(errBroker, tt.args.maxAttempts, tt.args.backoff); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Message.fail() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"rekber/ierr"
+	"sort"
+	"time"
+)
+
+type Repository interface {
+	Save(ctx context.Context, m Message) error
+	// LockDue locks the oldest pending message of one of consumers due at now, skipping messages
+	// locked by other relays. It returns ierr.OutboxMessageNotFound when there is none, it must be
+	// called within a transaction.
+	LockDue(ctx context.Context, now time.Time, consumers []string) (Message, error)
+	Update(ctx context.Context, m Message) error
+}
+
+// Publisher delivers a message to the other systems, e.g. a broker or a webhook.
+type Publisher interface {
+	Publish(ctx context.Context, m Message) error
+}
+
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type Service struct {
+	repository Repository
+	publishers map[string]Publisher
+	// consumers are the names of publishers, sorted so every message is saved in the same order.
+	consumers  []string
+	transactor Transactor
+}
+
+// Enqueue writes a copy of the message for every consumer to the outbox, it is called within the
+// transaction of the change the message tells about.
+func (s Service) Enqueue(ctx context.Context, m Message) error {
+	for _, consumer := range s.consumers {
+		m.Consumer = consumer
+		if err := s.repository.Save(ctx, m); err != nil {
+			return fmt.Errorf("failed to save outbox message: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Relay publishes every due message once and returns how many were attempted. A failed attempt is
+// recorded on the message and retried later, it is not returned as error. A message published
+// right before its row fails to be updated is published again, hence at least once.
+func (s Service) Relay(ctx context.Context, maxAttempts int, backoff time.Duration) (int, error) {
+	relayed := 0
+	for {
+		err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			m, err := s.repository.LockDue(ctx, time.Now(), s.consumers)
+			if err != nil {
+				return err
+			}
+
+			if err := s.publishers[m.Consumer].Publish(ctx, m); err != nil {
+				m = m.fail(err, maxAttempts, backoff)
+			} else {
+				m = m.publish()
+			}
+
+			if err := s.repository.Update(ctx, m); err != nil {
+				return fmt.Errorf("failed to update outbox message: %w", err)
+			}
+
+			return nil
+		})
+		if errors.As(err, &ierr.OutboxMessageNotFound{}) {
+			return relayed, nil
+		}
+
+		if err != nil {
+			return relayed, err
+		}
+
+		relayed++
+	}
+}
+
+// NewService relays messages to publishers by their consumer name, e.g. broker or webhook.
+func NewService(repo Repository, publishers map[string]Publisher, transactor Transactor) *Service {
+	consumers := make([]string, 0, len(publishers))
+	for consumer := range publishers {
+		consumers = append(consumers, consumer)
+	}
+	sort.Strings(consumers)
+
+	return &Service{
+		repository: repo,
+		publishers: publishers,
+		consumers:  consumers,
+		transactor: transactor,
+	}
+}
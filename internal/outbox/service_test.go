@@ -0,0 +1,168 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"rekber/ierr"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/google/uuid"
+)
+
+type fakeRepository struct {
+	Repository
+	messages []Message
+}
+
+func (f *fakeRepository) Save(ctx context.Context, m Message) error {
+	f.messages = append(f.messages, m)
+	return nil
+}
+
+func (f *fakeRepository) LockDue(ctx context.Context, now time.Time, consumers []string) (Message, error) {
+	for _, m := range f.messages {
+		for _, consumer := range consumers {
+			if m.Consumer == consumer && m.Status == Pending && !m.NextAttemptAt.After(now) {
+				return m, nil
+			}
+		}
+	}
+
+	return Message{}, ierr.OutboxMessageNotFound{}
+}
+
+func (f *fakeRepository) Update(ctx context.Context, m Message) error {
+	for i := range f.messages {
+		if f.messages[i].ID == m.ID && f.messages[i].Consumer == m.Consumer {
+			f.messages[i] = m
+		}
+	}
+
+	return nil
+}
+
+func (f *fakeRepository) get(id uuid.UUID, consumer string) Message {
+	for _, m := range f.messages {
+		if m.ID == id && m.Consumer == consumer {
+			return m
+		}
+	}
+
+	return Message{}
+}
+
+type fakePublisher struct {
+	err       error
+	published []Message
+}
+
+func (f *fakePublisher) Publish(ctx context.Context, m Message) error {
+	if f.err != nil {
+		return f.err
+	}
+
+	f.published = append(f.published, m)
+	return nil
+}
+
+type fakeTransactor struct{}
+
+func (fakeTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestService_Enqueue(t *testing.T) {
+	repo := &fakeRepository{}
+	s := NewService(repo, map[string]Publisher{"webhook": &fakePublisher{}, "broker": &fakePublisher{}}, fakeTransactor{})
+
+	m := Message{ID: uuid.New(), Topic: "transaction.accept", Status: Pending}
+	if err := s.Enqueue(context.Background(), m); err != nil {
+		t.Fatalf("Service.Enqueue() error = %v", err)
+	}
+
+	if len(repo.messages) != 2 {
+		t.Fatalf("Service.Enqueue() saved %v messages, want 2", len(repo.messages))
+	}
+	for i, consumer := range []string{"broker", "webhook"} {
+		if got := repo.messages[i]; got.ID != m.ID || got.Consumer != consumer {
+			t.Errorf("Service.Enqueue() message %v = %+v, want %v for %v", i, got, m.ID, consumer)
+		}
+	}
+}
+
+func TestService_Relay(t *testing.T) {
+	timeNow := time.Date(2023, 9, 30, 21, 0, 0, 0, time.UTC)
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time {
+		return timeNow
+	})
+	defer patches.Reset()
+
+	dueUUID := uuid.New()
+	laterUUID := uuid.New()
+	removedUUID := uuid.New()
+
+	type want struct {
+		status   Status
+		attempts int
+		next     time.Time
+	}
+	tests := []struct {
+		name        string
+		brokerErr   error
+		wantRelayed int
+		wantBroker  want
+		wantWebhook want
+	}{
+		{
+			name:        "due message is published to every consumer",
+			wantRelayed: 2,
+			wantBroker:  want{status: Published, attempts: 1, next: timeNow},
+			wantWebhook: want{status: Published, attempts: 1, next: timeNow},
+		},
+		{
+			name:        "failed consumer is retried after backoff without holding back the others",
+			brokerErr:   errors.New("broker is offline"),
+			wantRelayed: 2,
+			wantBroker:  want{status: Pending, attempts: 1, next: timeNow.Add(time.Minute)},
+			wantWebhook: want{status: Published, attempts: 1, next: timeNow},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepository{messages: []Message{
+				{ID: dueUUID, Consumer: "broker", Topic: "transaction.accept", Status: Pending, NextAttemptAt: timeNow},
+				{ID: dueUUID, Consumer: "webhook", Topic: "transaction.accept", Status: Pending, NextAttemptAt: timeNow},
+				{ID: laterUUID, Consumer: "broker", Topic: "transaction.pay", Status: Pending, NextAttemptAt: timeNow.Add(time.Hour)},
+				{ID: removedUUID, Consumer: "removed", Topic: "transaction.pay", Status: Pending, NextAttemptAt: timeNow},
+			}}
+			broker := &fakePublisher{err: tt.brokerErr}
+			webhook := &fakePublisher{}
+			s := NewService(repo, map[string]Publisher{"broker": broker, "webhook": webhook}, fakeTransactor{})
+
+			got, err := s.Relay(context.Background(), 5, time.Minute)
+			if err != nil {
+				t.Fatalf("Service.Relay() error = %v", err)
+			}
+			if got != tt.wantRelayed {
+				t.Errorf("Service.Relay() = %v, want %v", got, tt.wantRelayed)
+			}
+
+			for consumer, want := range map[string]want{"broker": tt.wantBroker, "webhook": tt.wantWebhook} {
+				due := repo.get(dueUUID, consumer)
+				if due.Status != want.status || due.Attempts != want.attempts || !due.NextAttemptAt.Equal(want.next) {
+					t.Errorf("due message of %v = %+v, want status %v, attempts %v, next attempt at %v", consumer, due, want.status, want.attempts, want.next)
+				}
+			}
+
+			if later := repo.get(laterUUID, "broker"); later.Status != Pending || later.Attempts != 0 {
+				t.Errorf("message not due yet = %+v, want untouched", later)
+			}
+
+			if removed := repo.get(removedUUID, "removed"); removed.Status != Pending || removed.Attempts != 0 {
+				t.Errorf("message of unknown consumer = %+v, want untouched", removed)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"rekber/config"
 	"rekber/ierr"
 	"rekber/internal/user"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
@@ -16,47 +17,120 @@ type Token struct {
 }
 
 func (t Token) ParseAccessToken() (user.User, error) {
+	claims, err := t.ParseAccessTokenClaims()
+	if err != nil {
+		return user.User{}, err
+	}
+
+	return user.User{
+		ID: claims.UserID,
+	}, nil
+}
+
+func (t Token) ParseAccessTokenClaims() (user.AccessToken, error) {
 	jwtToken, err := validate(t.accessToken, config.Get().JWT.AccessToken.SecretKey)
 	if err != nil {
-		return user.User{}, fmt.Errorf("failed to validate access token: %w", err)
+		return user.AccessToken{}, fmt.Errorf("failed to validate access token: %w", err)
 	}
 
 	claims, ok := jwtToken.Claims.(jwt.MapClaims)
 	if !(ok && jwtToken.Valid) {
-		return user.User{}, ierr.JWTError{}
+		return user.AccessToken{}, ierr.JWTError{}
 	}
 
-	userID, ok := claims["user_id"].(string)
-	if !ok {
-		return user.User{}, ierr.JWTError{}
+	userID, err := uuidClaim(claims, "user_id")
+	if err != nil {
+		return user.AccessToken{}, err
 	}
 
-	return user.User{
-		ID: uuid.MustParse(userID),
+	// token without id cannot be revoked, so it is not accepted
+	id, err := uuidClaim(claims, "jti")
+	if err != nil {
+		return user.AccessToken{}, err
+	}
+
+	issuedAt, err := claims.GetIssuedAt()
+	if err != nil || issuedAt == nil {
+		return user.AccessToken{}, ierr.JWTError{}
+	}
+
+	expiredAt, err := claims.GetExpirationTime()
+	if err != nil || expiredAt == nil {
+		return user.AccessToken{}, ierr.JWTError{}
+	}
+
+	return user.AccessToken{
+		ID:        id,
+		UserID:    userID,
+		IssuedAt:  issuedAtMilli(claims, issuedAt.Time),
+		ExpiredAt: expiredAt.Time,
 	}, nil
 }
 
-func (t Token) ParseRefreshToken() (user.User, error) {
+// issuedAtMilli returns the issue time with millisecond precision from the iat_ms claim, or iat
+// for a token issued before the claim was added.
+func issuedAtMilli(claims jwt.MapClaims, issuedAt time.Time) time.Time {
+	ms, ok := claims["iat_ms"].(float64)
+	if !ok {
+		return issuedAt
+	}
+
+	return time.UnixMilli(int64(ms))
+}
+
+func (t Token) ParseRefreshToken() (user.RefreshToken, error) {
 	jwtToken, err := validate(t.refreshToken, config.Get().JWT.RefreshToken.SecretKey)
 	if err != nil {
-		return user.User{}, fmt.Errorf("failed to validate refresh token: %w", err)
+		return user.RefreshToken{}, fmt.Errorf("failed to validate refresh token: %w", err)
 	}
 
 	claims, ok := jwtToken.Claims.(jwt.MapClaims)
 	if !(ok && jwtToken.Valid) {
-		return user.User{}, ierr.JWTError{}
+		return user.RefreshToken{}, ierr.JWTError{}
 	}
 
-	userID, ok := claims["user_id"].(string)
-	if !ok {
-		return user.User{}, ierr.JWTError{}
+	userID, err := uuidClaim(claims, "user_id")
+	if err != nil {
+		return user.RefreshToken{}, err
 	}
 
-	return user.User{
-		ID: uuid.MustParse(userID),
+	id, err := uuidClaim(claims, "jti")
+	if err != nil {
+		return user.RefreshToken{}, err
+	}
+
+	familyID, err := uuidClaim(claims, "family_id")
+	if err != nil {
+		return user.RefreshToken{}, err
+	}
+
+	expiredAt, err := claims.GetExpirationTime()
+	if err != nil || expiredAt == nil {
+		return user.RefreshToken{}, ierr.JWTError{}
+	}
+
+	return user.RefreshToken{
+		ID:        id,
+		FamilyID:  familyID,
+		UserID:    userID,
+		ExpiredAt: expiredAt.Time,
 	}, nil
 }
 
+func uuidClaim(claims jwt.MapClaims, key string) (uuid.UUID, error) {
+	value, ok := claims[key].(string)
+	if !ok {
+		return uuid.Nil, ierr.JWTError{}
+	}
+
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, ierr.JWTError{}
+	}
+
+	return id, nil
+}
+
 func validate(token, secretKey string) (*jwt.Token, error) {
 	tokenParsed, err := jwt.Parse(token, func(t *jwt.Token) (interface{}, error) {
 		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -89,3 +163,9 @@ func WithAccessToken(t string) Options {
 		token.accessToken = t
 	}
 }
+
+func WithRefreshToken(t string) Options {
+	return func(token *Token) {
+		token.refreshToken = t
+	}
+}
@@ -0,0 +1,39 @@
+package transaction
+
+import "github.com/google/uuid"
+
+// Admin settles disputes between buyer and seller.
+type Admin struct {
+	ID uuid.UUID
+}
+
+// Refund returns the whole payment to the buyer.
+func (a Admin) Refund(t Transaction, note string) (Transaction, error) {
+	return fire(t, refund, a.command(note))
+}
+
+// Release pays the seller as if the buyer had confirmed.
+func (a Admin) Release(t Transaction, note string) (Transaction, error) {
+	return fire(t, release, a.command(note))
+}
+
+// Split refunds part of the seller net to the buyer and releases the rest to the seller.
+func (a Admin) Split(t Transaction, refundAmount Money, note string) (Transaction, error) {
+	c := a.command(note)
+	c.refund = refundAmount
+
+	return fire(t, split, c)
+}
+
+// AvailableActions returns the actions the admin can take on the transaction now.
+func (a Admin) AvailableActions(t Transaction) []Action {
+	return availableActions(t, a.command(""))
+}
+
+func (a Admin) command(note string) command {
+	return command{
+		actor:  admin,
+		admin:  a,
+		reason: note,
+	}
+}
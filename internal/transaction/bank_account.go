@@ -1,7 +1,18 @@
 package transaction
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type BankAccount struct {
 	ID uuid.UUID
+	// VerifiedAt is when the holder name was checked against the bank, seller only receives payout
+	// to a verified bank account.
+	VerifiedAt time.Time
+}
+
+func (b BankAccount) IsVerified() bool {
+	return b.ID != uuid.Nil && !b.VerifiedAt.IsZero()
 }
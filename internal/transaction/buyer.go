@@ -16,15 +16,22 @@ func (b Buyer) IsEligible() bool {
 	return !b.PhoneNumberVerifiedAt.IsZero()
 }
 
-func (b Buyer) Create(s Seller) (Transaction, error) {
+func (b Buyer) Create(s Seller, items []Item) (Transaction, error) {
 	if !b.IsEligible() {
 		return Transaction{}, ierr.BuyerIsNotEligible{ID: b.ID, Reason: "phone number is not verified yet"}
 	}
 
+	breakdown, err := calculateBreakdown(items)
+	if err != nil {
+		return Transaction{}, err
+	}
+
 	return Transaction{
 		ID:        uuid.New(),
 		Seller:    s,
 		Buyer:     b,
+		Items:     items,
+		Breakdown: breakdown,
 		CreatedBy: buyer,
 		CreatedAt: time.Now(),
 		Status:    waitingForApproval,
@@ -32,62 +39,38 @@ func (b Buyer) Create(s Seller) (Transaction, error) {
 }
 
 func (b Buyer) Accept(t Transaction) (Transaction, error) {
-	if !b.IsEligible() {
-		return Transaction{}, ierr.BuyerIsNotEligible{ID: b.ID, Reason: "phone number is not verified yet"}
-	}
-
-	if t.CreatedBy != seller {
-		return Transaction{}, ierr.TransactionNotCreatedBySeller{}
-	}
-
-	if !t.VerifyLastStatus(waitingForPayment) {
-		return Transaction{}, ierr.TransactionStatusNotValid{
-			LastStatus: t.Status.String(),
-			NewStatus:  waitingForPayment.String(),
-		}
-	}
-
-	t.AcceptedAt = time.Now()
-	t.AcceptedBy = buyer
-	t.Status = paid
-
-	return t, nil
+	return fire(t, accept, b.command())
 }
 
 func (b Buyer) Reject(t Transaction, reason string) (Transaction, error) {
-	if !t.VerifyLastStatus(rejected) {
-		return Transaction{}, ierr.TransactionStatusNotValid{
-			LastStatus: t.Status.String(),
-			NewStatus:  rejected.String(),
-		}
-	}
+	c := b.command()
+	c.reason = reason
 
-	if t.CreatedBy != seller {
-		return Transaction{}, ierr.TransactionNotCreatedBySeller{}
-	}
-
-	t.RejectedAt = time.Now()
-	t.RejectedBy = buyer
-	t.RejectedReason = reason
-	t.Status = rejected
-
-	return t, nil
+	return fire(t, reject, c)
 }
 
 func (b Buyer) Done(t Transaction) (Transaction, error) {
-	if !b.IsEligible() {
-		return Transaction{}, ierr.BuyerIsNotEligible{ID: b.ID, Reason: "phone number is not verified yet"}
-	}
+	return fire(t, done, b.command())
+}
 
-	if !t.VerifyLastStatus(success) {
-		return Transaction{}, ierr.TransactionStatusNotValid{
-			LastStatus: t.Status.String(),
-			NewStatus:  success.String(),
-		}
-	}
+// OpenDispute raises an issue with the goods instead of confirming, the funds stay in escrow
+// until an admin resolves it. The seller is given window to respond.
+func (b Buyer) OpenDispute(t Transaction, reason string, window time.Duration) (Transaction, error) {
+	c := b.command()
+	c.reason = reason
+	c.window = window
+
+	return fire(t, dispute, c)
+}
 
-	t.Status = success
-	t.SuccessAt = time.Now()
+// AvailableActions returns the actions the buyer can take on the transaction now.
+func (b Buyer) AvailableActions(t Transaction) []Action {
+	return availableActions(t, b.command())
+}
 
-	return t, nil
+func (b Buyer) command() command {
+	return command{
+		actor: buyer,
+		buyer: b,
+	}
 }
@@ -65,8 +65,17 @@ func TestBuyer_Create(t *testing.T) {
 		ID                    uuid.UUID
 		PhoneNumberVerifiedAt time.Time
 	}
+	items := []Item{
+		{
+			Name:     "mechanical keyboard",
+			Quantity: 2,
+			Price:    NewMoney(1_000_000_00, IDR),
+		},
+	}
+
 	type args struct {
-		s Seller
+		s     Seller
+		items []Item
 	}
 	tests := []struct {
 		name    string
@@ -84,6 +93,42 @@ func TestBuyer_Create(t *testing.T) {
 				s: Seller{
 					ID: uuidSeller,
 				},
+				items: items,
+			},
+			want:    Transaction{},
+			wantErr: true,
+		},
+		{
+			name: "transaction has no item",
+			fields: fields{
+				ID:                    uuidBuyer,
+				PhoneNumberVerifiedAt: verifiedAt,
+			},
+			args: args{
+				s: Seller{
+					ID: uuidSeller,
+				},
+			},
+			want:    Transaction{},
+			wantErr: true,
+		},
+		{
+			name: "item total is less than escrow fee",
+			fields: fields{
+				ID:                    uuidBuyer,
+				PhoneNumberVerifiedAt: verifiedAt,
+			},
+			args: args{
+				s: Seller{
+					ID: uuidSeller,
+				},
+				items: []Item{
+					{
+						Name:     "sticker",
+						Quantity: 1,
+						Price:    NewMoney(1_000_00, IDR),
+					},
+				},
 			},
 			want:    Transaction{},
 			wantErr: true,
@@ -98,6 +143,7 @@ func TestBuyer_Create(t *testing.T) {
 				s: Seller{
 					ID: uuidSeller,
 				},
+				items: items,
 			},
 			want: Transaction{
 				ID: uuid.MustParse("52fdfc07-2182-454f-963f-5f0f9a621d72"),
@@ -108,6 +154,12 @@ func TestBuyer_Create(t *testing.T) {
 					ID:                    uuidBuyer,
 					PhoneNumberVerifiedAt: verifiedAt,
 				},
+				Items: items,
+				Breakdown: Breakdown{
+					ItemTotal: NewMoney(2_000_000_00, IDR),
+					EscrowFee: NewMoney(20_000_00, IDR),
+					SellerNet: NewMoney(1_980_000_00, IDR),
+				},
 				CreatedBy: buyer,
 				CreatedAt: createdAt,
 				Status:    waitingForApproval,
@@ -121,7 +173,7 @@ func TestBuyer_Create(t *testing.T) {
 				ID:                    tt.fields.ID,
 				PhoneNumberVerifiedAt: tt.fields.PhoneNumberVerifiedAt,
 			}
-			got, err := b.Create(tt.args.s)
+			got, err := b.Create(tt.args.s, tt.args.items)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Buyer.Create() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -172,7 +224,7 @@ func TestBuyer_Accept(t *testing.T) {
 			want: Transaction{
 				ID:         trxUUID,
 				CreatedBy:  seller,
-				Status:     paid,
+				Status:     waitingForPayment,
 				AcceptedAt: acceptedAt,
 				AcceptedBy: buyer,
 			},
@@ -381,6 +433,7 @@ func TestBuyer_Done(t *testing.T) {
 				ID:        trxUUID,
 				Status:    success,
 				SuccessAt: successAt,
+				SuccessBy: buyer,
 			},
 			wantErr: false,
 		},
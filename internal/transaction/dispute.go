@@ -0,0 +1,90 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"rekber/ierr"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// disputeEscalated is sent to both parties when a dispute goes to admins without a seller response.
+const disputeEscalated NotificationKind = "dispute_escalated"
+
+// EscalateOverdueDisputes hands every dispute the seller did not respond to before its deadline to
+// admins and returns how many were escalated. The seller can not respond anymore, admins find the
+// escalated disputes with ListEscalatedDisputes.
+func (s Service) EscalateOverdueDisputes(ctx context.Context) (int, error) {
+	escalated := 0
+	for {
+		err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			t, err := s.repository.LockOverdueDispute(ctx, time.Now())
+			if err != nil {
+				return err
+			}
+
+			return s.escalateDispute(ctx, t)
+		})
+		if errors.As(err, &ierr.TransactionNotFound{}) {
+			return escalated, nil
+		}
+
+		if err != nil {
+			return escalated, err
+		}
+
+		escalated++
+	}
+}
+
+func (s Service) escalateDispute(ctx context.Context, t Transaction) error {
+	for _, userID := range []uuid.UUID{t.Buyer.ID, t.Seller.ID} {
+		n := Notification{
+			Kind:          disputeEscalated,
+			UserID:        userID,
+			TransactionID: t.ID,
+			Message:       "the seller did not respond to the dispute in time, an admin will resolve it",
+		}
+		if err := s.notifier.Notify(ctx, n); err != nil {
+			return fmt.Errorf("failed to notify dispute escalation: %w", err)
+		}
+	}
+
+	updated := t
+	updated.DisputeEscalatedAt = time.Now()
+	_, err := s.save(ctx, t, updated, Event{
+		TransactionID: t.ID,
+		Type:          eventDisputeEscalated,
+		Actor:         system,
+		OccurredAt:    updated.DisputeEscalatedAt,
+	})
+
+	return err
+}
+
+// ListEscalatedDisputes returns the escalated disputes which are not resolved yet to an admin,
+// oldest escalation first.
+func (s Service) ListEscalatedDisputes(ctx context.Context, userID uuid.UUID) ([]Response, error) {
+	caller, err := s.userRepository.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get caller by id: %w", err)
+	}
+
+	if !caller.IsAdmin {
+		return nil, ierr.UserForbiddenAccess{PhoneNumber: caller.PhoneNumber}
+	}
+
+	trxs, err := s.repository.ListEscalatedDisputes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list escalated disputes: %w", err)
+	}
+
+	result := make([]Response, 0, len(trxs))
+	for _, t := range trxs {
+		result = append(result, newResponse(t, newCommand(admin, caller)))
+	}
+
+	return result, nil
+}
@@ -0,0 +1,322 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"rekber/ierr"
+	"rekber/internal/ledger"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/google/uuid"
+)
+
+func TestBuyer_OpenDispute(t *testing.T) {
+	trxUUID := uuid.New()
+
+	disputedAt := time.Now()
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time {
+		return disputedAt
+	})
+	defer patches.Reset()
+
+	eligibleBuyer := Buyer{ID: uuid.New(), PhoneNumberVerifiedAt: disputedAt}
+
+	type args struct {
+		t      Transaction
+		reason string
+	}
+	tests := []struct {
+		name    string
+		b       Buyer
+		args    args
+		want    Transaction
+		wantErr error
+	}{
+		{
+			name: "transaction done by seller is disputed",
+			b:    eligibleBuyer,
+			args: args{
+				t:      Transaction{ID: trxUUID, Status: doneBySeller},
+				reason: "item is broken",
+			},
+			want: Transaction{
+				ID:              trxUUID,
+				Status:          disputed,
+				DisputedAt:      disputedAt,
+				DisputeReason:   "item is broken",
+				DisputeDeadline: disputedAt.Add(defaultDisputeWindow),
+			},
+		},
+		{
+			name: "dispute without reason",
+			b:    eligibleBuyer,
+			args: args{
+				t:      Transaction{ID: trxUUID, Status: paid},
+				reason: " ",
+			},
+			wantErr: ierr.DisputeNotValid{Reason: "reason is required"},
+		},
+		{
+			name: "success transaction cannot be disputed",
+			b:    eligibleBuyer,
+			args: args{
+				t:      Transaction{ID: trxUUID, Status: success},
+				reason: "item is broken",
+			},
+			wantErr: ierr.TransactionStatusNotValid{LastStatus: success.String(), NewStatus: disputed.String()},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.b.OpenDispute(tt.args.t, tt.args.reason, defaultDisputeWindow)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Buyer.OpenDispute() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Buyer.OpenDispute() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdmin_Split(t *testing.T) {
+	trxUUID := uuid.New()
+	adminUUID := uuid.New()
+
+	resolvedAt := time.Now()
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time {
+		return resolvedAt
+	})
+	defer patches.Reset()
+
+	breakdown := Breakdown{ItemTotal: NewMoney(100, "IDR"), EscrowFee: NewMoney(10, "IDR"), SellerNet: NewMoney(90, "IDR")}
+
+	tests := []struct {
+		name    string
+		refund  Money
+		want    Transaction
+		wantErr error
+	}{
+		{
+			name:   "part of seller net is refunded",
+			refund: NewMoney(30, "IDR"),
+			want: Transaction{
+				ID:             trxUUID,
+				Breakdown:      breakdown,
+				Status:         resolved,
+				ResolvedAt:     resolvedAt,
+				ResolvedBy:     adminUUID,
+				ResolutionNote: "half of the items are missing",
+				RefundAmount:   NewMoney(30, "IDR"),
+			},
+		},
+		{
+			name:    "whole seller net is refunded",
+			refund:  NewMoney(90, "IDR"),
+			wantErr: ierr.DisputeResolutionNotValid{Reason: "refund should be more than zero and less than " + breakdown.SellerNet.String()},
+		},
+		{
+			name:    "refund in other currency",
+			refund:  NewMoney(30, "USD"),
+			wantErr: ierr.DisputeResolutionNotValid{Reason: "refund currency should be IDR"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trx := Transaction{ID: trxUUID, Breakdown: breakdown, Status: disputed}
+			got, err := Admin{ID: adminUUID}.Split(trx, tt.refund, "half of the items are missing")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Admin.Split() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Admin.Split() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_ResolveDispute(t *testing.T) {
+	trxUUID := uuid.New()
+	adminUUID := uuid.New()
+	breakdown := Breakdown{ItemTotal: NewMoney(100, "IDR"), EscrowFee: NewMoney(10, "IDR"), SellerNet: NewMoney(90, "IDR")}
+
+	buyerUUID := uuid.New()
+	paidPayment := Payment{ID: uuid.New(), TransactionID: trxUUID, ExternalID: "charge-1", Amount: breakdown.ItemTotal, Status: paymentPaid}
+
+	tests := []struct {
+		name       string
+		userID     uuid.UUID
+		req        ResolveDisputeRequest
+		wantStatus Status
+		wantEntry  ledger.Kind
+		wantPayout int64
+		wantRefund Money
+		wantErr    error
+	}{
+		{
+			name:       "refund returns the item total to buyer",
+			userID:     adminUUID,
+			req:        ResolveDisputeRequest{Outcome: "refund"},
+			wantStatus: refunded,
+			wantEntry:  ledger.Refund,
+			wantRefund: NewMoney(100, "IDR"),
+		},
+		{
+			name:       "release pays the seller net",
+			userID:     adminUUID,
+			req:        ResolveDisputeRequest{Outcome: "release"},
+			wantStatus: success,
+			wantEntry:  ledger.Success,
+			wantPayout: 90,
+		},
+		{
+			name:       "split pays the seller net minus refund",
+			userID:     adminUUID,
+			req:        ResolveDisputeRequest{Outcome: "split", RefundAmount: 30},
+			wantStatus: resolved,
+			wantEntry:  ledger.Resolved,
+			wantPayout: 60,
+			wantRefund: NewMoney(30, "IDR"),
+		},
+		{
+			name:       "unknown outcome",
+			userID:     adminUUID,
+			req:        ResolveDisputeRequest{Outcome: "cancel"},
+			wantStatus: disputed,
+			wantErr:    ierr.DisputeResolutionNotValid{Reason: "outcome should be refund, release or split"},
+		},
+		{
+			name:       "user who is not admin does not find the transaction",
+			userID:     uuid.New(),
+			req:        ResolveDisputeRequest{Outcome: "refund"},
+			wantStatus: disputed,
+			wantErr:    ierr.TransactionNotFound{ID: trxUUID},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepository{trxs: map[uuid.UUID]Transaction{
+				trxUUID: {ID: trxUUID, Buyer: Buyer{ID: buyerUUID}, Seller: Seller{ID: uuid.New()}, Breakdown: breakdown, Status: disputed},
+			}}
+			payoutRepo := &fakePayoutRepository{}
+			refundRepo := &fakeRefundRepository{}
+			l := &fakeLedger{}
+			s := Service{
+				repository:         repo,
+				userRepository:     fakeUserRepository{admins: map[uuid.UUID]bool{adminUUID: true}},
+				payoutRepository:   payoutRepo,
+				paymentRepository:  &fakePaymentRepository{payments: map[string]Payment{paidPayment.ExternalID: paidPayment}},
+				refundRepository:   refundRepo,
+				transactor:         fakeTransactor{},
+				timelineRepository: &fakeTimelineRepository{},
+				eventStore:         &fakeEventStore{},
+				outbox:             &fakeOutbox{},
+				ledger:             l,
+				shipmentRepository: &fakeShipmentRepository{},
+			}
+
+			_, err := s.ResolveDispute(context.Background(), tt.userID, trxUUID, tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Service.ResolveDispute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got := repo.trxs[trxUUID].Status; got != tt.wantStatus {
+				t.Errorf("Service.ResolveDispute() status = %v, want %v", got, tt.wantStatus)
+			}
+			if tt.wantEntry != "" && (len(l.entries) != 1 || l.entries[0].Kind != tt.wantEntry) {
+				t.Errorf("Service.ResolveDispute() entries = %v, want one %v entry", l.entries, tt.wantEntry)
+			}
+			if tt.wantPayout == 0 && len(payoutRepo.payouts) != 0 {
+				t.Errorf("Service.ResolveDispute() payouts = %v, want none", payoutRepo.payouts)
+			}
+			if tt.wantPayout != 0 && (len(payoutRepo.payouts) != 1 || payoutRepo.payouts[0].Amount.Amount != tt.wantPayout) {
+				t.Errorf("Service.ResolveDispute() payouts = %v, want %v", payoutRepo.payouts, tt.wantPayout)
+			}
+			if tt.wantRefund.IsZero() && len(refundRepo.refunds) != 0 {
+				t.Errorf("Service.ResolveDispute() refunds = %v, want none", refundRepo.refunds)
+			}
+			if !tt.wantRefund.IsZero() && (len(refundRepo.refunds) != 1 || refundRepo.refunds[0].Amount != tt.wantRefund ||
+				refundRepo.refunds[0].BuyerID != buyerUUID || refundRepo.refunds[0].PaymentExternalID != paidPayment.ExternalID) {
+				t.Errorf("Service.ResolveDispute() refunds = %v, want %v from %s", refundRepo.refunds, tt.wantRefund, paidPayment.ExternalID)
+			}
+		})
+	}
+}
+
+func (f *fakeRepository) LockOverdueDispute(ctx context.Context, now time.Time) (Transaction, error) {
+	for _, t := range f.trxs {
+		if t.Status == disputed && t.DisputeRespondedAt.IsZero() && t.DisputeEscalatedAt.IsZero() && t.DisputeDeadline.Before(now) {
+			return t, nil
+		}
+	}
+
+	return Transaction{}, ierr.TransactionNotFound{}
+}
+
+func TestService_EscalateOverdueDisputes(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time {
+		return now
+	})
+	defer patches.Reset()
+
+	trxUUID := uuid.New()
+
+	tests := []struct {
+		name          string
+		trx           Transaction
+		wantEscalated int
+		wantNotified  int
+	}{
+		{
+			name:          "dispute past its deadline without response is escalated",
+			trx:           Transaction{ID: trxUUID, Status: disputed, DisputeDeadline: now.Add(-time.Minute)},
+			wantEscalated: 1,
+			wantNotified:  2,
+		},
+		{
+			name: "dispute before its deadline is left for the seller",
+			trx:  Transaction{ID: trxUUID, Status: disputed, DisputeDeadline: now.Add(time.Minute)},
+		},
+		{
+			name: "responded dispute is not escalated",
+			trx:  Transaction{ID: trxUUID, Status: disputed, DisputeDeadline: now.Add(-time.Minute), DisputeRespondedAt: now.Add(-time.Hour)},
+		},
+		{
+			name: "escalated dispute is not escalated again",
+			trx:  Transaction{ID: trxUUID, Status: disputed, DisputeDeadline: now.Add(-time.Hour), DisputeEscalatedAt: now.Add(-time.Minute)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepository{trxs: map[uuid.UUID]Transaction{trxUUID: tt.trx}}
+			n := &fakeNotifier{}
+			s := Service{
+				repository: repo,
+				transactor: fakeTransactor{},
+				notifier:   n,
+				eventStore: &fakeEventStore{},
+				outbox:     &fakeOutbox{},
+			}
+
+			got, err := s.EscalateOverdueDisputes(context.Background())
+			if err != nil {
+				t.Fatalf("Service.EscalateOverdueDisputes() error = %v", err)
+			}
+			if got != tt.wantEscalated {
+				t.Errorf("Service.EscalateOverdueDisputes() = %v, want %v", got, tt.wantEscalated)
+			}
+			if len(n.notifications) != tt.wantNotified {
+				t.Errorf("Service.EscalateOverdueDisputes() notifications = %v, want %v", len(n.notifications), tt.wantNotified)
+			}
+			if tt.wantEscalated > 0 && !repo.trxs[trxUUID].DisputeEscalatedAt.Equal(now) {
+				t.Errorf("Service.EscalateOverdueDisputes() escalated at = %v, want %v", repo.trxs[trxUUID].DisputeEscalatedAt, now)
+			}
+		})
+	}
+}
@@ -0,0 +1,384 @@
+package transaction
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MoneyRequest struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+type ItemRequest struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Quantity    int64        `json:"quantity"`
+	Price       MoneyRequest `json:"price"`
+}
+
+type CreateRequest struct {
+	// Role is the role of the caller in the transaction, either buyer or seller.
+	Role                   string        `json:"role"`
+	CounterpartPhoneNumber string        `json:"counterpart_phone_number"`
+	Items                  []ItemRequest `json:"items"`
+}
+
+type RejectRequest struct {
+	Reason string `json:"reason"`
+}
+
+type DoneRequest struct {
+	// CourierCode and AirwayBill are left empty when the goods are not shipped.
+	CourierCode string `json:"courier_code"`
+	AirwayBill  string `json:"airway_bill"`
+}
+
+type DisputeRequest struct {
+	Reason string `json:"reason"`
+}
+
+type RespondDisputeRequest struct {
+	Response string `json:"response"`
+}
+
+type ResolveDisputeRequest struct {
+	// Outcome is refund, release or split.
+	Outcome string `json:"outcome"`
+	// RefundAmount is returned to buyer on split, in the currency of the transaction.
+	RefundAmount int64  `json:"refund_amount"`
+	Note         string `json:"note"`
+}
+
+type PayRequest struct {
+	// Method is either virtual_account or qris.
+	Method string `json:"method"`
+	// BankCode is the bank of the virtual account, it is ignored for qris.
+	BankCode string `json:"bank_code"`
+}
+
+type MoneyResponse struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+type ItemResponse struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Quantity    int64         `json:"quantity"`
+	Price       MoneyResponse `json:"price"`
+}
+
+type BreakdownResponse struct {
+	ItemTotal MoneyResponse `json:"item_total"`
+	EscrowFee MoneyResponse `json:"escrow_fee"`
+	SellerNet MoneyResponse `json:"seller_net"`
+}
+
+type Response struct {
+	ID                  uuid.UUID         `json:"id"`
+	Role                string            `json:"role"`
+	BuyerID             uuid.UUID         `json:"buyer_id"`
+	SellerID            uuid.UUID         `json:"seller_id"`
+	Items               []ItemResponse    `json:"items"`
+	Breakdown           BreakdownResponse `json:"breakdown"`
+	Status              string            `json:"status"`
+	AvailableActions    []string          `json:"available_actions"`
+	CreatedBy           string            `json:"created_by"`
+	CreatedAt           time.Time         `json:"created_at"`
+	AcceptedBy          string            `json:"accepted_by,omitempty"`
+	AcceptedAt          *time.Time        `json:"accepted_at,omitempty"`
+	RejectedBy          string            `json:"rejected_by,omitempty"`
+	RejectedAt          *time.Time        `json:"rejected_at,omitempty"`
+	RejectedReason      string            `json:"rejected_reason,omitempty"`
+	PaidAt              *time.Time        `json:"paid_at,omitempty"`
+	ExpiredAt           *time.Time        `json:"expired_at,omitempty"`
+	ExpiredReason       string            `json:"expired_reason,omitempty"`
+	DoneBySellerAt      *time.Time        `json:"done_by_seller_at,omitempty"`
+	InspectionStartedAt *time.Time        `json:"inspection_started_at,omitempty"`
+	SuccessAt           *time.Time        `json:"success_at,omitempty"`
+	SuccessBy           string            `json:"success_by,omitempty"`
+	Dispute             *DisputeResponse  `json:"dispute,omitempty"`
+	Payout              *PayoutResponse   `json:"payout,omitempty"`
+	Refund              *RefundResponse   `json:"refund,omitempty"`
+	Shipment            *ShipmentResponse `json:"shipment,omitempty"`
+}
+
+func newMoneyResponse(m Money) MoneyResponse {
+	return MoneyResponse{
+		Amount:   m.Amount,
+		Currency: string(m.Currency),
+	}
+}
+
+func newTimeResponse(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+func newResponse(t Transaction, c command) Response {
+	items := make([]ItemResponse, 0, len(t.Items))
+	for _, item := range t.Items {
+		items = append(items, ItemResponse{
+			Name:        item.Name,
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			Price:       newMoneyResponse(item.Price),
+		})
+	}
+
+	actions := []string{}
+	for _, a := range availableActions(t, c) {
+		actions = append(actions, a.String())
+	}
+
+	return Response{
+		ID:       t.ID,
+		Role:     c.actor.String(),
+		BuyerID:  t.Buyer.ID,
+		SellerID: t.Seller.ID,
+		Items:    items,
+		Breakdown: BreakdownResponse{
+			ItemTotal: newMoneyResponse(t.Breakdown.ItemTotal),
+			EscrowFee: newMoneyResponse(t.Breakdown.EscrowFee),
+			SellerNet: newMoneyResponse(t.Breakdown.SellerNet),
+		},
+		Status:              t.Status.String(),
+		AvailableActions:    actions,
+		CreatedBy:           t.CreatedBy.String(),
+		CreatedAt:           t.CreatedAt,
+		AcceptedBy:          t.AcceptedBy.String(),
+		AcceptedAt:          newTimeResponse(t.AcceptedAt),
+		RejectedBy:          t.RejectedBy.String(),
+		RejectedAt:          newTimeResponse(t.RejectedAt),
+		RejectedReason:      t.RejectedReason,
+		PaidAt:              newTimeResponse(t.PaidAt),
+		ExpiredAt:           newTimeResponse(t.ExpiredAt),
+		ExpiredReason:       t.ExpiredReason,
+		DoneBySellerAt:      newTimeResponse(t.DoneBySellerAt),
+		InspectionStartedAt: newTimeResponse(t.InspectionStartedAt),
+		SuccessAt:           newTimeResponse(t.SuccessAt),
+		SuccessBy:           t.SuccessBy.String(),
+		Dispute:             newDisputeResponse(t),
+	}
+}
+
+type DisputeResponse struct {
+	Reason         string         `json:"reason"`
+	OpenedAt       time.Time      `json:"opened_at"`
+	Deadline       time.Time      `json:"deadline"`
+	Response       string         `json:"response,omitempty"`
+	RespondedAt    *time.Time     `json:"responded_at,omitempty"`
+	EscalatedAt    *time.Time     `json:"escalated_at,omitempty"`
+	ResolvedAt     *time.Time     `json:"resolved_at,omitempty"`
+	ResolutionNote string         `json:"resolution_note,omitempty"`
+	RefundAmount   *MoneyResponse `json:"refund_amount,omitempty"`
+}
+
+func newDisputeResponse(t Transaction) *DisputeResponse {
+	if t.DisputedAt.IsZero() {
+		return nil
+	}
+
+	resp := &DisputeResponse{
+		Reason:         t.DisputeReason,
+		OpenedAt:       t.DisputedAt,
+		Deadline:       t.DisputeDeadline,
+		Response:       t.DisputeResponse,
+		RespondedAt:    newTimeResponse(t.DisputeRespondedAt),
+		EscalatedAt:    newTimeResponse(t.DisputeEscalatedAt),
+		ResolvedAt:     newTimeResponse(t.ResolvedAt),
+		ResolutionNote: t.ResolutionNote,
+	}
+	if !t.RefundAmount.IsZero() {
+		refund := newMoneyResponse(t.RefundAmount)
+		resp.RefundAmount = &refund
+	}
+
+	return resp
+}
+
+type PaymentResponse struct {
+	ID                   uuid.UUID     `json:"id"`
+	TransactionID        uuid.UUID     `json:"transaction_id"`
+	Method               string        `json:"method"`
+	BankCode             string        `json:"bank_code,omitempty"`
+	VirtualAccountNumber string        `json:"virtual_account_number,omitempty"`
+	QRString             string        `json:"qr_string,omitempty"`
+	Amount               MoneyResponse `json:"amount"`
+	Status               string        `json:"status"`
+	ExpiredAt            time.Time     `json:"expired_at"`
+	PaidAt               *time.Time    `json:"paid_at,omitempty"`
+	RefundReason         string        `json:"refund_reason,omitempty"`
+}
+
+func newPaymentResponse(p Payment) PaymentResponse {
+	return PaymentResponse{
+		ID:                   p.ID,
+		TransactionID:        p.TransactionID,
+		Method:               string(p.Method),
+		BankCode:             p.BankCode,
+		VirtualAccountNumber: p.VirtualAccountNumber,
+		QRString:             p.QRString,
+		Amount:               newMoneyResponse(p.Amount),
+		Status:               string(p.Status),
+		ExpiredAt:            p.ExpiredAt,
+		PaidAt:               newTimeResponse(p.PaidAt),
+		RefundReason:         p.RefundReason,
+	}
+}
+
+type PayoutResponse struct {
+	ID            uuid.UUID     `json:"id"`
+	BankCode      string        `json:"bank_code"`
+	AccountNumber string        `json:"account_number"`
+	AccountName   string        `json:"account_name"`
+	Amount        MoneyResponse `json:"amount"`
+	Status        string        `json:"status"`
+	Attempts      int           `json:"attempts"`
+	SucceededAt   *time.Time    `json:"succeeded_at,omitempty"`
+	FailedAt      *time.Time    `json:"failed_at,omitempty"`
+}
+
+func newPayoutResponse(p Payout) *PayoutResponse {
+	return &PayoutResponse{
+		ID:            p.ID,
+		BankCode:      p.BankCode,
+		AccountNumber: p.AccountNumber,
+		AccountName:   p.AccountName,
+		Amount:        newMoneyResponse(p.Amount),
+		Status:        string(p.Status),
+		Attempts:      p.Attempts,
+		SucceededAt:   newTimeResponse(p.SucceededAt),
+		FailedAt:      newTimeResponse(p.FailedAt),
+	}
+}
+
+type RefundResponse struct {
+	ID          uuid.UUID     `json:"id"`
+	Amount      MoneyResponse `json:"amount"`
+	Status      string        `json:"status"`
+	Attempts    int           `json:"attempts"`
+	SucceededAt *time.Time    `json:"succeeded_at,omitempty"`
+	FailedAt    *time.Time    `json:"failed_at,omitempty"`
+}
+
+func newRefundResponse(r Refund) *RefundResponse {
+	return &RefundResponse{
+		ID:          r.ID,
+		Amount:      newMoneyResponse(r.Amount),
+		Status:      string(r.Status),
+		Attempts:    r.Attempts,
+		SucceededAt: newTimeResponse(r.SucceededAt),
+		FailedAt:    newTimeResponse(r.FailedAt),
+	}
+}
+
+type TrackingEventResponse struct {
+	Status      string    `json:"status"`
+	Description string    `json:"description"`
+	Location    string    `json:"location,omitempty"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
+
+type ShipmentResponse struct {
+	CourierCode   string                  `json:"courier_code"`
+	AirwayBill    string                  `json:"airway_bill"`
+	Status        string                  `json:"status"`
+	Events        []TrackingEventResponse `json:"events"`
+	DeliveredAt   *time.Time              `json:"delivered_at,omitempty"`
+	LastCheckedAt *time.Time              `json:"last_checked_at,omitempty"`
+}
+
+func newShipmentResponse(s Shipment) *ShipmentResponse {
+	events := make([]TrackingEventResponse, 0, len(s.Events))
+	for _, e := range s.Events {
+		events = append(events, TrackingEventResponse{
+			Status:      e.Status,
+			Description: e.Description,
+			Location:    e.Location,
+			OccurredAt:  e.OccurredAt,
+		})
+	}
+
+	return &ShipmentResponse{
+		CourierCode:   s.CourierCode,
+		AirwayBill:    s.AirwayBill,
+		Status:        string(s.Status),
+		Events:        events,
+		DeliveredAt:   newTimeResponse(s.DeliveredAt),
+		LastCheckedAt: newTimeResponse(s.LastCheckedAt),
+	}
+}
+
+type TimelineEventResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	From        string     `json:"from,omitempty"`
+	To          string     `json:"to"`
+	Action      string     `json:"action"`
+	Actor       string     `json:"actor"`
+	ActorUserID *uuid.UUID `json:"actor_user_id,omitempty"`
+	Reason      string     `json:"reason,omitempty"`
+	RequestID   string     `json:"request_id,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func newTimelineEventResponse(e TimelineEvent) TimelineEventResponse {
+	resp := TimelineEventResponse{
+		ID:        e.ID,
+		From:      e.From.String(),
+		To:        e.To.String(),
+		Action:    e.Action.String(),
+		Actor:     e.Actor.String(),
+		Reason:    e.Reason,
+		RequestID: e.RequestID,
+		CreatedAt: e.CreatedAt,
+	}
+	if e.ActorUserID != uuid.Nil {
+		actorUserID := e.ActorUserID
+		resp.ActorUserID = &actorUserID
+	}
+
+	return resp
+}
+
+// EventMessage is the payload of the message published for every event of a transaction.
+type EventMessage struct {
+	TransactionID uuid.UUID  `json:"transaction_id"`
+	Version       int        `json:"version"`
+	Type          string     `json:"type"`
+	Actor         string     `json:"actor"`
+	ActorUserID   *uuid.UUID `json:"actor_user_id,omitempty"`
+	Reason        string     `json:"reason,omitempty"`
+	BuyerID       uuid.UUID  `json:"buyer_id"`
+	SellerID      uuid.UUID  `json:"seller_id"`
+	// PreviousStatus is empty for the created event.
+	PreviousStatus string    `json:"previous_status,omitempty"`
+	Status         string    `json:"status"`
+	OccurredAt     time.Time `json:"occurred_at"`
+}
+
+func newEventMessage(from Status, t Transaction, e Event) EventMessage {
+	m := EventMessage{
+		TransactionID:  t.ID,
+		Version:        e.Version,
+		Type:           string(e.Type),
+		Actor:          e.Actor.String(),
+		Reason:         e.Reason,
+		BuyerID:        t.Buyer.ID,
+		SellerID:       t.Seller.ID,
+		PreviousStatus: from.String(),
+		Status:         t.Status.String(),
+		OccurredAt:     e.OccurredAt,
+	}
+	if e.UserID != uuid.Nil {
+		userID := e.UserID
+		m.ActorUserID = &userID
+	}
+
+	return m
+}
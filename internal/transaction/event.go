@@ -0,0 +1,246 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"rekber/ierr"
+	"rekber/internal/outbox"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EventType string
+
+const (
+	eventCreated EventType = "created"
+	// eventImported carries a transaction created before events were recorded, as it was then.
+	eventImported         EventType = "imported"
+	eventReleaseReminded  EventType = "release_reminded"
+	eventDisputeEscalated EventType = "dispute_escalated"
+)
+
+// Event is a fact which happened to a transaction, the transaction is the fold of its events.
+// Events fired through the transition table are named after their action.
+type Event struct {
+	TransactionID uuid.UUID
+	// Version is the version of the transaction after the event, starting from 1.
+	Version  int
+	Type     EventType
+	Actor    Actors
+	UserID   uuid.UUID // nil when the actor is system
+	Reason   string
+	Refund   Money
+	Shipping Shipping
+	// Window is the response window of a dispute event.
+	Window time.Duration
+	// DeliveredAt is when the courier delivered the goods of a deliver event.
+	DeliveredAt time.Time
+	// Snapshot is the whole transaction for created and imported events.
+	Snapshot   Transaction
+	OccurredAt time.Time
+}
+
+type EventStore interface {
+	// Append stores the events after expectedVersion of the transaction. It returns
+	// ierr.EventVersionConflict when another writer has appended after expectedVersion.
+	Append(ctx context.Context, transactionID uuid.UUID, expectedVersion int, events ...Event) error
+	// Load returns the events of the transaction, oldest first.
+	Load(ctx context.Context, transactionID uuid.UUID) ([]Event, error)
+	ListTransactionIDs(ctx context.Context) ([]uuid.UUID, error)
+}
+
+func newEvent(t Transaction, a Action, c command) Event {
+	return Event{
+		TransactionID: t.ID,
+		Type:          EventType(a.String()),
+		Actor:         c.actor,
+		UserID:        c.userID(),
+		Reason:        c.reason,
+		Refund:        c.refund,
+		Shipping:      c.shipping,
+		Window:        c.window,
+		DeliveredAt:   c.deliveredAt,
+		OccurredAt:    c.at,
+	}
+}
+
+func newSnapshotEvent(t Transaction, typ EventType, at time.Time) Event {
+	return Event{
+		TransactionID: t.ID,
+		Type:          typ,
+		Actor:         t.CreatedBy,
+		Snapshot:      t,
+		OccurredAt:    at,
+	}
+}
+
+// command returns the command the event was fired with.
+func (e Event) command() command {
+	c := command{
+		actor:       e.Actor,
+		reason:      e.Reason,
+		refund:      e.Refund,
+		shipping:    e.Shipping,
+		window:      e.Window,
+		deliveredAt: e.DeliveredAt,
+		at:          e.OccurredAt,
+	}
+	switch e.Actor {
+	case buyer:
+		c.buyer = Buyer{ID: e.UserID}
+	case seller:
+		c.seller = Seller{ID: e.UserID}
+	case admin:
+		c.admin = Admin{ID: e.UserID}
+	}
+
+	return c
+}
+
+func parseAction(s string) (Action, bool) {
+	for a := accept; a <= create; a++ {
+		if a.String() == s {
+			return a, true
+		}
+	}
+
+	return 0, false
+}
+
+// fold rebuilds the transaction from its events, oldest first. The events are facts so guards and
+// validators are not checked again, only the hooks are replayed.
+func fold(events []Event) (Transaction, error) {
+	var t Transaction
+	for _, e := range events {
+		var err error
+		if t, err = t.apply(e); err != nil {
+			return Transaction{}, err
+		}
+	}
+
+	return t, nil
+}
+
+func (t Transaction) apply(e Event) (Transaction, error) {
+	if e.Version != t.Version+1 {
+		return Transaction{}, ierr.EventNotValid{TransactionID: e.TransactionID, Version: e.Version, Reason: fmt.Sprintf("version %d is expected", t.Version+1)}
+	}
+
+	switch e.Type {
+	case eventCreated, eventImported:
+		if e.Version != 1 {
+			return Transaction{}, ierr.EventNotValid{TransactionID: e.TransactionID, Version: e.Version, Reason: "transaction exists already"}
+		}
+
+		t = e.Snapshot
+	case eventReleaseReminded:
+		t.ReleaseRemindedAt = e.OccurredAt
+	case eventDisputeEscalated:
+		t.DisputeEscalatedAt = e.OccurredAt
+	default:
+		a, ok := parseAction(string(e.Type))
+		if !ok {
+			return Transaction{}, ierr.EventNotValid{TransactionID: e.TransactionID, Version: e.Version, Reason: "type " + string(e.Type) + " is unknown"}
+		}
+
+		tr, ok := findTransition(t.Status, a, e.Actor)
+		if !ok {
+			return Transaction{}, ierr.EventNotValid{TransactionID: e.TransactionID, Version: e.Version, Reason: fmt.Sprintf("%s cannot %s a transaction %s", e.Actor, a, t.Status)}
+		}
+
+		t = tr.apply(t, e.command())
+	}
+
+	t.Version = e.Version
+	return t, nil
+}
+
+// save appends the event which turns t into updated, saves updated as the read model and
+// publishes the event. A transaction created before events were recorded gets its snapshot as the
+// first event, which is not published. It must be called within a transaction.
+func (s Service) save(ctx context.Context, t, updated Transaction, e Event) (Transaction, error) {
+	events := []Event{e}
+	if t.Version == 0 {
+		events = []Event{newSnapshotEvent(t, eventImported, e.OccurredAt), e}
+	}
+
+	for i := range events {
+		events[i].Version = t.Version + i + 1
+	}
+
+	if err := s.eventStore.Append(ctx, t.ID, t.Version, events...); err != nil {
+		return Transaction{}, fmt.Errorf("failed to append events: %w", err)
+	}
+
+	updated.Version = events[len(events)-1].Version
+	if err := s.repository.Update(ctx, updated, t.Status); err != nil {
+		return Transaction{}, fmt.Errorf("failed to update transaction: %w", err)
+	}
+
+	if err := s.publish(ctx, t.Status, updated, events[len(events)-1]); err != nil {
+		return Transaction{}, err
+	}
+
+	return updated, nil
+}
+
+// publish enqueues the event to the outbox for other systems, it must be called within the
+// transaction which appends the event so the message is sent only if the event is stored.
+func (s Service) publish(ctx context.Context, from Status, t Transaction, e Event) error {
+	m, err := outbox.NewMessage("transaction."+string(e.Type), t.ID.String(), newEventMessage(from, t, e))
+	if err != nil {
+		return fmt.Errorf("failed to create outbox message: %w", err)
+	}
+
+	if err := s.outbox.Enqueue(ctx, m); err != nil {
+		return fmt.Errorf("failed to enqueue event: %w", err)
+	}
+
+	return nil
+}
+
+// RebuildProjections replaces every transaction row with the fold of its events and returns how
+// many were rebuilt. Transactions created before events were recorded are imported first, so
+// they are kept as they are. It is meant to run while no other instance is serving.
+func (s Service) RebuildProjections(ctx context.Context) (int, error) {
+	legacy, err := s.repository.ListUnrecorded(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list transactions without events: %w", err)
+	}
+
+	for _, t := range legacy {
+		e := newSnapshotEvent(t, eventImported, time.Now())
+		e.Version = 1
+		if err := s.eventStore.Append(ctx, t.ID, 0, e); err != nil && !errors.As(err, &ierr.EventVersionConflict{}) {
+			return 0, fmt.Errorf("failed to import transaction %s: %w", t.ID, err)
+		}
+	}
+
+	ids, err := s.eventStore.ListTransactionIDs(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list transactions with events: %w", err)
+	}
+
+	for i, id := range ids {
+		err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			events, err := s.eventStore.Load(ctx, id)
+			if err != nil {
+				return fmt.Errorf("failed to load events: %w", err)
+			}
+
+			t, err := fold(events)
+			if err != nil {
+				return err
+			}
+
+			return s.repository.Project(ctx, t)
+		})
+		if err != nil {
+			return i, fmt.Errorf("failed to rebuild transaction %s: %w", id, err)
+		}
+	}
+
+	return len(ids), nil
+}
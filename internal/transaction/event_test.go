@@ -0,0 +1,310 @@
+package transaction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"rekber/ierr"
+	"rekber/internal/outbox"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/google/uuid"
+)
+
+type fakeEventStore struct {
+	events map[uuid.UUID][]Event
+}
+
+func (f *fakeEventStore) Append(ctx context.Context, transactionID uuid.UUID, expectedVersion int, events ...Event) error {
+	if f.events == nil {
+		f.events = make(map[uuid.UUID][]Event)
+	}
+
+	if len(f.events[transactionID]) != expectedVersion {
+		return ierr.EventVersionConflict{TransactionID: transactionID, Version: expectedVersion + 1}
+	}
+
+	f.events[transactionID] = append(f.events[transactionID], events...)
+	return nil
+}
+
+func (f *fakeEventStore) Load(ctx context.Context, transactionID uuid.UUID) ([]Event, error) {
+	return f.events[transactionID], nil
+}
+
+func (f *fakeEventStore) ListTransactionIDs(ctx context.Context) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0, len(f.events))
+	for id := range f.events {
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+type fakeOutbox struct {
+	messages []outbox.Message
+}
+
+func (f *fakeOutbox) Enqueue(ctx context.Context, m outbox.Message) error {
+	f.messages = append(f.messages, m)
+	return nil
+}
+
+func (f *fakeRepository) ListUnrecorded(ctx context.Context) ([]Transaction, error) {
+	var trxs []Transaction
+	for _, t := range f.trxs {
+		if t.Version == 0 {
+			trxs = append(trxs, t)
+		}
+	}
+
+	return trxs, nil
+}
+
+func (f *fakeRepository) Project(ctx context.Context, t Transaction) error {
+	f.trxs[t.ID] = t
+	return nil
+}
+
+func Test_fold(t *testing.T) {
+	timeNow := time.Date(2023, 9, 30, 21, 0, 0, 0, time.UTC)
+	verifiedAt := timeNow.Add(-24 * time.Hour)
+	b := Buyer{ID: uuid.New(), PhoneNumberVerifiedAt: verifiedAt}
+	s := Seller{ID: uuid.New(), PhoneNumberVerifiedAt: verifiedAt, BankAccount: BankAccount{ID: uuid.New(), VerifiedAt: verifiedAt}}
+	created := Transaction{
+		ID:        uuid.New(),
+		Buyer:     Buyer{ID: b.ID},
+		Seller:    Seller{ID: s.ID},
+		Breakdown: Breakdown{ItemTotal: NewMoney(100, "IDR"), EscrowFee: NewMoney(10, "IDR"), SellerNet: NewMoney(90, "IDR")},
+		CreatedBy: buyer,
+		CreatedAt: timeNow,
+		Status:    waitingForApproval,
+	}
+
+	shipped := s.command()
+	shipped.shipping = Shipping{CourierCode: "jne", AirwayBill: "JNE0001"}
+	disputed := b.command()
+	disputed.reason = "goods are broken"
+	disputed.window = 24 * time.Hour
+	splitBy := Admin{ID: uuid.New()}.command("")
+	splitBy.refund = NewMoney(40, "IDR")
+
+	type step struct {
+		a Action
+		c command
+	}
+
+	// history fires every step like the service does and returns its events and the result
+	history := func(steps ...step) ([]Event, Transaction) {
+		first := newSnapshotEvent(created, eventCreated, created.CreatedAt)
+		first.Version = 1
+		events := []Event{first}
+		trx := created
+		trx.Version = 1
+		for i, st := range steps {
+			st.c.at = timeNow.Add(time.Duration(i+1) * time.Hour)
+			updated, err := fire(trx, st.a, st.c)
+			if err != nil {
+				t.Fatalf("fire(%v) error = %v", st.a, err)
+			}
+
+			e := newEvent(updated, st.a, st.c)
+			e.Version = trx.Version + 1
+			updated.Version = e.Version
+			events = append(events, e)
+			trx = updated
+		}
+
+		return events, trx
+	}
+	shippedEvents, shippedTrx := history(step{accept, s.command()}, step{pay, System{}.command()}, step{done, shipped}, step{deliver, System{}.command()}, step{done, b.command()})
+	splitEvents, splitTrx := history(step{accept, s.command()}, step{pay, System{}.command()}, step{dispute, disputed}, step{split, splitBy})
+	remindedEvents := append(shippedEvents[:5:5], Event{TransactionID: created.ID, Version: 6, Type: eventReleaseReminded, Actor: system, OccurredAt: timeNow.Add(10 * time.Hour)})
+
+	tests := []struct {
+		name    string
+		events  []Event
+		want    Transaction
+		wantErr bool
+	}{
+		{
+			name:   "shipped transaction confirmed by buyer",
+			events: shippedEvents,
+			want:   shippedTrx,
+		},
+		{
+			name:   "disputed transaction split by admin",
+			events: splitEvents,
+			want:   splitTrx,
+		},
+		{
+			name:    "version is skipped",
+			events:  []Event{shippedEvents[0], shippedEvents[2]},
+			wantErr: true,
+		},
+		{
+			name:    "transaction is created twice",
+			events:  []Event{shippedEvents[0], {TransactionID: created.ID, Version: 2, Type: eventCreated, Snapshot: created}},
+			wantErr: true,
+		},
+		{
+			name:    "action is not allowed in the status",
+			events:  []Event{shippedEvents[0], {TransactionID: created.ID, Version: 2, Type: "done", Actor: seller, UserID: s.ID}},
+			wantErr: true,
+		},
+		{
+			name:    "type is unknown",
+			events:  []Event{shippedEvents[0], {TransactionID: created.ID, Version: 2, Type: "archived", Actor: system}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fold(tt.events)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("fold() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fold() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("release reminder is folded", func(t *testing.T) {
+		got, err := fold(remindedEvents)
+		if err != nil {
+			t.Fatalf("fold() error = %v", err)
+		}
+		if !got.ReleaseRemindedAt.Equal(timeNow.Add(10*time.Hour)) || got.Version != 6 || got.Status != doneBySeller {
+			t.Errorf("fold() = %v, want reminded transaction done by seller at version 6", got)
+		}
+	})
+}
+
+func TestService_RebuildProjections(t *testing.T) {
+	timeNow := time.Date(2023, 9, 30, 21, 0, 0, 0, time.UTC)
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time {
+		return timeNow
+	})
+	defer patches.Reset()
+
+	legacyUUID := uuid.New()
+	staleUUID := uuid.New()
+	sellerUUID := uuid.New()
+
+	legacy := Transaction{ID: legacyUUID, CreatedBy: buyer, Status: paid, PaidAt: timeNow.Add(-time.Hour)}
+	created := Transaction{ID: staleUUID, Seller: Seller{ID: sellerUUID}, CreatedBy: buyer, Status: waitingForApproval, Version: 1}
+	first := newSnapshotEvent(created, eventCreated, timeNow.Add(-2*time.Hour))
+	first.Version = 1
+
+	repo := &fakeRepository{trxs: map[uuid.UUID]Transaction{legacyUUID: legacy, staleUUID: created}}
+	store := &fakeEventStore{events: map[uuid.UUID][]Event{
+		staleUUID: {first, {TransactionID: staleUUID, Version: 2, Type: "accept", Actor: seller, UserID: sellerUUID, OccurredAt: timeNow.Add(-time.Hour)}},
+	}}
+	s := Service{
+		repository: repo,
+		transactor: fakeTransactor{},
+		eventStore: store,
+	}
+
+	got, err := s.RebuildProjections(context.Background())
+	if err != nil {
+		t.Fatalf("Service.RebuildProjections() error = %v", err)
+	}
+	if got != 2 {
+		t.Errorf("Service.RebuildProjections() = %v, want %v", got, 2)
+	}
+
+	wantLegacy := legacy
+	wantLegacy.Version = 1
+	if !reflect.DeepEqual(repo.trxs[legacyUUID], wantLegacy) {
+		t.Errorf("legacy transaction = %v, want %v", repo.trxs[legacyUUID], wantLegacy)
+	}
+
+	stale := repo.trxs[staleUUID]
+	if stale.Status != waitingForPayment || stale.Version != 2 || !stale.AcceptedAt.Equal(timeNow.Add(-time.Hour)) {
+		t.Errorf("stale transaction = %v, want accepted at version 2", stale)
+	}
+}
+
+func TestService_save(t *testing.T) {
+	timeNow := time.Date(2023, 9, 30, 21, 0, 0, 0, time.UTC)
+	trxUUID := uuid.New()
+
+	tests := []struct {
+		name        string
+		t           Transaction
+		stored      []Event
+		wantTypes   []EventType
+		wantVersion int
+		wantErr     error
+	}{
+		{
+			name:        "event is appended after the last version",
+			t:           Transaction{ID: trxUUID, Status: paid, Version: 1},
+			stored:      []Event{{TransactionID: trxUUID, Version: 1, Type: eventCreated}},
+			wantTypes:   []EventType{eventCreated, "done"},
+			wantVersion: 2,
+		},
+		{
+			name:        "transaction created before events is imported first",
+			t:           Transaction{ID: trxUUID, Status: paid},
+			wantTypes:   []EventType{eventImported, "done"},
+			wantVersion: 2,
+		},
+		{
+			name:    "another writer has appended first",
+			t:       Transaction{ID: trxUUID, Status: paid, Version: 1},
+			stored:  []Event{{TransactionID: trxUUID, Version: 1, Type: eventCreated}, {TransactionID: trxUUID, Version: 2, Type: "done"}},
+			wantErr: ierr.EventVersionConflict{TransactionID: trxUUID, Version: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepository{trxs: map[uuid.UUID]Transaction{trxUUID: tt.t}}
+			store := &fakeEventStore{events: map[uuid.UUID][]Event{trxUUID: tt.stored}}
+			ob := &fakeOutbox{}
+			s := Service{repository: repo, eventStore: store, outbox: ob}
+
+			updated := tt.t
+			updated.Status = doneBySeller
+			got, err := s.save(context.Background(), tt.t, updated, Event{TransactionID: trxUUID, Type: "done", Actor: seller, OccurredAt: timeNow})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Service.save() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if got.Version != tt.wantVersion || repo.trxs[trxUUID].Version != tt.wantVersion {
+				t.Errorf("Service.save() version = %v, want %v", got.Version, tt.wantVersion)
+			}
+
+			var types []EventType
+			for i, e := range store.events[trxUUID] {
+				types = append(types, e.Type)
+				if e.Version != i+1 {
+					t.Errorf("event %v version = %v, want %v", e.Type, e.Version, i+1)
+				}
+			}
+			if !reflect.DeepEqual(types, tt.wantTypes) {
+				t.Errorf("Service.save() events = %v, want %v", types, tt.wantTypes)
+			}
+
+			if len(ob.messages) != 1 {
+				t.Fatalf("Service.save() published %v messages, want 1", len(ob.messages))
+			}
+
+			var msg EventMessage
+			if err := json.Unmarshal(ob.messages[0].Payload, &msg); err != nil {
+				t.Fatalf("failed to unmarshal payload: %v", err)
+			}
+			if ob.messages[0].Topic != "transaction.done" || ob.messages[0].Key != trxUUID.String() || msg.Version != tt.wantVersion || msg.Status != doneBySeller.String() {
+				t.Errorf("Service.save() published %v with %+v", ob.messages[0].Topic, msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"rekber/ierr"
+	"time"
+)
+
+const (
+	approvalExpiredReason = "not answered within the approval window"
+	paymentExpiredReason  = "not paid within the payment window"
+)
+
+// Deadlines is how long a transaction may stay in a status before it is expired, a zero
+// window never expires the status.
+type Deadlines struct {
+	// Approval counts from the creation of the transaction.
+	Approval time.Duration
+	// Payment counts from the acceptance of the transaction.
+	Payment time.Duration
+}
+
+// cutoff returns the time before which the window started for the transaction to be overdue now.
+func cutoff(window time.Duration, now time.Time) time.Time {
+	if window <= 0 {
+		return time.Time{}
+	}
+
+	return now.Add(-window)
+}
+
+func expiredReason(t Transaction) string {
+	if t.Status == waitingForApproval {
+		return approvalExpiredReason
+	}
+
+	return paymentExpiredReason
+}
+
+// ExpireOverdue expires every transaction past its deadline and returns how many were expired.
+// Each transaction is locked while it is expired so replicas running it at the same time skip
+// it instead of expiring it twice.
+func (s Service) ExpireOverdue(ctx context.Context, d Deadlines) (int, error) {
+	expiredCount := 0
+	for {
+		err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			now := time.Now()
+			t, err := s.repository.LockOverdue(ctx, cutoff(d.Approval, now), cutoff(d.Payment, now))
+			if err != nil {
+				return err
+			}
+
+			c := System{}.command()
+			c.reason = expiredReason(t)
+			_, err = s.transition(ctx, t, expire, c)
+			return err
+		})
+		if errors.As(err, &ierr.TransactionNotFound{}) {
+			return expiredCount, nil
+		}
+
+		if err != nil {
+			return expiredCount, err
+		}
+
+		expiredCount++
+	}
+}
@@ -0,0 +1,102 @@
+package transaction
+
+import (
+	"context"
+	"rekber/ierr"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/google/uuid"
+)
+
+func (f *fakeRepository) LockOverdue(ctx context.Context, approvalCutoff, paymentCutoff time.Time) (Transaction, error) {
+	for _, t := range f.trxs {
+		if (t.Status == waitingForApproval && t.CreatedAt.Before(approvalCutoff)) ||
+			(t.Status == waitingForPayment && t.AcceptedAt.Before(paymentCutoff)) {
+			return t, nil
+		}
+	}
+
+	return Transaction{}, ierr.TransactionNotFound{}
+}
+
+func TestService_ExpireOverdue(t *testing.T) {
+	timeNow := time.Date(2023, 9, 30, 21, 0, 0, 0, time.UTC)
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time {
+		return timeNow
+	})
+	defer patches.Reset()
+
+	overdueApproval := uuid.New()
+	freshApproval := uuid.New()
+	overduePayment := uuid.New()
+	freshPayment := uuid.New()
+	paidTrx := uuid.New()
+
+	trxs := func() map[uuid.UUID]Transaction {
+		return map[uuid.UUID]Transaction{
+			overdueApproval: {ID: overdueApproval, Status: waitingForApproval, CreatedAt: timeNow.Add(-73 * time.Hour)},
+			freshApproval:   {ID: freshApproval, Status: waitingForApproval, CreatedAt: timeNow.Add(-time.Hour)},
+			overduePayment:  {ID: overduePayment, Status: waitingForPayment, CreatedAt: timeNow.Add(-50 * time.Hour), AcceptedAt: timeNow.Add(-49 * time.Hour)},
+			freshPayment:    {ID: freshPayment, Status: waitingForPayment, CreatedAt: timeNow.Add(-50 * time.Hour), AcceptedAt: timeNow.Add(-time.Hour)},
+			paidTrx:         {ID: paidTrx, Status: paid, CreatedAt: timeNow.Add(-100 * time.Hour), AcceptedAt: timeNow.Add(-99 * time.Hour)},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		deadlines   Deadlines
+		want        int
+		wantExpired map[uuid.UUID]string
+	}{
+		{
+			name:      "overdue transactions are expired with the reason of their window",
+			deadlines: Deadlines{Approval: 72 * time.Hour, Payment: 48 * time.Hour},
+			want:      2,
+			wantExpired: map[uuid.UUID]string{
+				overdueApproval: approvalExpiredReason,
+				overduePayment:  paymentExpiredReason,
+			},
+		},
+		{
+			name:      "zero window never expires its status",
+			deadlines: Deadlines{Payment: 48 * time.Hour},
+			want:      1,
+			wantExpired: map[uuid.UUID]string{
+				overduePayment: paymentExpiredReason,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepository{trxs: trxs()}
+			s := Service{
+				repository:         repo,
+				transactor:         fakeTransactor{},
+				timelineRepository: &fakeTimelineRepository{},
+				eventStore:         &fakeEventStore{},
+				outbox:             &fakeOutbox{},
+			}
+
+			got, err := s.ExpireOverdue(context.Background(), tt.deadlines)
+			if err != nil {
+				t.Fatalf("Service.ExpireOverdue() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Service.ExpireOverdue() = %v, want %v", got, tt.want)
+			}
+
+			for id, trx := range repo.trxs {
+				reason, wantExpired := tt.wantExpired[id]
+				if (trx.Status == expired) != wantExpired {
+					t.Errorf("transaction %v status = %v, want expired %v", id, trx.Status, wantExpired)
+					continue
+				}
+				if wantExpired && (trx.ExpiredReason != reason || !trx.ExpiredAt.Equal(timeNow)) {
+					t.Errorf("transaction %v expired = %v %q, want %v %q", id, trx.ExpiredAt, trx.ExpiredReason, timeNow, reason)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package transaction
+
+import (
+	"rekber/ierr"
+)
+
+const (
+	maxItems        = 20
+	maxItemQuantity = 1000
+
+	// escrowFeeBasisPoints is the escrow fee charged to seller, 100 basis points equal to 1%.
+	escrowFeeBasisPoints = 100
+)
+
+var (
+	minEscrowFee = NewMoney(5_000_00, IDR)       // IDR 5.000
+	maxItemTotal = NewMoney(100_000_000_00, IDR) // IDR 100.000.000
+)
+
+type Item struct {
+	Name        string
+	Description string
+	Quantity    int64
+	Price       Money
+}
+
+func (i Item) Total() Money {
+	return i.Price.Multiply(i.Quantity)
+}
+
+func (i Item) validate() error {
+	if i.Name == "" {
+		return ierr.TransactionItemNotValid{Name: i.Name, Reason: "name is required"}
+	}
+
+	if i.Quantity <= 0 {
+		return ierr.TransactionItemNotValid{Name: i.Name, Reason: "quantity should be greater than zero"}
+	}
+
+	if i.Quantity > maxItemQuantity {
+		return ierr.TransactionItemNotValid{Name: i.Name, Reason: "quantity exceeds the maximum limit"}
+	}
+
+	if !i.Price.Currency.IsSupported() {
+		return ierr.CurrencyNotSupported{Currency: string(i.Price.Currency)}
+	}
+
+	if i.Price.IsNegative() || i.Price.IsZero() {
+		return ierr.TransactionItemNotValid{Name: i.Name, Reason: "price should be greater than zero"}
+	}
+
+	if i.Price.Amount > maxItemTotal.Amount {
+		return ierr.TransactionItemNotValid{Name: i.Name, Reason: "price exceeds the maximum limit"}
+	}
+
+	return nil
+}
+
+// Breakdown is the money breakdown of a transaction. Buyer pays ItemTotal,
+// the escrow fee is deducted from it and the rest is paid out to seller.
+type Breakdown struct {
+	ItemTotal Money
+	EscrowFee Money
+	SellerNet Money
+}
+
+func calculateBreakdown(items []Item) (Breakdown, error) {
+	if len(items) == 0 {
+		return Breakdown{}, ierr.TransactionAmountNotValid{Reason: "transaction should have at least one item"}
+	}
+
+	if len(items) > maxItems {
+		return Breakdown{}, ierr.TransactionAmountNotValid{Reason: "transaction items exceed the maximum limit"}
+	}
+
+	itemTotal := NewMoney(0, items[0].Price.Currency)
+	for _, item := range items {
+		if err := item.validate(); err != nil {
+			return Breakdown{}, err
+		}
+
+		total, err := itemTotal.Add(item.Total())
+		if err != nil {
+			return Breakdown{}, err
+		}
+
+		// checked on every item so the running total can never overflow
+		if total.Amount > maxItemTotal.Amount {
+			return Breakdown{}, ierr.TransactionAmountNotValid{Reason: "item total exceeds the maximum limit of " + maxItemTotal.String()}
+		}
+
+		itemTotal = total
+	}
+
+	escrowFee := NewMoney(itemTotal.Amount*escrowFeeBasisPoints/10_000, itemTotal.Currency)
+	if escrowFee.Amount < minEscrowFee.Amount {
+		escrowFee = NewMoney(minEscrowFee.Amount, itemTotal.Currency)
+	}
+
+	sellerNet, err := itemTotal.Sub(escrowFee)
+	if err != nil {
+		return Breakdown{}, err
+	}
+
+	if sellerNet.IsNegative() || sellerNet.IsZero() {
+		return Breakdown{}, ierr.TransactionAmountNotValid{Reason: "item total should be greater than the escrow fee of " + escrowFee.String()}
+	}
+
+	return Breakdown{
+		ItemTotal: itemTotal,
+		EscrowFee: escrowFee,
+		SellerNet: sellerNet,
+	}, nil
+}
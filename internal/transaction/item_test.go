@@ -0,0 +1,158 @@
+package transaction
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_calculateBreakdown(t *testing.T) {
+	tooManyItems := make([]Item, maxItems+1)
+	for i := range tooManyItems {
+		tooManyItems[i] = Item{Name: "item", Quantity: 1, Price: NewMoney(100_000_00, IDR)}
+	}
+
+	type args struct {
+		items []Item
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    Breakdown
+		wantErr bool
+	}{
+		{
+			name: "percentage fee is used when it is above the minimum fee",
+			args: args{
+				items: []Item{
+					{Name: "laptop", Quantity: 1, Price: NewMoney(10_000_000_00, IDR)},
+					{Name: "mouse", Quantity: 2, Price: NewMoney(250_000_00, IDR)},
+				},
+			},
+			want: Breakdown{
+				ItemTotal: NewMoney(10_500_000_00, IDR),
+				EscrowFee: NewMoney(105_000_00, IDR),
+				SellerNet: NewMoney(10_395_000_00, IDR),
+			},
+			wantErr: false,
+		},
+		{
+			name: "minimum fee is used when percentage fee is below it",
+			args: args{
+				items: []Item{
+					{Name: "book", Quantity: 1, Price: NewMoney(100_000_00, IDR)},
+				},
+			},
+			want: Breakdown{
+				ItemTotal: NewMoney(100_000_00, IDR),
+				EscrowFee: NewMoney(5_000_00, IDR),
+				SellerNet: NewMoney(95_000_00, IDR),
+			},
+			wantErr: false,
+		},
+		{
+			name:    "no item",
+			args:    args{},
+			want:    Breakdown{},
+			wantErr: true,
+		},
+		{
+			name:    "too many items",
+			args:    args{items: tooManyItems},
+			want:    Breakdown{},
+			wantErr: true,
+		},
+		{
+			name: "item without name",
+			args: args{
+				items: []Item{
+					{Quantity: 1, Price: NewMoney(100_000_00, IDR)},
+				},
+			},
+			want:    Breakdown{},
+			wantErr: true,
+		},
+		{
+			name: "item quantity is zero",
+			args: args{
+				items: []Item{
+					{Name: "book", Price: NewMoney(100_000_00, IDR)},
+				},
+			},
+			want:    Breakdown{},
+			wantErr: true,
+		},
+		{
+			name: "item quantity exceeds the limit",
+			args: args{
+				items: []Item{
+					{Name: "book", Quantity: maxItemQuantity + 1, Price: NewMoney(100_000_00, IDR)},
+				},
+			},
+			want:    Breakdown{},
+			wantErr: true,
+		},
+		{
+			name: "item price is negative",
+			args: args{
+				items: []Item{
+					{Name: "book", Quantity: 1, Price: NewMoney(-100_000_00, IDR)},
+				},
+			},
+			want:    Breakdown{},
+			wantErr: true,
+		},
+		{
+			name: "item price is zero",
+			args: args{
+				items: []Item{
+					{Name: "book", Quantity: 1, Price: NewMoney(0, IDR)},
+				},
+			},
+			want:    Breakdown{},
+			wantErr: true,
+		},
+		{
+			name: "currency is not supported",
+			args: args{
+				items: []Item{
+					{Name: "book", Quantity: 1, Price: NewMoney(100_00, "USD")},
+				},
+			},
+			want:    Breakdown{},
+			wantErr: true,
+		},
+		{
+			name: "item total exceeds the maximum limit",
+			args: args{
+				items: []Item{
+					{Name: "car", Quantity: 1, Price: NewMoney(60_000_000_00, IDR)},
+					{Name: "motorcycle", Quantity: 2, Price: NewMoney(25_000_000_00, IDR)},
+				},
+			},
+			want:    Breakdown{},
+			wantErr: true,
+		},
+		{
+			name: "item total is not greater than the minimum fee",
+			args: args{
+				items: []Item{
+					{Name: "sticker", Quantity: 1, Price: NewMoney(5_000_00, IDR)},
+				},
+			},
+			want:    Breakdown{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := calculateBreakdown(tt.args.items)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("calculateBreakdown() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("calculateBreakdown() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
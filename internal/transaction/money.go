@@ -0,0 +1,95 @@
+package transaction
+
+import (
+	"fmt"
+	"rekber/ierr"
+)
+
+type Currency string
+
+const (
+	IDR Currency = "IDR"
+)
+
+// exponent is the number of minor units digits of the currency, e.g. IDR
+// is stored in sen (1/100 rupiah).
+func (c Currency) exponent() int {
+	switch c {
+	case IDR:
+		return 2
+	default:
+		return 0
+	}
+}
+
+func (c Currency) IsSupported() bool {
+	switch c {
+	case IDR:
+		return true
+	default:
+		return false
+	}
+}
+
+// Money is an amount in the minor unit of its currency, kept as integer to
+// avoid floating point rounding on money calculation.
+type Money struct {
+	Amount   int64
+	Currency Currency
+}
+
+func NewMoney(amount int64, currency Currency) Money {
+	return Money{
+		Amount:   amount,
+		Currency: currency,
+	}
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, ierr.CurrencyMismatch{Expected: string(m.Currency), Actual: string(o.Currency)}
+	}
+
+	return NewMoney(m.Amount+o.Amount, m.Currency), nil
+}
+
+func (m Money) Sub(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, ierr.CurrencyMismatch{Expected: string(m.Currency), Actual: string(o.Currency)}
+	}
+
+	return NewMoney(m.Amount-o.Amount, m.Currency), nil
+}
+
+func (m Money) Multiply(n int64) Money {
+	return NewMoney(m.Amount*n, m.Currency)
+}
+
+func (m Money) String() string {
+	exp := m.Currency.exponent()
+	if exp == 0 {
+		return fmt.Sprintf("%s %d", m.Currency, m.Amount)
+	}
+
+	unit := int64(1)
+	for i := 0; i < exp; i++ {
+		unit *= 10
+	}
+
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	return fmt.Sprintf("%s %s%d.%0*d", m.Currency, sign, amount/unit, exp, amount%unit)
+}
@@ -0,0 +1,78 @@
+package transaction
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMoney_Add(t *testing.T) {
+	tests := []struct {
+		name    string
+		m       Money
+		o       Money
+		want    Money
+		wantErr bool
+	}{
+		{
+			name: "same currency",
+			m:    NewMoney(1_000_00, IDR),
+			o:    NewMoney(2_500_50, IDR),
+			want: NewMoney(3_500_50, IDR),
+		},
+		{
+			name:    "different currency",
+			m:       NewMoney(1_000_00, IDR),
+			o:       NewMoney(1_00, "USD"),
+			want:    Money{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.m.Add(tt.o)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Money.Add() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Money.Add() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoney_String(t *testing.T) {
+	tests := []struct {
+		name string
+		m    Money
+		want string
+	}{
+		{
+			name: "whole rupiah",
+			m:    NewMoney(5_000_00, IDR),
+			want: "IDR 5000.00",
+		},
+		{
+			name: "with sen",
+			m:    NewMoney(1_05, IDR),
+			want: "IDR 1.05",
+		},
+		{
+			name: "negative amount",
+			m:    NewMoney(-1_05, IDR),
+			want: "IDR -1.05",
+		},
+		{
+			name: "unknown currency",
+			m:    NewMoney(100, "XXX"),
+			want: "XXX 100",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.m.String(); got != tt.want {
+				t.Errorf("Money.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
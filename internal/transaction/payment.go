@@ -0,0 +1,325 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"rekber/ierr"
+	"rekber/internal/ledger"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PaymentMethod string
+
+const (
+	VirtualAccount PaymentMethod = "virtual_account"
+	QRIS           PaymentMethod = "qris"
+)
+
+func (m PaymentMethod) IsValid() bool {
+	switch m {
+	case VirtualAccount, QRIS:
+		return true
+	default:
+		return false
+	}
+}
+
+type PaymentStatus string
+
+const (
+	paymentPending PaymentStatus = "pending"
+	paymentPaid    PaymentStatus = "paid"
+	// paymentExpired is a charge replaced by a newer one or left behind once the transaction is paid.
+	// The buyer may still pay it at the provider, so it can become paid later.
+	paymentExpired PaymentStatus = "expired"
+)
+
+// paymentRefundDue is sent to the buyer when a payment arrives for a transaction which can not be paid
+// anymore, e.g. it is expired or paid by another charge.
+const paymentRefundDue NotificationKind = "payment_refund_due"
+
+// Payment is a charge created at the payment provider for the buyer to pay a transaction.
+type Payment struct {
+	ID            uuid.UUID
+	TransactionID uuid.UUID
+	Method        PaymentMethod
+	// BankCode is the bank of the virtual account, empty for QRIS.
+	BankCode string
+	// ExternalID is the id of the charge at the payment provider.
+	ExternalID           string
+	VirtualAccountNumber string
+	QRString             string
+	Amount               Money
+	Status               PaymentStatus
+	ExpiredAt            time.Time
+	PaidAt               time.Time
+	// RefundReason is set when the payment arrived but could not pay its transaction, the money has to
+	// be returned to the buyer by an admin.
+	RefundReason string
+	CreatedAt    time.Time
+}
+
+// Charge is what the payment provider returns for a created payment.
+type Charge struct {
+	ExternalID           string
+	VirtualAccountNumber string
+	QRString             string
+	ExpiredAt            time.Time
+}
+
+// PaymentCallback is the payment status pushed by the payment provider.
+type PaymentCallback struct {
+	ExternalID string
+	Amount     Money
+	Paid       bool
+	PaidAt     time.Time
+}
+
+type PaymentProvider interface {
+	// CreateCharge creates a virtual account or QRIS charge for the payment. The charge must not be
+	// payable after the expiry of the payment when it is set.
+	CreateCharge(ctx context.Context, p Payment) (Charge, error)
+	// ParseCallback verifies the signature of the callback body, it returns
+	// ierr.PaymentSignatureNotValid when the callback is not sent by the provider.
+	ParseCallback(body []byte, signature string) (PaymentCallback, error)
+	// Refund returns the refund amount from its charge to the buyer and returns the id of the refund
+	// at the provider. The refund id is sent as idempotency key so a retry never refunds twice.
+	Refund(ctx context.Context, r Refund) (string, error)
+}
+
+type PaymentRepository interface {
+	SavePayment(ctx context.Context, p Payment) error
+	// GetPendingPayment returns the latest pending payment of the transaction which is not expired
+	// yet, or ierr.PaymentNotFound.
+	GetPendingPayment(ctx context.Context, transactionID uuid.UUID) (Payment, error)
+	GetPaymentByExternalID(ctx context.Context, externalID string) (Payment, error)
+	// GetPaidPayment returns the payment which paid the transaction, or ierr.PaymentNotFound.
+	GetPaidPayment(ctx context.Context, transactionID uuid.UUID) (Payment, error)
+	// MarkPaymentPaid marks a pending or expired payment paid, it returns false when the payment is paid
+	// already.
+	MarkPaymentPaid(ctx context.Context, id uuid.UUID, paidAt time.Time) (bool, error)
+	// ExpirePendingPayments expires every pending payment of the transaction except the given one.
+	ExpirePendingPayments(ctx context.Context, transactionID, except uuid.UUID) error
+	// FlagPaymentRefund records why a paid payment has to be refunded to the buyer.
+	FlagPaymentRefund(ctx context.Context, id uuid.UUID, reason string) error
+	// ListRefundDuePayments returns the payments flagged for refund whose refund has not succeeded
+	// yet, oldest first.
+	ListRefundDuePayments(ctx context.Context) ([]Payment, error)
+}
+
+// Transactor runs fn atomically, repositories called with the context given to fn take part in it.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Pay creates a charge at the payment provider for the buyer to pay the transaction. A pending charge
+// with the same method is returned again instead of creating another one, a new charge expires the
+// older ones and never outlives the payment deadline of the transaction.
+func (s Service) Pay(ctx context.Context, userID, id uuid.UUID, req PayRequest) (PaymentResponse, error) {
+	method := PaymentMethod(req.Method)
+	if !method.IsValid() {
+		return PaymentResponse{}, ierr.PaymentMethodNotValid{Method: req.Method}
+	}
+
+	t, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return PaymentResponse{}, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	c, err := s.resolve(ctx, userID, t)
+	if err != nil {
+		return PaymentResponse{}, err
+	}
+
+	if c.actor != buyer {
+		return PaymentResponse{}, ierr.TransactionActionNotAllowed{Action: pay.String(), Actor: c.actor.String()}
+	}
+
+	if err := buyerIsEligible(t, c); err != nil {
+		return PaymentResponse{}, err
+	}
+
+	if t.Status != waitingForPayment {
+		return PaymentResponse{}, ierr.TransactionStatusNotValid{LastStatus: t.Status.String(), NewStatus: paid.String()}
+	}
+
+	pending, err := s.paymentRepository.GetPendingPayment(ctx, t.ID)
+	if err != nil && !errors.As(err, &ierr.PaymentNotFound{}) {
+		return PaymentResponse{}, fmt.Errorf("failed to get pending payment: %w", err)
+	}
+
+	if err == nil && pending.Method == method && pending.BankCode == req.BankCode {
+		return newPaymentResponse(pending), nil
+	}
+
+	deadline := s.paymentDeadline(t)
+	now := time.Now()
+	if !deadline.IsZero() && !deadline.After(now) {
+		return PaymentResponse{}, ierr.PaymentDeadlinePassed{TransactionID: t.ID, Deadline: deadline}
+	}
+
+	p := Payment{
+		ID:            uuid.New(),
+		TransactionID: t.ID,
+		Method:        method,
+		BankCode:      req.BankCode,
+		// escrow fee is deducted from the seller, buyer pays the item total
+		Amount:    t.Breakdown.ItemTotal,
+		Status:    paymentPending,
+		ExpiredAt: deadline,
+		CreatedAt: now,
+	}
+
+	charge, err := s.paymentProvider.CreateCharge(ctx, p)
+	if err != nil {
+		return PaymentResponse{}, fmt.Errorf("failed to create charge: %w", err)
+	}
+
+	p.ExternalID = charge.ExternalID
+	p.VirtualAccountNumber = charge.VirtualAccountNumber
+	p.QRString = charge.QRString
+	if deadline.IsZero() || charge.ExpiredAt.Before(deadline) {
+		p.ExpiredAt = charge.ExpiredAt
+	}
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.paymentRepository.SavePayment(ctx, p); err != nil {
+			return fmt.Errorf("failed to save payment: %w", err)
+		}
+
+		// the buyer should pay only the newest charge, paying an older one anyway is still recorded
+		if err := s.paymentRepository.ExpirePendingPayments(ctx, t.ID, p.ID); err != nil {
+			return fmt.Errorf("failed to expire pending payments: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return PaymentResponse{}, err
+	}
+
+	return newPaymentResponse(p), nil
+}
+
+// paymentDeadline returns when the transaction is expired for not being paid, or zero when the
+// payment window is not set.
+func (s Service) paymentDeadline(t Transaction) time.Time {
+	if s.deadlines.Payment <= 0 {
+		return time.Time{}
+	}
+
+	return t.AcceptedAt.Add(s.deadlines.Payment)
+}
+
+// HandlePaymentCallback marks the payment and its transaction paid when the payment provider confirms
+// it. The provider may send the same callback more than once, only the first one takes effect. A
+// payment which can not pay its transaction anymore is still recorded as paid and flagged for refund,
+// the money has arrived at the provider either way.
+func (s Service) HandlePaymentCallback(ctx context.Context, body []byte, signature string) error {
+	cb, err := s.paymentProvider.ParseCallback(body, signature)
+	if err != nil {
+		return fmt.Errorf("failed to parse payment callback: %w", err)
+	}
+
+	if !cb.Paid {
+		return nil
+	}
+
+	p, err := s.paymentRepository.GetPaymentByExternalID(ctx, cb.ExternalID)
+	if err != nil {
+		return fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	if cb.Amount != p.Amount {
+		return ierr.PaymentAmountMismatch{ExternalID: cb.ExternalID, Expected: p.Amount.Amount, Actual: cb.Amount.Amount}
+	}
+
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// the row lock taken here serializes duplicate callbacks, the later ones see it paid already
+		marked, err := s.paymentRepository.MarkPaymentPaid(ctx, p.ID, cb.PaidAt)
+		if err != nil {
+			return fmt.Errorf("failed to mark payment paid: %w", err)
+		}
+
+		if !marked {
+			return nil
+		}
+
+		t, err := s.repository.GetByID(ctx, p.TransactionID)
+		if err != nil {
+			return fmt.Errorf("failed to get transaction: %w", err)
+		}
+
+		if t.Status != waitingForPayment {
+			return s.flagPaymentRefund(ctx, t, p)
+		}
+
+		if _, err := s.transition(ctx, t, pay, System{}.command()); err != nil {
+			return err
+		}
+
+		if err := s.ledger.Post(ctx, ledger.PaidEntry(t.ID, string(p.Amount.Currency), p.Amount.Amount)); err != nil {
+			return fmt.Errorf("failed to post paid entry: %w", err)
+		}
+
+		if err := s.paymentRepository.ExpirePendingPayments(ctx, t.ID, p.ID); err != nil {
+			return fmt.Errorf("failed to expire pending payments: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// flagPaymentRefund flags a payment which arrived after its transaction left waiting for payment,
+// creates its refund and tells the buyer the money is going to be returned. The payment is kept out
+// of the ledger of the transaction since it never went into escrow.
+func (s Service) flagPaymentRefund(ctx context.Context, t Transaction, p Payment) error {
+	reason := fmt.Sprintf("transaction is %s when the payment arrived", t.Status)
+	if err := s.paymentRepository.FlagPaymentRefund(ctx, p.ID, reason); err != nil {
+		return fmt.Errorf("failed to flag payment refund: %w", err)
+	}
+
+	if err := s.refundRepository.SaveRefund(ctx, newLatePaymentRefund(t, p)); err != nil {
+		return fmt.Errorf("failed to save refund: %w", err)
+	}
+
+	n := Notification{
+		Kind:          paymentRefundDue,
+		UserID:        t.Buyer.ID,
+		TransactionID: t.ID,
+		Message:       "your payment arrived after the transaction could be paid, it will be refunded",
+	}
+	if err := s.notifier.Notify(ctx, n); err != nil {
+		return fmt.Errorf("failed to notify payment refund: %w", err)
+	}
+
+	return nil
+}
+
+// ListRefundDuePayments returns the payments flagged for refund which are not refunded yet to an
+// admin, a payment whose refund failed stays listed for the admin to follow up.
+func (s Service) ListRefundDuePayments(ctx context.Context, userID uuid.UUID) ([]PaymentResponse, error) {
+	caller, err := s.userRepository.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get caller by id: %w", err)
+	}
+
+	if !caller.IsAdmin {
+		return nil, ierr.UserForbiddenAccess{PhoneNumber: caller.PhoneNumber}
+	}
+
+	payments, err := s.paymentRepository.ListRefundDuePayments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refund due payments: %w", err)
+	}
+
+	resp := make([]PaymentResponse, 0, len(payments))
+	for _, p := range payments {
+		resp = append(resp, newPaymentResponse(p))
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,410 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"rekber/ierr"
+	"rekber/internal/ledger"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/google/uuid"
+)
+
+type fakeRepository struct {
+	Repository
+	trxs map[uuid.UUID]Transaction
+}
+
+func (f *fakeRepository) GetByID(ctx context.Context, id uuid.UUID) (Transaction, error) {
+	t, ok := f.trxs[id]
+	if !ok {
+		return Transaction{}, ierr.TransactionNotFound{ID: id}
+	}
+
+	return t, nil
+}
+
+func (f *fakeRepository) Update(ctx context.Context, t Transaction, lastStatus Status) error {
+	if f.trxs[t.ID].Status != lastStatus {
+		return ierr.TransactionUpdateConflict{ID: t.ID}
+	}
+
+	f.trxs[t.ID] = t
+	return nil
+}
+
+type fakePaymentRepository struct {
+	PaymentRepository
+	payments map[string]Payment
+}
+
+func (f *fakePaymentRepository) GetPaymentByExternalID(ctx context.Context, externalID string) (Payment, error) {
+	p, ok := f.payments[externalID]
+	if !ok {
+		return Payment{}, ierr.PaymentNotFound{ExternalID: externalID}
+	}
+
+	return p, nil
+}
+
+func (f *fakePaymentRepository) GetPaidPayment(ctx context.Context, transactionID uuid.UUID) (Payment, error) {
+	for _, p := range f.payments {
+		if p.TransactionID == transactionID && p.Status == paymentPaid && p.RefundReason == "" {
+			return p, nil
+		}
+	}
+
+	return Payment{}, ierr.PaymentNotFound{TransactionID: transactionID}
+}
+
+func (f *fakePaymentRepository) MarkPaymentPaid(ctx context.Context, id uuid.UUID, paidAt time.Time) (bool, error) {
+	for externalID, p := range f.payments {
+		if p.ID == id && (p.Status == paymentPending || p.Status == paymentExpired) {
+			p.Status = paymentPaid
+			p.PaidAt = paidAt
+			f.payments[externalID] = p
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (f *fakePaymentRepository) GetPendingPayment(ctx context.Context, transactionID uuid.UUID) (Payment, error) {
+	for _, p := range f.payments {
+		if p.TransactionID == transactionID && p.Status == paymentPending {
+			return p, nil
+		}
+	}
+
+	return Payment{}, ierr.PaymentNotFound{TransactionID: transactionID}
+}
+
+func (f *fakePaymentRepository) SavePayment(ctx context.Context, p Payment) error {
+	f.payments[p.ExternalID] = p
+	return nil
+}
+
+func (f *fakePaymentRepository) ExpirePendingPayments(ctx context.Context, transactionID, except uuid.UUID) error {
+	for externalID, p := range f.payments {
+		if p.TransactionID == transactionID && p.ID != except && p.Status == paymentPending {
+			p.Status = paymentExpired
+			f.payments[externalID] = p
+		}
+	}
+
+	return nil
+}
+
+func (f *fakePaymentRepository) FlagPaymentRefund(ctx context.Context, id uuid.UUID, reason string) error {
+	for externalID, p := range f.payments {
+		if p.ID == id {
+			p.RefundReason = reason
+			f.payments[externalID] = p
+		}
+	}
+
+	return nil
+}
+
+type fakePaymentProvider struct {
+	PaymentProvider
+	callback PaymentCallback
+	charge   Charge
+}
+
+func (f fakePaymentProvider) CreateCharge(ctx context.Context, p Payment) (Charge, error) {
+	return f.charge, nil
+}
+
+func (f fakePaymentProvider) ParseCallback(body []byte, signature string) (PaymentCallback, error) {
+	if signature != "valid" {
+		return PaymentCallback{}, ierr.PaymentSignatureNotValid{}
+	}
+
+	return f.callback, nil
+}
+
+type fakeLedger struct {
+	Ledger
+	entries []ledger.Entry
+}
+
+func (f *fakeLedger) Post(ctx context.Context, e ledger.Entry) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+
+	f.entries = append(f.entries, e)
+	return nil
+}
+
+type fakeTransactor struct{}
+
+func (fakeTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestService_HandlePaymentCallback(t *testing.T) {
+	trxID := uuid.New()
+	amount := NewMoney(150_000_00, IDR)
+	payment := Payment{
+		ID:            uuid.New(),
+		TransactionID: trxID,
+		ExternalID:    "charge-1",
+		Amount:        amount,
+		Status:        paymentPending,
+	}
+	other := Payment{
+		ID:            uuid.New(),
+		TransactionID: trxID,
+		ExternalID:    "charge-2",
+		Amount:        amount,
+		Status:        paymentPending,
+	}
+
+	tests := []struct {
+		name             string
+		trxStatus        Status
+		callbacks        []PaymentCallback
+		signatures       []string
+		wantStatus       Status
+		wantPosted       int
+		wantPaymentState map[string]PaymentStatus
+		wantRefundDue    []string
+		wantNotified     int
+		wantErr          error
+	}{
+		{
+			name:             "paid callback pays the transaction and expires the other charges",
+			trxStatus:        waitingForPayment,
+			callbacks:        []PaymentCallback{{ExternalID: "charge-1", Amount: amount, Paid: true}},
+			signatures:       []string{"valid"},
+			wantStatus:       paid,
+			wantPosted:       1,
+			wantPaymentState: map[string]PaymentStatus{"charge-1": paymentPaid, "charge-2": paymentExpired},
+		},
+		{
+			name:      "duplicate paid callback is ignored",
+			trxStatus: waitingForPayment,
+			callbacks: []PaymentCallback{
+				{ExternalID: "charge-1", Amount: amount, Paid: true},
+				{ExternalID: "charge-1", Amount: amount, Paid: true},
+			},
+			signatures:       []string{"valid", "valid"},
+			wantStatus:       paid,
+			wantPosted:       1,
+			wantPaymentState: map[string]PaymentStatus{"charge-1": paymentPaid, "charge-2": paymentExpired},
+		},
+		{
+			name:      "second charge paid after the transaction is paid is flagged for refund",
+			trxStatus: waitingForPayment,
+			callbacks: []PaymentCallback{
+				{ExternalID: "charge-1", Amount: amount, Paid: true},
+				{ExternalID: "charge-2", Amount: amount, Paid: true},
+			},
+			signatures:       []string{"valid", "valid"},
+			wantStatus:       paid,
+			wantPosted:       1,
+			wantPaymentState: map[string]PaymentStatus{"charge-1": paymentPaid, "charge-2": paymentPaid},
+			wantRefundDue:    []string{"charge-2"},
+			wantNotified:     1,
+		},
+		{
+			name:             "payment of an expired transaction is recorded and flagged for refund",
+			trxStatus:        expired,
+			callbacks:        []PaymentCallback{{ExternalID: "charge-1", Amount: amount, Paid: true}},
+			signatures:       []string{"valid"},
+			wantStatus:       expired,
+			wantPaymentState: map[string]PaymentStatus{"charge-1": paymentPaid, "charge-2": paymentPending},
+			wantRefundDue:    []string{"charge-1"},
+			wantNotified:     1,
+		},
+		{
+			name:             "callback with invalid signature is rejected",
+			trxStatus:        waitingForPayment,
+			callbacks:        []PaymentCallback{{ExternalID: "charge-1", Amount: amount, Paid: true}},
+			signatures:       []string{"forged"},
+			wantStatus:       waitingForPayment,
+			wantPaymentState: map[string]PaymentStatus{"charge-1": paymentPending, "charge-2": paymentPending},
+			wantErr:          ierr.PaymentSignatureNotValid{},
+		},
+		{
+			name:             "callback with different amount is rejected",
+			trxStatus:        waitingForPayment,
+			callbacks:        []PaymentCallback{{ExternalID: "charge-1", Amount: NewMoney(1_00, IDR), Paid: true}},
+			signatures:       []string{"valid"},
+			wantStatus:       waitingForPayment,
+			wantPaymentState: map[string]PaymentStatus{"charge-1": paymentPending, "charge-2": paymentPending},
+			wantErr:          ierr.PaymentAmountMismatch{ExternalID: "charge-1", Expected: amount.Amount, Actual: 1_00},
+		},
+		{
+			name:             "unpaid callback does nothing",
+			trxStatus:        waitingForPayment,
+			callbacks:        []PaymentCallback{{ExternalID: "charge-1", Amount: amount}},
+			signatures:       []string{"valid"},
+			wantStatus:       waitingForPayment,
+			wantPaymentState: map[string]PaymentStatus{"charge-1": paymentPending, "charge-2": paymentPending},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepository{trxs: map[uuid.UUID]Transaction{trxID: {ID: trxID, Status: tt.trxStatus}}}
+			payments := &fakePaymentRepository{payments: map[string]Payment{payment.ExternalID: payment, other.ExternalID: other}}
+			l := &fakeLedger{}
+			n := &fakeNotifier{}
+			refunds := &fakeRefundRepository{}
+			s := Service{
+				ledger:             l,
+				repository:         repo,
+				paymentRepository:  payments,
+				refundRepository:   refunds,
+				transactor:         fakeTransactor{},
+				notifier:           n,
+				timelineRepository: &fakeTimelineRepository{},
+				eventStore:         &fakeEventStore{},
+				outbox:             &fakeOutbox{},
+			}
+
+			var err error
+			for i, signature := range tt.signatures {
+				s.paymentProvider = fakePaymentProvider{callback: tt.callbacks[i]}
+				if err = s.HandlePaymentCallback(context.Background(), nil, signature); err != nil {
+					break
+				}
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Service.HandlePaymentCallback() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := repo.trxs[trxID].Status; got != tt.wantStatus {
+				t.Errorf("Service.HandlePaymentCallback() status = %v, want %v", got, tt.wantStatus)
+			}
+			if got := len(l.entries); got != tt.wantPosted {
+				t.Errorf("Service.HandlePaymentCallback() posted entries = %v, want %v", got, tt.wantPosted)
+			}
+			for externalID, want := range tt.wantPaymentState {
+				if got := payments.payments[externalID].Status; got != want {
+					t.Errorf("Service.HandlePaymentCallback() payment %s status = %v, want %v", externalID, got, want)
+				}
+			}
+			var refundDue []string
+			for _, externalID := range []string{payment.ExternalID, other.ExternalID} {
+				if payments.payments[externalID].RefundReason != "" {
+					refundDue = append(refundDue, externalID)
+				}
+			}
+			if !reflect.DeepEqual(refundDue, tt.wantRefundDue) {
+				t.Errorf("Service.HandlePaymentCallback() refund due = %v, want %v", refundDue, tt.wantRefundDue)
+			}
+			var refunded []string
+			for _, r := range refunds.refunds {
+				if !r.LatePayment || r.Amount != amount || r.Status != retryPending {
+					t.Errorf("Service.HandlePaymentCallback() refund = %+v, want pending late payment refund of %v", r, amount)
+				}
+				refunded = append(refunded, r.PaymentExternalID)
+			}
+			if !reflect.DeepEqual(refunded, tt.wantRefundDue) {
+				t.Errorf("Service.HandlePaymentCallback() refunded = %v, want %v", refunded, tt.wantRefundDue)
+			}
+			if got := len(n.notifications); got != tt.wantNotified {
+				t.Errorf("Service.HandlePaymentCallback() notifications = %v, want %v", got, tt.wantNotified)
+			}
+		})
+	}
+}
+
+func TestService_Pay(t *testing.T) {
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time {
+		return now
+	})
+	defer patches.Reset()
+
+	buyerID := uuid.New()
+	trxID := uuid.New()
+	older := Payment{
+		ID:            uuid.New(),
+		TransactionID: trxID,
+		Method:        QRIS,
+		ExternalID:    "charge-1",
+		Status:        paymentPending,
+		ExpiredAt:     now.Add(time.Hour),
+	}
+
+	tests := []struct {
+		name          string
+		acceptedAt    time.Time
+		paymentWindow time.Duration
+		charge        Charge
+		wantExpiredAt time.Time
+		wantOlder     PaymentStatus
+		wantErr       error
+	}{
+		{
+			name:          "charge expiring before the deadline is kept and expires the older charge",
+			acceptedAt:    now.Add(-time.Hour),
+			paymentWindow: 48 * time.Hour,
+			charge:        Charge{ExternalID: "charge-2", ExpiredAt: now.Add(24 * time.Hour)},
+			wantExpiredAt: now.Add(24 * time.Hour),
+			wantOlder:     paymentExpired,
+		},
+		{
+			name:          "charge outliving the deadline is capped at it",
+			acceptedAt:    now.Add(-40 * time.Hour),
+			paymentWindow: 48 * time.Hour,
+			charge:        Charge{ExternalID: "charge-2", ExpiredAt: now.Add(24 * time.Hour)},
+			wantExpiredAt: now.Add(8 * time.Hour),
+			wantOlder:     paymentExpired,
+		},
+		{
+			name:          "charge is not capped without a payment window",
+			acceptedAt:    now.Add(-40 * time.Hour),
+			charge:        Charge{ExternalID: "charge-2", ExpiredAt: now.Add(24 * time.Hour)},
+			wantExpiredAt: now.Add(24 * time.Hour),
+			wantOlder:     paymentExpired,
+		},
+		{
+			name:          "payment after the deadline is rejected",
+			acceptedAt:    now.Add(-48 * time.Hour),
+			paymentWindow: 48 * time.Hour,
+			charge:        Charge{ExternalID: "charge-2", ExpiredAt: now.Add(24 * time.Hour)},
+			wantOlder:     paymentPending,
+			wantErr:       ierr.PaymentDeadlinePassed{TransactionID: trxID, Deadline: now},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trx := Transaction{
+				ID:         trxID,
+				Buyer:      Buyer{ID: buyerID},
+				Status:     waitingForPayment,
+				AcceptedAt: tt.acceptedAt,
+				Breakdown:  Breakdown{ItemTotal: NewMoney(150_000_00, IDR)},
+			}
+			payments := &fakePaymentRepository{payments: map[string]Payment{older.ExternalID: older}}
+			s := Service{
+				repository:        &fakeRepository{trxs: map[uuid.UUID]Transaction{trxID: trx}},
+				userRepository:    fakeUserRepository{verified: map[uuid.UUID]time.Time{buyerID: now}},
+				paymentRepository: payments,
+				paymentProvider:   fakePaymentProvider{charge: tt.charge},
+				transactor:        fakeTransactor{},
+				deadlines:         Deadlines{Payment: tt.paymentWindow},
+			}
+
+			got, err := s.Pay(context.Background(), buyerID, trxID, PayRequest{Method: string(VirtualAccount), BankCode: "bca"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Service.Pay() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !got.ExpiredAt.Equal(tt.wantExpiredAt) {
+				t.Errorf("Service.Pay() expired at = %v, want %v", got.ExpiredAt, tt.wantExpiredAt)
+			}
+			if got := payments.payments[older.ExternalID].Status; got != tt.wantOlder {
+				t.Errorf("Service.Pay() older payment status = %v, want %v", got, tt.wantOlder)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"rekber/ierr"
+	"rekber/internal/user"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Payout moves the seller net amount of a successful transaction to the seller bank account.
+// The bank account is copied when the payout is created so later changes by the seller do not
+// redirect money which is already on its way. A payout created while the seller has no verified
+// bank account has none and waits for one.
+type Payout struct {
+	ID            uuid.UUID
+	TransactionID uuid.UUID
+	SellerID      uuid.UUID
+	BankAccountID uuid.UUID
+	BankCode      string
+	AccountNumber string
+	AccountName   string
+	Amount        Money
+	// Retry is the transfer at the disbursement provider.
+	Retry
+	CreatedAt time.Time
+}
+
+func newPayout(t Transaction, b user.BankAccount) Payout {
+	now := time.Now()
+	p := Payout{
+		ID:            uuid.New(),
+		TransactionID: t.ID,
+		SellerID:      t.Seller.ID,
+		Amount:        t.sellerAmount(),
+		Retry:         newRetry(now),
+		CreatedAt:     now,
+	}
+
+	if b.IsVerified() {
+		p = p.withBankAccount(b)
+	}
+
+	return p
+}
+
+func (p Payout) withBankAccount(b user.BankAccount) Payout {
+	p.BankAccountID = b.ID
+	p.BankCode = b.Bank.Code
+	p.AccountNumber = b.Number
+	p.AccountName = b.Name
+
+	return p
+}
+
+type Disburser interface {
+	// Disburse transfers the payout amount to its bank account and returns the id of the transfer at
+	// the provider. The payout id is sent as idempotency key so a retry never transfers twice.
+	Disburse(ctx context.Context, p Payout) (string, error)
+}
+
+type PayoutRepository interface {
+	SavePayout(ctx context.Context, p Payout) error
+	// GetPayout returns the payout of the transaction, or ierr.PayoutNotFound.
+	GetPayout(ctx context.Context, transactionID uuid.UUID) (Payout, error)
+	ListPayouts(ctx context.Context, transactionIDs []uuid.UUID) ([]Payout, error)
+	// LockDuePayout locks a pending payout whose next attempt is due, skipping payouts locked by other
+	// workers. It returns ierr.PayoutNotFound when there is none, it must be called within a transaction.
+	LockDuePayout(ctx context.Context, now time.Time) (Payout, error)
+	UpdatePayout(ctx context.Context, p Payout) error
+}
+
+// createPayout creates the payout of a transaction which has just succeeded, to the seller primary
+// bank account when it is verified.
+func (s Service) createPayout(ctx context.Context, t Transaction) error {
+	u, err := s.userRepository.GetByID(ctx, t.Seller.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get seller by id: %w", err)
+	}
+
+	if err := s.payoutRepository.SavePayout(ctx, newPayout(t, u.BankAccount)); err != nil {
+		return fmt.Errorf("failed to save payout: %w", err)
+	}
+
+	return nil
+}
+
+// ProcessPayouts attempts every due payout once and returns how many were attempted. A failed
+// attempt is recorded on the payout and retried later, it is not returned as error.
+func (s Service) ProcessPayouts(ctx context.Context, maxAttempts int, backoff time.Duration) (int, error) {
+	return processDue(ctx, s.transactor, retryQueue[Payout]{
+		name:    "payout",
+		lockDue: s.payoutRepository.LockDuePayout,
+		isNone:  func(err error) bool { return errors.As(err, &ierr.PayoutNotFound{}) },
+		retry:   func(p *Payout) *Retry { return &p.Retry },
+		ready:   s.readyPayout,
+		attempt: s.disburser.Disburse,
+		update:  s.payoutRepository.UpdatePayout,
+	}, maxAttempts, backoff)
+}
+
+// readyPayout copies the seller primary bank account to a payout which has none yet. The payout waits
+// as long as the primary bank account is not verified, so money only goes to an account whose holder
+// name is confirmed by the bank.
+func (s Service) readyPayout(ctx context.Context, p *Payout) (string, error) {
+	if p.BankAccountID != uuid.Nil {
+		return "", nil
+	}
+
+	u, err := s.userRepository.GetByID(ctx, p.SellerID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get seller by id: %w", err)
+	}
+
+	if !u.BankAccount.IsVerified() {
+		return "waiting for a verified primary bank account", nil
+	}
+
+	*p = p.withBankAccount(u.BankAccount)
+	return "", nil
+}
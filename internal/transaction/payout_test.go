@@ -0,0 +1,172 @@
+package transaction
+
+import (
+	"context"
+	"rekber/ierr"
+	"rekber/internal/user"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/google/uuid"
+)
+
+func (f *fakePayoutRepository) LockDuePayout(ctx context.Context, now time.Time) (Payout, error) {
+	for _, p := range f.payouts {
+		if p.Status == retryPending && !p.NextAttemptAt.After(now) {
+			return p, nil
+		}
+	}
+
+	return Payout{}, ierr.PayoutNotFound{}
+}
+
+func (f *fakePayoutRepository) UpdatePayout(ctx context.Context, p Payout) error {
+	for i := range f.payouts {
+		if f.payouts[i].ID == p.ID {
+			f.payouts[i] = p
+		}
+	}
+
+	return nil
+}
+
+type fakeDisburser struct {
+	disbursed []Payout
+}
+
+func (f *fakeDisburser) Disburse(ctx context.Context, p Payout) (string, error) {
+	f.disbursed = append(f.disbursed, p)
+	return "transfer-" + p.ID.String(), nil
+}
+
+func Test_newPayout(t *testing.T) {
+	verified := user.BankAccount{ID: uuid.New(), Number: "1234567890", Name: "Seller", Bank: user.Bank{Code: "bca"}, VerifiedAt: time.Now()}
+	unverified := user.BankAccount{ID: uuid.New(), Number: "1234567890", Name: "Seller", Bank: user.Bank{Code: "bca"}}
+
+	tests := []struct {
+		name            string
+		bankAccount     user.BankAccount
+		wantBankAccount uuid.UUID
+	}{
+		{
+			name:            "verified bank account receives the payout",
+			bankAccount:     verified,
+			wantBankAccount: verified.ID,
+		},
+		{
+			name:        "unverified bank account is not copied",
+			bankAccount: unverified,
+		},
+		{
+			name: "seller without bank account",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newPayout(Transaction{ID: uuid.New(), Seller: Seller{ID: uuid.New()}}, tt.bankAccount)
+			if got.BankAccountID != tt.wantBankAccount {
+				t.Errorf("newPayout() bank account = %v, want %v", got.BankAccountID, tt.wantBankAccount)
+			}
+			if tt.wantBankAccount == uuid.Nil && (got.AccountNumber != "" || got.AccountName != "" || got.BankCode != "") {
+				t.Errorf("newPayout() copied the unverified bank account %v", got)
+			}
+			if got.Status != retryPending {
+				t.Errorf("newPayout() status = %v, want %v", got.Status, retryPending)
+			}
+		})
+	}
+}
+
+func TestService_ProcessPayouts(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time {
+		return now
+	})
+	defer patches.Reset()
+
+	sellerUUID := uuid.New()
+	verified := user.BankAccount{ID: uuid.New(), Number: "1234567890", Name: "Seller", Bank: user.Bank{Code: "bca"}, VerifiedAt: now}
+	unverified := user.BankAccount{ID: uuid.New(), Number: "9876543210", Name: "Seller", Bank: user.Bank{Code: "bni"}}
+	withAccount := Payout{ID: uuid.New(), SellerID: sellerUUID, Retry: newRetry(now)}.withBankAccount(verified)
+	waiting := Payout{ID: uuid.New(), SellerID: sellerUUID, Retry: newRetry(now)}
+
+	tests := []struct {
+		name            string
+		payout          Payout
+		bankAccount     user.BankAccount
+		wantProcessed   int
+		wantStatus      RetryStatus
+		wantAttempts    int
+		wantBankAccount uuid.UUID
+		wantNextAttempt time.Time
+	}{
+		{
+			name:            "payout with bank account is transferred",
+			payout:          withAccount,
+			bankAccount:     unverified,
+			wantProcessed:   1,
+			wantStatus:      retrySucceeded,
+			wantAttempts:    1,
+			wantBankAccount: verified.ID,
+			wantNextAttempt: now,
+		},
+		{
+			name:            "waiting payout is transferred once the primary bank account is verified",
+			payout:          waiting,
+			bankAccount:     verified,
+			wantProcessed:   1,
+			wantStatus:      retrySucceeded,
+			wantAttempts:    1,
+			wantBankAccount: verified.ID,
+			wantNextAttempt: now,
+		},
+		{
+			name:            "waiting payout keeps waiting for an unverified primary bank account",
+			payout:          waiting,
+			bankAccount:     unverified,
+			wantStatus:      retryPending,
+			wantNextAttempt: now.Add(time.Minute),
+		},
+		{
+			name:            "waiting payout keeps waiting for a seller without bank account",
+			payout:          waiting,
+			wantStatus:      retryPending,
+			wantNextAttempt: now.Add(time.Minute),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakePayoutRepository{payouts: []Payout{tt.payout}}
+			d := &fakeDisburser{}
+			s := Service{
+				userRepository:   fakeUserRepository{bankAccounts: map[uuid.UUID]user.BankAccount{sellerUUID: tt.bankAccount}},
+				payoutRepository: repo,
+				disburser:        d,
+				transactor:       fakeTransactor{},
+			}
+
+			got, err := s.ProcessPayouts(context.Background(), 5, time.Minute)
+			if err != nil {
+				t.Fatalf("Service.ProcessPayouts() error = %v", err)
+			}
+			if got != tt.wantProcessed {
+				t.Errorf("Service.ProcessPayouts() = %v, want %v", got, tt.wantProcessed)
+			}
+			if len(d.disbursed) != tt.wantProcessed {
+				t.Errorf("Service.ProcessPayouts() disbursed %v payouts, want %v", len(d.disbursed), tt.wantProcessed)
+			}
+
+			p := repo.payouts[0]
+			if p.Status != tt.wantStatus || p.Attempts != tt.wantAttempts {
+				t.Errorf("Service.ProcessPayouts() payout status = %v attempts = %v, want %v and %v", p.Status, p.Attempts, tt.wantStatus, tt.wantAttempts)
+			}
+			if p.BankAccountID != tt.wantBankAccount {
+				t.Errorf("Service.ProcessPayouts() payout bank account = %v, want %v", p.BankAccountID, tt.wantBankAccount)
+			}
+			if !p.NextAttemptAt.Equal(tt.wantNextAttempt) {
+				t.Errorf("Service.ProcessPayouts() payout next attempt = %v, want %v", p.NextAttemptAt, tt.wantNextAttempt)
+			}
+		})
+	}
+}
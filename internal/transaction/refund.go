@@ -0,0 +1,93 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"rekber/ierr"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Refund returns the refund amount of a refunded or resolved transaction to the buyer, through the
+// payment provider from the charge the buyer paid with. A payment which arrived too late to pay its
+// transaction is refunded whole the same way.
+type Refund struct {
+	ID            uuid.UUID
+	TransactionID uuid.UUID
+	BuyerID       uuid.UUID
+	PaymentID     uuid.UUID
+	// PaymentExternalID is the id of the charge at the payment provider which is refunded.
+	PaymentExternalID string
+	Amount            Money
+	// LatePayment tells the refund returns a late or duplicate payment rather than the refund amount
+	// of the transaction, a transaction has at most one refund which is not.
+	LatePayment bool
+	// Retry is the refund at the payment provider.
+	Retry
+	CreatedAt time.Time
+}
+
+func newRefund(t Transaction, p Payment) Refund {
+	now := time.Now()
+	return Refund{
+		ID:                uuid.New(),
+		TransactionID:     t.ID,
+		BuyerID:           t.Buyer.ID,
+		PaymentID:         p.ID,
+		PaymentExternalID: p.ExternalID,
+		Amount:            t.RefundAmount,
+		Retry:             newRetry(now),
+		CreatedAt:         now,
+	}
+}
+
+// newLatePaymentRefund returns the whole payment which arrived after its transaction left waiting
+// for payment, the money never went into escrow.
+func newLatePaymentRefund(t Transaction, p Payment) Refund {
+	r := newRefund(t, p)
+	r.Amount = p.Amount
+	r.LatePayment = true
+
+	return r
+}
+
+type RefundRepository interface {
+	SaveRefund(ctx context.Context, r Refund) error
+	// GetRefund returns the refund of the transaction, or ierr.RefundNotFound. Refunds of late
+	// payments are left out, as they are by ListRefunds.
+	GetRefund(ctx context.Context, transactionID uuid.UUID) (Refund, error)
+	ListRefunds(ctx context.Context, transactionIDs []uuid.UUID) ([]Refund, error)
+	// LockDueRefund locks a pending refund whose next attempt is due, skipping refunds locked by other
+	// workers. It returns ierr.RefundNotFound when there is none, it must be called within a transaction.
+	LockDueRefund(ctx context.Context, now time.Time) (Refund, error)
+	UpdateRefund(ctx context.Context, r Refund) error
+}
+
+// createRefund creates the refund of a transaction which has just been refunded or resolved.
+func (s Service) createRefund(ctx context.Context, t Transaction) error {
+	p, err := s.paymentRepository.GetPaidPayment(ctx, t.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get paid payment: %w", err)
+	}
+
+	if err := s.refundRepository.SaveRefund(ctx, newRefund(t, p)); err != nil {
+		return fmt.Errorf("failed to save refund: %w", err)
+	}
+
+	return nil
+}
+
+// ProcessRefunds attempts every due refund once and returns how many were attempted. A failed
+// attempt is recorded on the refund and retried later, it is not returned as error.
+func (s Service) ProcessRefunds(ctx context.Context, maxAttempts int, backoff time.Duration) (int, error) {
+	return processDue(ctx, s.transactor, retryQueue[Refund]{
+		name:    "refund",
+		lockDue: s.refundRepository.LockDueRefund,
+		isNone:  func(err error) bool { return errors.As(err, &ierr.RefundNotFound{}) },
+		retry:   func(r *Refund) *Retry { return &r.Retry },
+		attempt: s.paymentProvider.Refund,
+		update:  s.refundRepository.UpdateRefund,
+	}, maxAttempts, backoff)
+}
@@ -0,0 +1,135 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"rekber/ierr"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/google/uuid"
+)
+
+type fakeRefundRepository struct {
+	RefundRepository
+	refunds []Refund
+}
+
+func (f *fakeRefundRepository) SaveRefund(ctx context.Context, r Refund) error {
+	f.refunds = append(f.refunds, r)
+	return nil
+}
+
+func (f *fakeRefundRepository) GetRefund(ctx context.Context, transactionID uuid.UUID) (Refund, error) {
+	for _, r := range f.refunds {
+		if r.TransactionID == transactionID && !r.LatePayment {
+			return r, nil
+		}
+	}
+
+	return Refund{}, ierr.RefundNotFound{TransactionID: transactionID}
+}
+
+func (f *fakeRefundRepository) LockDueRefund(ctx context.Context, now time.Time) (Refund, error) {
+	for _, r := range f.refunds {
+		if r.Status == retryPending && !r.NextAttemptAt.After(now) {
+			return r, nil
+		}
+	}
+
+	return Refund{}, ierr.RefundNotFound{}
+}
+
+func (f *fakeRefundRepository) UpdateRefund(ctx context.Context, r Refund) error {
+	for i := range f.refunds {
+		if f.refunds[i].ID == r.ID {
+			f.refunds[i] = r
+		}
+	}
+
+	return nil
+}
+
+type fakeRefundProvider struct {
+	PaymentProvider
+	err error
+}
+
+func (f fakeRefundProvider) Refund(ctx context.Context, r Refund) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+
+	return "refund-" + r.ID.String(), nil
+}
+
+func TestService_ProcessRefunds(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time {
+		return now
+	})
+	defer patches.Reset()
+
+	refundUUID := uuid.New()
+	errProvider := errors.New("provider is offline")
+
+	tests := []struct {
+		name          string
+		refund        Refund
+		providerErr   error
+		wantProcessed int
+		wantStatus    RetryStatus
+		wantAttempts  int
+	}{
+		{
+			name:          "due refund is returned to the buyer",
+			refund:        Refund{ID: refundUUID, Retry: Retry{Status: retryPending, NextAttemptAt: now}},
+			wantProcessed: 1,
+			wantStatus:    retrySucceeded,
+			wantAttempts:  1,
+		},
+		{
+			name:          "failed refund is retried later",
+			refund:        Refund{ID: refundUUID, Retry: Retry{Status: retryPending, NextAttemptAt: now}},
+			providerErr:   errProvider,
+			wantProcessed: 1,
+			wantStatus:    retryPending,
+			wantAttempts:  1,
+		},
+		{
+			name:          "refund failing its last attempt is failed",
+			refund:        Refund{ID: refundUUID, Retry: Retry{Status: retryPending, NextAttemptAt: now, Attempts: 4}},
+			providerErr:   errProvider,
+			wantProcessed: 1,
+			wantStatus:    retryFailed,
+			wantAttempts:  5,
+		},
+		{
+			name:       "refund which is not due yet is left alone",
+			refund:     Refund{ID: refundUUID, Retry: Retry{Status: retryPending, NextAttemptAt: now.Add(time.Minute)}},
+			wantStatus: retryPending,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRefundRepository{refunds: []Refund{tt.refund}}
+			s := Service{
+				refundRepository: repo,
+				paymentProvider:  fakeRefundProvider{err: tt.providerErr},
+				transactor:       fakeTransactor{},
+			}
+
+			got, err := s.ProcessRefunds(context.Background(), 5, time.Minute)
+			if err != nil {
+				t.Fatalf("Service.ProcessRefunds() error = %v", err)
+			}
+			if got != tt.wantProcessed {
+				t.Errorf("Service.ProcessRefunds() = %v, want %v", got, tt.wantProcessed)
+			}
+			if r := repo.refunds[0]; r.Status != tt.wantStatus || r.Attempts != tt.wantAttempts {
+				t.Errorf("Service.ProcessRefunds() refund status = %v attempts = %v, want %v and %v", r.Status, r.Attempts, tt.wantStatus, tt.wantAttempts)
+			}
+		})
+	}
+}
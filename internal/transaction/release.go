@@ -0,0 +1,155 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"rekber/ierr"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NotificationKind string
+
+const releaseReminder NotificationKind = "release_reminder"
+
+type Notification struct {
+	Kind          NotificationKind
+	UserID        uuid.UUID
+	TransactionID uuid.UUID
+	Message       string
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// Inspection is how long the buyer has to check the goods after the seller marks the transaction
+// done or the courier delivers them, the funds are released to the seller once it passes without
+// the buyer confirming. A zero period never releases automatically.
+type Inspection struct {
+	Period time.Duration
+	// RemindBefore is how long before the release both parties are reminded of it.
+	RemindBefore time.Duration
+	// DeliveryTimeout is how long after the seller marks a shipped transaction done the inspection
+	// starts when the courier does not report the delivery. A zero timeout waits for the courier.
+	DeliveryTimeout time.Duration
+}
+
+// AutoRelease starts the inspection of shipments the courier did not report delivered within the
+// delivery timeout, reminds both parties of upcoming releases, then releases every transaction
+// whose inspection period has passed and returns how many were released. A transaction is only
+// released once RemindBefore has passed since its reminder, so nobody is surprised by a release
+// even when the reminder is sent late.
+func (s Service) AutoRelease(ctx context.Context, in Inspection) (int, error) {
+	if err := s.startUndelivered(ctx, in.DeliveryTimeout); err != nil {
+		return 0, err
+	}
+
+	if in.Period <= 0 {
+		return 0, nil
+	}
+
+	remindBefore := in.RemindBefore
+	if remindBefore > in.Period {
+		remindBefore = in.Period
+	}
+
+	err := s.lockEach(ctx, func(ctx context.Context) (Transaction, error) {
+		return s.repository.LockDueReminder(ctx, time.Now().Add(-in.Period+remindBefore))
+	}, s.remindRelease)
+	if err != nil {
+		return 0, err
+	}
+
+	released := 0
+	err = s.lockEach(ctx, func(ctx context.Context) (Transaction, error) {
+		now := time.Now()
+		return s.repository.LockDueRelease(ctx, now.Add(-in.Period), now.Add(-remindBefore))
+	}, func(ctx context.Context, t Transaction) error {
+		if _, err := s.transition(ctx, t, release, System{}.command()); err != nil {
+			return err
+		}
+
+		released++
+		return nil
+	})
+
+	return released, err
+}
+
+// lockEach runs fn on every transaction locked by lock, each within its own transaction.
+func (s Service) lockEach(ctx context.Context, lock func(ctx context.Context) (Transaction, error), fn func(ctx context.Context, t Transaction) error) error {
+	for {
+		err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			t, err := lock(ctx)
+			if err != nil {
+				return err
+			}
+
+			return fn(ctx, t)
+		})
+		if errors.As(err, &ierr.TransactionNotFound{}) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// startUndelivered starts the inspection of every transaction done by seller longer than timeout
+// ago which the courier has not reported delivered, so the funds are not locked forever.
+func (s Service) startUndelivered(ctx context.Context, timeout time.Duration) error {
+	if timeout <= 0 {
+		return nil
+	}
+
+	for {
+		err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			t, err := s.repository.LockUndelivered(ctx, time.Now().Add(-timeout))
+			if err != nil {
+				return err
+			}
+
+			c := System{}.command()
+			c.reason = "delivery is not reported within the delivery timeout"
+			_, err = s.transition(ctx, t, deliver, c)
+			return err
+		})
+		if errors.As(err, &ierr.TransactionNotFound{}) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s Service) remindRelease(ctx context.Context, t Transaction) error {
+	for _, userID := range []uuid.UUID{t.Buyer.ID, t.Seller.ID} {
+		n := Notification{
+			Kind:          releaseReminder,
+			UserID:        userID,
+			TransactionID: t.ID,
+			Message:       "funds of the transaction will be released to the seller soon unless the buyer confirms",
+		}
+		if err := s.notifier.Notify(ctx, n); err != nil {
+			return fmt.Errorf("failed to notify release reminder: %w", err)
+		}
+	}
+
+	updated := t
+	updated.ReleaseRemindedAt = time.Now()
+	_, err := s.save(ctx, t, updated, Event{
+		TransactionID: t.ID,
+		Type:          eventReleaseReminded,
+		Actor:         system,
+		OccurredAt:    updated.ReleaseRemindedAt,
+	})
+
+	return err
+}
@@ -0,0 +1,205 @@
+package transaction
+
+import (
+	"context"
+	"rekber/ierr"
+	"rekber/internal/user"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/google/uuid"
+)
+
+func (f *fakeRepository) LockDueReminder(ctx context.Context, cutoff time.Time) (Transaction, error) {
+	for _, t := range f.trxs {
+		if t.Status == doneBySeller && !t.InspectionStartedAt.IsZero() && t.InspectionStartedAt.Before(cutoff) && t.ReleaseRemindedAt.IsZero() {
+			return t, nil
+		}
+	}
+
+	return Transaction{}, ierr.TransactionNotFound{}
+}
+
+func (f *fakeRepository) LockDueRelease(ctx context.Context, cutoff, remindedBefore time.Time) (Transaction, error) {
+	for _, t := range f.trxs {
+		if t.Status == doneBySeller && !t.InspectionStartedAt.IsZero() && t.InspectionStartedAt.Before(cutoff) && !t.ReleaseRemindedAt.IsZero() && !t.ReleaseRemindedAt.After(remindedBefore) {
+			return t, nil
+		}
+	}
+
+	return Transaction{}, ierr.TransactionNotFound{}
+}
+
+func (f *fakeRepository) LockUndelivered(ctx context.Context, doneBefore time.Time) (Transaction, error) {
+	for _, t := range f.trxs {
+		if t.Status == doneBySeller && t.InspectionStartedAt.IsZero() && t.DoneBySellerAt.Before(doneBefore) {
+			return t, nil
+		}
+	}
+
+	return Transaction{}, ierr.TransactionNotFound{}
+}
+
+type fakeUserRepository struct {
+	UserRepository
+	admins       map[uuid.UUID]bool
+	verified     map[uuid.UUID]time.Time
+	bankAccounts map[uuid.UUID]user.BankAccount
+}
+
+func (f fakeUserRepository) GetByID(ctx context.Context, id uuid.UUID) (user.User, error) {
+	return user.User{ID: id, IsAdmin: f.admins[id], PhoneNumberVerifiedAt: f.verified[id], BankAccount: f.bankAccounts[id]}, nil
+}
+
+type fakePayoutRepository struct {
+	PayoutRepository
+	payouts []Payout
+}
+
+func (f *fakePayoutRepository) SavePayout(ctx context.Context, p Payout) error {
+	f.payouts = append(f.payouts, p)
+	return nil
+}
+
+func (f *fakePayoutRepository) GetPayout(ctx context.Context, transactionID uuid.UUID) (Payout, error) {
+	for _, p := range f.payouts {
+		if p.TransactionID == transactionID {
+			return p, nil
+		}
+	}
+
+	return Payout{}, ierr.PayoutNotFound{TransactionID: transactionID}
+}
+
+type fakeNotifier struct {
+	notifications []Notification
+}
+
+func (f *fakeNotifier) Notify(ctx context.Context, n Notification) error {
+	f.notifications = append(f.notifications, n)
+	return nil
+}
+
+func TestService_AutoRelease(t *testing.T) {
+	timeNow := time.Date(2023, 9, 30, 21, 0, 0, 0, time.UTC)
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time {
+		return timeNow
+	})
+	defer patches.Reset()
+
+	overdue := uuid.New()
+	overdueReminded := uuid.New()
+	remindedLately := uuid.New()
+	remindable := uuid.New()
+	inspecting := uuid.New()
+	undelivered := uuid.New()
+	shipping := uuid.New()
+
+	breakdown := Breakdown{ItemTotal: NewMoney(100, "IDR"), EscrowFee: NewMoney(10, "IDR"), SellerNet: NewMoney(90, "IDR")}
+	trxs := func() map[uuid.UUID]Transaction {
+		return map[uuid.UUID]Transaction{
+			overdue:         {ID: overdue, Status: doneBySeller, Breakdown: breakdown, InspectionStartedAt: timeNow.Add(-80 * time.Hour)},
+			overdueReminded: {ID: overdueReminded, Status: doneBySeller, Breakdown: breakdown, InspectionStartedAt: timeNow.Add(-80 * time.Hour), ReleaseRemindedAt: timeNow.Add(-30 * time.Hour)},
+			remindedLately:  {ID: remindedLately, Status: doneBySeller, Breakdown: breakdown, InspectionStartedAt: timeNow.Add(-80 * time.Hour), ReleaseRemindedAt: timeNow.Add(-time.Hour)},
+			remindable:      {ID: remindable, Status: doneBySeller, Breakdown: breakdown, InspectionStartedAt: timeNow.Add(-50 * time.Hour)},
+			inspecting:      {ID: inspecting, Status: doneBySeller, Breakdown: breakdown, InspectionStartedAt: timeNow.Add(-10 * time.Hour)},
+			undelivered:     {ID: undelivered, Status: doneBySeller, Breakdown: breakdown, DoneBySellerAt: timeNow.Add(-200 * time.Hour)},
+			shipping:        {ID: shipping, Status: doneBySeller, Breakdown: breakdown, DoneBySellerAt: timeNow.Add(-10 * time.Hour)},
+		}
+	}
+
+	tests := []struct {
+		name              string
+		inspection        Inspection
+		want              int
+		wantReleased      []uuid.UUID
+		wantReminded      []uuid.UUID
+		wantNotifications int
+		wantInspection    map[uuid.UUID]time.Time
+	}{
+		{
+			name:              "transactions past inspection period are released once both parties are reminded long enough before",
+			inspection:        Inspection{Period: 72 * time.Hour, RemindBefore: 24 * time.Hour},
+			want:              1,
+			wantReleased:      []uuid.UUID{overdueReminded},
+			wantReminded:      []uuid.UUID{overdue, remindedLately, remindable},
+			wantNotifications: 4,
+		},
+		{
+			name:              "transactions reminded in this run are released in a later run",
+			inspection:        Inspection{Period: 72 * time.Hour, RemindBefore: 30 * time.Minute},
+			want:              2,
+			wantReleased:      []uuid.UUID{overdueReminded, remindedLately},
+			wantReminded:      []uuid.UUID{overdue},
+			wantNotifications: 2,
+		},
+		{
+			name:              "undelivered transactions past delivery timeout start the inspection",
+			inspection:        Inspection{Period: 72 * time.Hour, RemindBefore: 24 * time.Hour, DeliveryTimeout: 168 * time.Hour},
+			want:              1,
+			wantReleased:      []uuid.UUID{overdueReminded},
+			wantReminded:      []uuid.UUID{overdue, remindedLately, remindable},
+			wantNotifications: 4,
+			wantInspection:    map[uuid.UUID]time.Time{undelivered: timeNow},
+		},
+		{
+			name:       "zero period never releases",
+			inspection: Inspection{RemindBefore: 24 * time.Hour},
+			want:       0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepository{trxs: trxs()}
+			payoutRepo := &fakePayoutRepository{}
+			l := &fakeLedger{}
+			notifier := &fakeNotifier{}
+			s := Service{
+				repository:         repo,
+				userRepository:     fakeUserRepository{},
+				payoutRepository:   payoutRepo,
+				transactor:         fakeTransactor{},
+				timelineRepository: &fakeTimelineRepository{},
+				eventStore:         &fakeEventStore{},
+				outbox:             &fakeOutbox{},
+				ledger:             l,
+				notifier:           notifier,
+			}
+
+			got, err := s.AutoRelease(context.Background(), tt.inspection)
+			if err != nil {
+				t.Fatalf("Service.AutoRelease() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Service.AutoRelease() = %v, want %v", got, tt.want)
+			}
+
+			for _, id := range tt.wantReleased {
+				trx := repo.trxs[id]
+				if trx.Status != success || trx.SuccessBy != system || !trx.SuccessAt.Equal(timeNow) {
+					t.Errorf("transaction %v = %v by %v at %v, want released by system", id, trx.Status, trx.SuccessBy, trx.SuccessAt)
+				}
+			}
+			for _, id := range tt.wantReminded {
+				if trx := repo.trxs[id]; trx.Status != doneBySeller || trx.ReleaseRemindedAt.IsZero() {
+					t.Errorf("transaction %v = %v reminded at %v, want reminded and not released", id, trx.Status, trx.ReleaseRemindedAt)
+				}
+			}
+			if len(payoutRepo.payouts) != len(tt.wantReleased) || len(l.entries) != len(tt.wantReleased) {
+				t.Errorf("Service.AutoRelease() payouts = %v, entries = %v, want %v", len(payoutRepo.payouts), len(l.entries), len(tt.wantReleased))
+			}
+			if len(notifier.notifications) != tt.wantNotifications {
+				t.Errorf("Service.AutoRelease() notifications = %v, want %v", len(notifier.notifications), tt.wantNotifications)
+			}
+			for _, id := range []uuid.UUID{undelivered, shipping} {
+				if trx := repo.trxs[id]; trx.Status != doneBySeller || !trx.InspectionStartedAt.Equal(tt.wantInspection[id]) {
+					t.Errorf("transaction %v = %v inspection started at %v, want %v", id, trx.Status, trx.InspectionStartedAt, tt.wantInspection[id])
+				}
+			}
+			if repo.trxs[inspecting].Status != doneBySeller || !repo.trxs[inspecting].ReleaseRemindedAt.IsZero() {
+				t.Errorf("transaction within inspection period is changed")
+			}
+		})
+	}
+}
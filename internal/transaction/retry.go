@@ -0,0 +1,148 @@
+package transaction
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// maxRetryBackoff caps the exponential backoff between attempts of a payout or a refund.
+const maxRetryBackoff = time.Hour
+
+type RetryStatus string
+
+const (
+	retryPending   RetryStatus = "pending"
+	retrySucceeded RetryStatus = "succeeded"
+	retryFailed    RetryStatus = "failed"
+)
+
+// Retry is the state of a money movement which is attempted at a provider until it succeeds or runs
+// out of attempts, e.g. a payout to the seller or a refund to the buyer.
+type Retry struct {
+	Status        RetryStatus
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	// ExternalID is the id of the money movement at the provider.
+	ExternalID  string
+	SucceededAt time.Time
+	FailedAt    time.Time
+}
+
+// newRetry returns a retry which is due at now.
+func newRetry(now time.Time) Retry {
+	return Retry{
+		Status:        retryPending,
+		NextAttemptAt: now,
+	}
+}
+
+func (r Retry) succeed(externalID string) Retry {
+	r.Attempts++
+	r.Status = retrySucceeded
+	r.ExternalID = externalID
+	r.LastError = ""
+	r.SucceededAt = time.Now()
+
+	return r
+}
+
+// fail records a failed attempt, it is retried with exponential backoff until maxAttempts is reached.
+func (r Retry) fail(err error, maxAttempts int, backoff time.Duration) Retry {
+	r.Attempts++
+	r.LastError = err.Error()
+
+	if r.Attempts >= maxAttempts {
+		r.Status = retryFailed
+		r.FailedAt = time.Now()
+		return r
+	}
+
+	delay := backoff << (r.Attempts - 1)
+	if delay <= 0 || delay > maxRetryBackoff {
+		delay = maxRetryBackoff
+	}
+
+	r.NextAttemptAt = time.Now().Add(delay)
+	return r
+}
+
+// wait postpones the next attempt by delay without using an attempt, for a money movement which
+// cannot be attempted yet.
+func (r Retry) wait(reason string, delay time.Duration) Retry {
+	r.LastError = reason
+	r.NextAttemptAt = time.Now().Add(delay)
+
+	return r
+}
+
+// retryQueue is where the due payouts or refunds are locked and attempted.
+type retryQueue[T any] struct {
+	// name is what is retried, used in error messages.
+	name string
+	// lockDue locks the next due one, or returns an error for which isNone reports true when there
+	// is none.
+	lockDue func(ctx context.Context, now time.Time) (T, error)
+	isNone  func(err error) bool
+	// retry returns the retry state of the locked one.
+	retry func(v *T) *Retry
+	// ready completes the locked one before its attempt and returns why it cannot be attempted yet,
+	// or an empty reason when it can. It may be nil when every one can be attempted.
+	ready func(ctx context.Context, v *T) (string, error)
+	// attempt moves the money at the provider and returns the id of the movement.
+	attempt func(ctx context.Context, v T) (string, error)
+	update  func(ctx context.Context, v T) error
+}
+
+// processDue attempts every due payout or refund of q once, each within its own transaction, and
+// returns how many were attempted. A failed attempt is recorded and retried later, it is not
+// returned as error. One which is not ready waits for backoff without using an attempt.
+func processDue[T any](ctx context.Context, transactor Transactor, q retryQueue[T], maxAttempts int, backoff time.Duration) (int, error) {
+	processed := 0
+	for {
+		attempted := false
+		err := transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			v, err := q.lockDue(ctx, time.Now())
+			if err != nil {
+				return err
+			}
+
+			reason := ""
+			if q.ready != nil {
+				if reason, err = q.ready(ctx, &v); err != nil {
+					return err
+				}
+			}
+
+			r := q.retry(&v)
+			if reason != "" {
+				*r = r.wait(reason, backoff)
+			} else {
+				attempted = true
+				if externalID, err := q.attempt(ctx, v); err != nil {
+					*r = r.fail(err, maxAttempts, backoff)
+				} else {
+					*r = r.succeed(externalID)
+				}
+			}
+
+			if err := q.update(ctx, v); err != nil {
+				return fmt.Errorf("failed to update %s: %w", q.name, err)
+			}
+
+			return nil
+		})
+		if q.isNone(err) {
+			return processed, nil
+		}
+
+		if err != nil {
+			return processed, err
+		}
+
+		if attempted {
+			processed++
+		}
+	}
+}
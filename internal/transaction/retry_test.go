@@ -0,0 +1,103 @@
+package transaction
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+)
+
+func TestRetry_fail(t *testing.T) {
+	timeNow := time.Date(2023, 9, 30, 21, 0, 0, 0, time.UTC)
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time {
+		return timeNow
+	})
+	defer patches.Reset()
+
+	errBank := errors.New("bank is offline")
+
+	type args struct {
+		maxAttempts int
+		backoff     time.Duration
+	}
+	tests := []struct {
+		name  string
+		retry Retry
+		args  args
+		want  Retry
+	}{
+		{
+			name:  "first failed attempt is retried after backoff",
+			retry: Retry{Status: retryPending},
+			args:  args{maxAttempts: 5, backoff: time.Minute},
+			want: Retry{
+				Status:        retryPending,
+				Attempts:      1,
+				NextAttemptAt: timeNow.Add(time.Minute),
+				LastError:     errBank.Error(),
+			},
+		},
+		{
+			name:  "backoff doubles on every failed attempt",
+			retry: Retry{Status: retryPending, Attempts: 2},
+			args:  args{maxAttempts: 5, backoff: time.Minute},
+			want: Retry{
+				Status:        retryPending,
+				Attempts:      3,
+				NextAttemptAt: timeNow.Add(4 * time.Minute),
+				LastError:     errBank.Error(),
+			},
+		},
+		{
+			name:  "backoff is capped",
+			retry: Retry{Status: retryPending, Attempts: 10},
+			args:  args{maxAttempts: 20, backoff: time.Minute},
+			want: Retry{
+				Status:        retryPending,
+				Attempts:      11,
+				NextAttemptAt: timeNow.Add(maxRetryBackoff),
+				LastError:     errBank.Error(),
+			},
+		},
+		{
+			name:  "retry fails after the last attempt",
+			retry: Retry{Status: retryPending, Attempts: 4},
+			args:  args{maxAttempts: 5, backoff: time.Minute},
+			want: Retry{
+				Status:    retryFailed,
+				Attempts:  5,
+				LastError: errBank.Error(),
+				FailedAt:  timeNow,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.retry.fail(errBank, tt.args.maxAttempts, tt.args.backoff); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Retry.fail() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetry_succeed(t *testing.T) {
+	timeNow := time.Date(2023, 9, 30, 21, 0, 0, 0, time.UTC)
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time {
+		return timeNow
+	})
+	defer patches.Reset()
+
+	r := Retry{Status: retryPending, Attempts: 1, LastError: "bank is offline"}
+
+	want := Retry{
+		Status:      retrySucceeded,
+		Attempts:    2,
+		ExternalID:  "disbursement-1",
+		SucceededAt: timeNow,
+	}
+	if got := r.succeed("disbursement-1"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Retry.succeed() = %v, want %v", got, want)
+	}
+}
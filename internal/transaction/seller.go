@@ -1,7 +1,7 @@
 package transaction
 
 import (
-	"errors"
+	"rekber/ierr"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,42 +14,78 @@ type Seller struct {
 }
 
 func (s Seller) IsEligible() bool {
-	return (!s.PhoneNumberVerifiedAt.IsZero()) && !(s.BankAccount.ID == uuid.Nil)
+	return (!s.PhoneNumberVerifiedAt.IsZero()) && s.BankAccount.IsVerified()
 }
 
-func (s Seller) Accept(t Transaction) (Transaction, error) {
-	if !s.IsEligible() {
-		return Transaction{}, errors.New("user is not eligible")
+func (s Seller) notEligibleReason() string {
+	if s.PhoneNumberVerifiedAt.IsZero() {
+		return "phone number is not verified yet"
 	}
 
-	if t.CreatedBy != buyer {
-		return Transaction{}, errors.New("transaction created is not by buyer")
+	if s.BankAccount.ID == uuid.Nil {
+		return "bank account is not registered yet"
+	}
+
+	return "bank account is not verified yet"
+}
+
+func (s Seller) Create(b Buyer, items []Item) (Transaction, error) {
+	if !s.IsEligible() {
+		return Transaction{}, ierr.SellerIsNotEligible{ID: s.ID, Reason: s.notEligibleReason()}
 	}
 
-	if !t.VerifyLastStatus(waitingForPayment) {
-		return Transaction{}, errors.New("transaction last status is not valid")
+	breakdown, err := calculateBreakdown(items)
+	if err != nil {
+		return Transaction{}, err
 	}
 
-	t.Status = waitingForPayment
-	t.AcceptedAt = time.Now()
-	t.AcceptedBy = seller
+	return Transaction{
+		ID:        uuid.New(),
+		Seller:    s,
+		Buyer:     b,
+		Items:     items,
+		Breakdown: breakdown,
+		CreatedBy: seller,
+		CreatedAt: time.Now(),
+		Status:    waitingForApproval,
+	}, nil
+}
 
-	return t, nil
+func (s Seller) Accept(t Transaction) (Transaction, error) {
+	return fire(t, accept, s.command())
 }
 
 func (s Seller) Reject(t Transaction, reason string) (Transaction, error) {
-	if !t.VerifyLastStatus(rejected) {
-		return Transaction{}, errors.New("transaction last status is not valid")
-	}
+	c := s.command()
+	c.reason = reason
 
-	if t.CreatedBy != buyer {
-		return Transaction{}, errors.New("transaction created not by buyer")
-	}
+	return fire(t, reject, c)
+}
+
+// Done marks the transaction done by seller, shipping is zero when the goods are not shipped.
+func (s Seller) Done(t Transaction, shipping Shipping) (Transaction, error) {
+	c := s.command()
+	c.shipping = shipping
+
+	return fire(t, done, c)
+}
 
-	t.RejectedAt = time.Now()
-	t.RejectedBy = seller
-	t.RejectedReason = reason
-	t.Status = rejected
+// RespondDispute gives the seller side of a dispute, it can be given once before the dispute deadline.
+func (s Seller) RespondDispute(t Transaction, response string) (Transaction, error) {
+	c := s.command()
+	c.reason = response
 
-	return t, nil
+	return fire(t, respond, c)
+}
+
+// AvailableActions returns the actions the seller can take on the transaction now.
+func (s Seller) AvailableActions(t Transaction) []Action {
+	return availableActions(t, s.command())
+}
+
+func (s Seller) command() command {
+	return command{
+		actor:  seller,
+		seller: s,
+	}
 }
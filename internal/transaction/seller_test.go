@@ -1,6 +1,7 @@
 package transaction
 
 import (
+	"math/rand"
 	"reflect"
 	"testing"
 	"time"
@@ -26,7 +27,8 @@ func TestSeller_IsEligible(t *testing.T) {
 				ID:                    uuid.New(),
 				PhoneNumberVerifiedAt: time.Now(),
 				BankAccount: BankAccount{
-					ID: uuid.New(),
+					ID:         uuid.New(),
+					VerifiedAt: time.Now(),
 				},
 			},
 			want: true,
@@ -36,7 +38,8 @@ func TestSeller_IsEligible(t *testing.T) {
 			fields: fields{
 				ID: uuid.New(),
 				BankAccount: BankAccount{
-					ID: uuid.New(),
+					ID:         uuid.New(),
+					VerifiedAt: time.Now(),
 				},
 			},
 			want: false,
@@ -49,6 +52,17 @@ func TestSeller_IsEligible(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "seller is not eligible because registered bank account is not verified yet",
+			fields: fields{
+				ID:                    uuid.New(),
+				PhoneNumberVerifiedAt: time.Now(),
+				BankAccount: BankAccount{
+					ID: uuid.New(),
+				},
+			},
+			want: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -64,6 +78,188 @@ func TestSeller_IsEligible(t *testing.T) {
 	}
 }
 
+func TestSeller_Create(t *testing.T) {
+	uuid.SetRand(rand.New(rand.NewSource(1)))
+
+	uuidBuyer := uuid.MustParse("861b1cd4-90ec-4633-9e84-dcfbd03a9fe5")
+	uuidSeller := uuid.MustParse("28551a5b-c62f-43bb-9893-3438bc6135df")
+	uuidBankAccount := uuid.MustParse("d6fb5c36-4d2c-4b5e-8a5b-0d2a1e6a3c1f")
+	verifiedAt := time.Now()
+
+	createdAt := time.Now()
+	gomonkey.ApplyFunc(time.Now, func() time.Time {
+		return createdAt
+	})
+
+	items := []Item{
+		{
+			Name:     "mechanical keyboard",
+			Quantity: 2,
+			Price:    NewMoney(1_000_000_00, IDR),
+		},
+	}
+
+	type fields struct {
+		ID                    uuid.UUID
+		PhoneNumberVerifiedAt time.Time
+		BankAccount           BankAccount
+	}
+	type args struct {
+		b     Buyer
+		items []Item
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    Transaction
+		wantErr bool
+	}{
+		{
+			name: "seller is not eligible because phone number is not verified yet",
+			fields: fields{
+				ID: uuidSeller,
+				BankAccount: BankAccount{
+					ID:         uuidBankAccount,
+					VerifiedAt: verifiedAt,
+				},
+			},
+			args: args{
+				b: Buyer{
+					ID: uuidBuyer,
+				},
+				items: items,
+			},
+			want:    Transaction{},
+			wantErr: true,
+		},
+		{
+			name: "seller is not eligible because bank account is not registered yet",
+			fields: fields{
+				ID:                    uuidSeller,
+				PhoneNumberVerifiedAt: verifiedAt,
+			},
+			args: args{
+				b: Buyer{
+					ID: uuidBuyer,
+				},
+				items: items,
+			},
+			want:    Transaction{},
+			wantErr: true,
+		},
+		{
+			name: "transaction has no item",
+			fields: fields{
+				ID:                    uuidSeller,
+				PhoneNumberVerifiedAt: verifiedAt,
+				BankAccount: BankAccount{
+					ID:         uuidBankAccount,
+					VerifiedAt: verifiedAt,
+				},
+			},
+			args: args{
+				b: Buyer{
+					ID: uuidBuyer,
+				},
+			},
+			want:    Transaction{},
+			wantErr: true,
+		},
+		{
+			name: "seller is eligible",
+			fields: fields{
+				ID:                    uuidSeller,
+				PhoneNumberVerifiedAt: verifiedAt,
+				BankAccount: BankAccount{
+					ID:         uuidBankAccount,
+					VerifiedAt: verifiedAt,
+				},
+			},
+			args: args{
+				b: Buyer{
+					ID: uuidBuyer,
+				},
+				items: items,
+			},
+			want: Transaction{
+				ID: uuid.MustParse("52fdfc07-2182-454f-963f-5f0f9a621d72"),
+				Seller: Seller{
+					ID:                    uuidSeller,
+					PhoneNumberVerifiedAt: verifiedAt,
+					BankAccount: BankAccount{
+						ID:         uuidBankAccount,
+						VerifiedAt: verifiedAt,
+					},
+				},
+				Buyer: Buyer{
+					ID: uuidBuyer,
+				},
+				Items: items,
+				Breakdown: Breakdown{
+					ItemTotal: NewMoney(2_000_000_00, IDR),
+					EscrowFee: NewMoney(20_000_00, IDR),
+					SellerNet: NewMoney(1_980_000_00, IDR),
+				},
+				CreatedBy: seller,
+				CreatedAt: createdAt,
+				Status:    waitingForApproval,
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Seller{
+				ID:                    tt.fields.ID,
+				PhoneNumberVerifiedAt: tt.fields.PhoneNumberVerifiedAt,
+				BankAccount:           tt.fields.BankAccount,
+			}
+			got, err := s.Create(tt.args.b, tt.args.items)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Seller.Create() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Seller.Create() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeller_Create_AnsweredByBuyer(t *testing.T) {
+	b := Buyer{
+		ID:                    uuid.New(),
+		PhoneNumberVerifiedAt: time.Now(),
+	}
+	s := Seller{
+		ID:                    uuid.New(),
+		PhoneNumberVerifiedAt: time.Now(),
+		BankAccount: BankAccount{
+			ID:         uuid.New(),
+			VerifiedAt: time.Now(),
+		},
+	}
+
+	trx, err := s.Create(b, []Item{{Name: "book", Quantity: 1, Price: NewMoney(100_000_00, IDR)}})
+	if err != nil {
+		t.Fatalf("Seller.Create() error = %v", err)
+	}
+
+	if _, err := b.Accept(trx); err != nil {
+		t.Errorf("Buyer.Accept() error = %v, want transaction created by seller to be accepted", err)
+	}
+
+	if _, err := b.Reject(trx, "changed my mind"); err != nil {
+		t.Errorf("Buyer.Reject() error = %v, want transaction created by seller to be rejected", err)
+	}
+
+	if _, err := s.Accept(trx); err == nil {
+		t.Errorf("Seller.Accept() error = nil, want seller not to accept its own transaction")
+	}
+}
+
 func TestSeller_Accept(t *testing.T) {
 	trxUUID := uuid.New()
 	createdAt := time.Now()
@@ -95,7 +291,8 @@ func TestSeller_Accept(t *testing.T) {
 				ID:                    uuid.New(),
 				PhoneNumberVerifiedAt: time.Now(),
 				BankAccount: BankAccount{
-					ID: uuid.New(),
+					ID:         uuid.New(),
+					VerifiedAt: time.Now(),
 				},
 			},
 			args: args{
@@ -137,7 +334,8 @@ func TestSeller_Accept(t *testing.T) {
 				ID:                    uuid.New(),
 				PhoneNumberVerifiedAt: time.Now(),
 				BankAccount: BankAccount{
-					ID: uuid.New(),
+					ID:         uuid.New(),
+					VerifiedAt: time.Now(),
 				},
 			},
 			args: args{
@@ -156,7 +354,8 @@ func TestSeller_Accept(t *testing.T) {
 				ID:                    uuid.New(),
 				PhoneNumberVerifiedAt: time.Now(),
 				BankAccount: BankAccount{
-					ID: uuid.New(),
+					ID:         uuid.New(),
+					VerifiedAt: time.Now(),
 				},
 			},
 			args: args{
@@ -291,3 +490,169 @@ func TestSeller_Reject(t *testing.T) {
 		})
 	}
 }
+
+func TestSeller_Done(t *testing.T) {
+	trxUUID := uuid.New()
+
+	doneAt := time.Now()
+	gomonkey.ApplyFunc(time.Now, func() time.Time {
+		return doneAt
+	})
+
+	type fields struct {
+		ID                    uuid.UUID
+		PhoneNumberVerifiedAt time.Time
+		BankAccount           BankAccount
+	}
+	type args struct {
+		t        Transaction
+		shipping Shipping
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    Transaction
+		wantErr bool
+	}{
+		{
+			name: "seller set transaction to done successfully",
+			fields: fields{
+				ID:                    uuid.New(),
+				PhoneNumberVerifiedAt: time.Now(),
+				BankAccount: BankAccount{
+					ID:         uuid.New(),
+					VerifiedAt: time.Now(),
+				},
+			},
+			args: args{
+				t: Transaction{
+					ID:     trxUUID,
+					Status: paid,
+				},
+			},
+			want: Transaction{
+				ID:                  trxUUID,
+				Status:              doneBySeller,
+				DoneBySellerAt:      doneAt,
+				InspectionStartedAt: doneAt,
+			},
+			wantErr: false,
+		},
+		{
+			name: "shipped transaction starts inspection on delivery",
+			fields: fields{
+				ID:                    uuid.New(),
+				PhoneNumberVerifiedAt: time.Now(),
+				BankAccount: BankAccount{
+					ID:         uuid.New(),
+					VerifiedAt: time.Now(),
+				},
+			},
+			args: args{
+				t: Transaction{
+					ID:     trxUUID,
+					Status: paid,
+				},
+				shipping: Shipping{CourierCode: "jne", AirwayBill: "JNE0001"},
+			},
+			want: Transaction{
+				ID:             trxUUID,
+				Status:         doneBySeller,
+				DoneBySellerAt: doneAt,
+			},
+			wantErr: false,
+		},
+		{
+			name: "courier is not supported",
+			fields: fields{
+				ID:                    uuid.New(),
+				PhoneNumberVerifiedAt: time.Now(),
+				BankAccount: BankAccount{
+					ID:         uuid.New(),
+					VerifiedAt: time.Now(),
+				},
+			},
+			args: args{
+				t: Transaction{
+					ID:     trxUUID,
+					Status: paid,
+				},
+				shipping: Shipping{CourierCode: "unknown", AirwayBill: "X0001"},
+			},
+			want:    Transaction{},
+			wantErr: true,
+		},
+		{
+			name: "airway bill is missing",
+			fields: fields{
+				ID:                    uuid.New(),
+				PhoneNumberVerifiedAt: time.Now(),
+				BankAccount: BankAccount{
+					ID:         uuid.New(),
+					VerifiedAt: time.Now(),
+				},
+			},
+			args: args{
+				t: Transaction{
+					ID:     trxUUID,
+					Status: paid,
+				},
+				shipping: Shipping{CourierCode: "jne"},
+			},
+			want:    Transaction{},
+			wantErr: true,
+		},
+		{
+			name: "seller is not eligible",
+			fields: fields{
+				ID:                    uuid.New(),
+				PhoneNumberVerifiedAt: time.Now(),
+			},
+			args: args{
+				t: Transaction{
+					ID:     trxUUID,
+					Status: paid,
+				},
+			},
+			want:    Transaction{},
+			wantErr: true,
+		},
+		{
+			name: "transaction is not paid yet",
+			fields: fields{
+				ID:                    uuid.New(),
+				PhoneNumberVerifiedAt: time.Now(),
+				BankAccount: BankAccount{
+					ID:         uuid.New(),
+					VerifiedAt: time.Now(),
+				},
+			},
+			args: args{
+				t: Transaction{
+					ID:     trxUUID,
+					Status: waitingForPayment,
+				},
+			},
+			want:    Transaction{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Seller{
+				ID:                    tt.fields.ID,
+				PhoneNumberVerifiedAt: tt.fields.PhoneNumberVerifiedAt,
+				BankAccount:           tt.fields.BankAccount,
+			}
+			got, err := s.Done(tt.args.t, tt.args.shipping)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Seller.Done() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Seller.Done() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
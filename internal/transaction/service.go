@@ -0,0 +1,581 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"rekber/ierr"
+	"rekber/internal/ledger"
+	"rekber/internal/outbox"
+	"rekber/internal/user"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	Save(ctx context.Context, t Transaction) error
+	GetByID(ctx context.Context, id uuid.UUID) (Transaction, error)
+	Update(ctx context.Context, t Transaction, lastStatus Status) error
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]Transaction, error)
+	// LockOverdue locks a transaction waiting for approval created before approvalCutoff or waiting
+	// for payment accepted before paymentCutoff, skipping transactions locked by other workers. It
+	// returns ierr.TransactionNotFound when there is none, it must be called within a transaction.
+	LockOverdue(ctx context.Context, approvalCutoff, paymentCutoff time.Time) (Transaction, error)
+	// LockDueReminder locks a transaction done by seller whose inspection started before cutoff and
+	// whose release reminder is not sent yet, skipping transactions locked by other workers. It
+	// returns ierr.TransactionNotFound when there is none, it must be called within a transaction.
+	LockDueReminder(ctx context.Context, cutoff time.Time) (Transaction, error)
+	// LockDueRelease locks a transaction done by seller whose inspection started before cutoff and
+	// whose release reminder is sent before remindedBefore, skipping transactions locked by other
+	// workers. It returns ierr.TransactionNotFound when there is none, it must be called within a
+	// transaction.
+	LockDueRelease(ctx context.Context, cutoff, remindedBefore time.Time) (Transaction, error)
+	// LockUndelivered locks a transaction done by seller before doneBefore whose inspection has not
+	// started, skipping transactions locked by other workers. It returns ierr.TransactionNotFound
+	// when there is none, it must be called within a transaction.
+	LockUndelivered(ctx context.Context, doneBefore time.Time) (Transaction, error)
+	// LockOverdueDispute locks a disputed transaction which is not responded nor escalated yet whose
+	// deadline is before now, skipping transactions locked by other workers. It returns
+	// ierr.TransactionNotFound when there is none, it must be called within a transaction.
+	LockOverdueDispute(ctx context.Context, now time.Time) (Transaction, error)
+	// ListEscalatedDisputes returns the disputed transactions which are escalated, oldest escalation first.
+	ListEscalatedDisputes(ctx context.Context) ([]Transaction, error)
+	// ListUnrecorded returns the transactions created before events were recorded which have no
+	// event yet.
+	ListUnrecorded(ctx context.Context) ([]Transaction, error)
+	// Project saves the transaction as folded from its events, whatever its row holds now.
+	Project(ctx context.Context, t Transaction) error
+}
+
+type UserRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (user.User, error)
+	GetByPhoneNumber(ctx context.Context, phoneNumber string) (user.User, error)
+}
+
+// Ledger records the money movement of transactions as double-entry journal entries.
+type Ledger interface {
+	Post(ctx context.Context, e ledger.Entry) error
+	TransactionLedger(ctx context.Context, transactionID uuid.UUID) (ledger.Response, error)
+}
+
+// Outbox publishes messages to other systems once the transaction they are enqueued in commits.
+type Outbox interface {
+	Enqueue(ctx context.Context, m outbox.Message) error
+}
+
+type Service struct {
+	repository         Repository
+	userRepository     UserRepository
+	paymentRepository  PaymentRepository
+	paymentProvider    PaymentProvider
+	transactor         Transactor
+	payoutRepository   PayoutRepository
+	disburser          Disburser
+	ledger             Ledger
+	notifier           Notifier
+	refundRepository   RefundRepository
+	shipmentRepository ShipmentRepository
+	courierTracker     CourierTracker
+	timelineRepository TimelineRepository
+	eventStore         EventStore
+	outbox             Outbox
+	deadlines          Deadlines
+	disputeWindow      time.Duration
+}
+
+func (s Service) Create(ctx context.Context, userID uuid.UUID, req CreateRequest) (Response, error) {
+	role, err := parseRole(req.Role)
+	if err != nil {
+		return Response{}, err
+	}
+
+	caller, err := s.userRepository.GetByID(ctx, userID)
+	if err != nil {
+		return Response{}, fmt.Errorf("failed to get caller by id: %w", err)
+	}
+
+	counterpart, err := s.userRepository.GetByPhoneNumber(ctx, req.CounterpartPhoneNumber)
+	if err != nil {
+		return Response{}, fmt.Errorf("failed to get counterpart by phone number: %w", err)
+	}
+
+	if counterpart.ID == caller.ID {
+		return Response{}, ierr.TransactionCounterpartNotValid{Reason: "buyer and seller cannot be the same user"}
+	}
+
+	items := toItems(req.Items)
+
+	var t Transaction
+	switch role {
+	case buyer:
+		t, err = newBuyer(caller).Create(newSeller(counterpart), items)
+	case seller:
+		t, err = newSeller(caller).Create(newBuyer(counterpart), items)
+	}
+	if err != nil {
+		return Response{}, fmt.Errorf("failed to create transaction: %w", err)
+	}
+
+	c := newCommand(role, caller)
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		created := newSnapshotEvent(t, eventCreated, t.CreatedAt)
+		created.Version = 1
+		if err := s.eventStore.Append(ctx, t.ID, 0, created); err != nil {
+			return fmt.Errorf("failed to append events: %w", err)
+		}
+
+		t.Version = created.Version
+		if err := s.repository.Save(ctx, t); err != nil {
+			return fmt.Errorf("failed to save transaction: %w", err)
+		}
+
+		if err := s.record(ctx, 0, t, create, c); err != nil {
+			return err
+		}
+
+		return s.publish(ctx, 0, t, created)
+	})
+	if err != nil {
+		return Response{}, err
+	}
+
+	return newResponse(t, c), nil
+}
+
+func (s Service) Get(ctx context.Context, userID, id uuid.UUID) (Response, error) {
+	t, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return Response{}, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	c, err := s.resolve(ctx, userID, t)
+	if err != nil {
+		return Response{}, err
+	}
+
+	return s.withDetails(ctx, newResponse(t, c))
+}
+
+func (s Service) List(ctx context.Context, userID uuid.UUID) ([]Response, error) {
+	caller, err := s.userRepository.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get caller by id: %w", err)
+	}
+
+	trxs, err := s.repository.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
+
+	ids := make([]uuid.UUID, 0, len(trxs))
+	for _, t := range trxs {
+		ids = append(ids, t.ID)
+	}
+
+	payouts, err := s.payoutRepository.ListPayouts(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payouts: %w", err)
+	}
+
+	payoutByTransactionID := make(map[uuid.UUID]Payout, len(payouts))
+	for _, p := range payouts {
+		payoutByTransactionID[p.TransactionID] = p
+	}
+
+	refunds, err := s.refundRepository.ListRefunds(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refunds: %w", err)
+	}
+
+	refundByTransactionID := make(map[uuid.UUID]Refund, len(refunds))
+	for _, r := range refunds {
+		refundByTransactionID[r.TransactionID] = r
+	}
+
+	shipments, err := s.shipmentRepository.ListShipments(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shipments: %w", err)
+	}
+
+	shipmentByTransactionID := make(map[uuid.UUID]Shipment, len(shipments))
+	for _, sh := range shipments {
+		shipmentByTransactionID[sh.TransactionID] = sh
+	}
+
+	result := make([]Response, 0, len(trxs))
+	for _, t := range trxs {
+		role := seller
+		if t.Buyer.ID == userID {
+			role = buyer
+		}
+
+		resp := newResponse(t, newCommand(role, caller))
+		if p, ok := payoutByTransactionID[t.ID]; ok {
+			resp.Payout = newPayoutResponse(p)
+		}
+		if r, ok := refundByTransactionID[t.ID]; ok {
+			resp.Refund = newRefundResponse(r)
+		}
+		if sh, ok := shipmentByTransactionID[t.ID]; ok {
+			resp.Shipment = newShipmentResponse(sh)
+		}
+
+		result = append(result, resp)
+	}
+
+	return result, nil
+}
+
+func (s Service) Accept(ctx context.Context, userID, id uuid.UUID) (Response, error) {
+	return s.act(ctx, userID, id, accept, 0, nil)
+}
+
+func (s Service) Reject(ctx context.Context, userID, id uuid.UUID, req RejectRequest) (Response, error) {
+	return s.act(ctx, userID, id, reject, 0, withReason(req.Reason))
+}
+
+// Done marks the transaction as done by seller, with the shipping info when the goods are shipped.
+func (s Service) Done(ctx context.Context, userID, id uuid.UUID, req DoneRequest) (Response, error) {
+	return s.act(ctx, userID, id, done, seller, func(_ Transaction, c *command) {
+		c.shipping = Shipping{
+			CourierCode: strings.ToLower(strings.TrimSpace(req.CourierCode)),
+			AirwayBill:  strings.TrimSpace(req.AirwayBill),
+		}
+	})
+}
+
+// Confirm confirms the transaction done by buyer, which completes the transaction.
+func (s Service) Confirm(ctx context.Context, userID, id uuid.UUID) (Response, error) {
+	return s.act(ctx, userID, id, done, buyer, nil)
+}
+
+// OpenDispute raises an issue by buyer, which holds the funds until an admin resolves it.
+func (s Service) OpenDispute(ctx context.Context, userID, id uuid.UUID, req DisputeRequest) (Response, error) {
+	return s.act(ctx, userID, id, dispute, buyer, func(_ Transaction, c *command) {
+		c.reason = req.Reason
+		c.window = s.disputeWindow
+	})
+}
+
+// RespondDispute gives the seller side of a dispute before its deadline.
+func (s Service) RespondDispute(ctx context.Context, userID, id uuid.UUID, req RespondDisputeRequest) (Response, error) {
+	return s.act(ctx, userID, id, respond, seller, withReason(req.Response))
+}
+
+// ResolveDispute settles a dispute by admin, the funds are refunded, released or split.
+func (s Service) ResolveDispute(ctx context.Context, userID, id uuid.UUID, req ResolveDisputeRequest) (Response, error) {
+	var a Action
+	switch req.Outcome {
+	case refund.String():
+		a = refund
+	case release.String():
+		a = release
+	case split.String():
+		a = split
+	default:
+		return Response{}, ierr.DisputeResolutionNotValid{Reason: "outcome should be refund, release or split"}
+	}
+
+	return s.act(ctx, userID, id, a, admin, func(t Transaction, c *command) {
+		c.reason = req.Note
+		if req.RefundAmount != 0 {
+			// refund is requested in the currency of the transaction
+			c.refund = NewMoney(req.RefundAmount, t.Breakdown.SellerNet.Currency)
+		}
+	})
+}
+
+func withReason(reason string) func(t Transaction, c *command) {
+	return func(_ Transaction, c *command) {
+		c.reason = reason
+	}
+}
+
+// act fires the action as the caller. When role is set, only caller with that role may fire it.
+// with sets the input of the action on the command.
+func (s Service) act(ctx context.Context, userID, id uuid.UUID, a Action, role Actors, with func(t Transaction, c *command)) (Response, error) {
+	t, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return Response{}, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	c, err := s.resolve(ctx, userID, t)
+	if err != nil {
+		return Response{}, err
+	}
+
+	if role != 0 && c.actor != role {
+		return Response{}, ierr.TransactionActionNotAllowed{Action: a.String(), Actor: c.actor.String()}
+	}
+
+	if with != nil {
+		with(t, &c)
+	}
+
+	var updated Transaction
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if updated, err = s.transition(ctx, t, a, c); err != nil {
+			return err
+		}
+
+		return s.createShipment(ctx, updated, c)
+	})
+	if err != nil {
+		return Response{}, err
+	}
+
+	return s.withDetails(ctx, newResponse(updated, c))
+}
+
+// transition fires the action on the transaction by the command, saves it with its event, records
+// it on the timeline and settles the escrow of a transaction which has just ended. It must be
+// called within a transaction.
+func (s Service) transition(ctx context.Context, t Transaction, a Action, c command) (Transaction, error) {
+	c.at = time.Now()
+	updated, err := fire(t, a, c)
+	if err != nil {
+		return Transaction{}, fmt.Errorf("failed to %s transaction: %w", a.String(), err)
+	}
+
+	if updated, err = s.save(ctx, t, updated, newEvent(updated, a, c)); err != nil {
+		return Transaction{}, err
+	}
+
+	if err := s.record(ctx, t.Status, updated, a, c); err != nil {
+		return Transaction{}, err
+	}
+
+	if err := s.settle(ctx, t.Status, updated); err != nil {
+		return Transaction{}, err
+	}
+
+	return updated, nil
+}
+
+// settle releases the escrow to seller on success, returns it to buyer on refund or splits it
+// between them when resolved.
+func (s Service) settle(ctx context.Context, lastStatus Status, updated Transaction) error {
+	if updated.Status == lastStatus {
+		return nil
+	}
+
+	b := updated.Breakdown
+	currency := string(b.ItemTotal.Currency)
+	switch updated.Status {
+	case success:
+		if err := s.ledger.Post(ctx, ledger.SuccessEntry(updated.ID, currency, b.SellerNet.Amount, b.EscrowFee.Amount)); err != nil {
+			return fmt.Errorf("failed to post success entry: %w", err)
+		}
+
+		return s.createPayout(ctx, updated)
+	case refunded:
+		if err := s.ledger.Post(ctx, ledger.RefundEntry(updated.ID, currency, updated.RefundAmount.Amount)); err != nil {
+			return fmt.Errorf("failed to post refund entry: %w", err)
+		}
+
+		return s.createRefund(ctx, updated)
+	case resolved:
+		if err := s.ledger.Post(ctx, ledger.ResolvedEntry(updated.ID, currency, updated.RefundAmount.Amount, updated.sellerAmount().Amount, b.EscrowFee.Amount)); err != nil {
+			return fmt.Errorf("failed to post resolved entry: %w", err)
+		}
+
+		if err := s.createPayout(ctx, updated); err != nil {
+			return err
+		}
+
+		return s.createRefund(ctx, updated)
+	}
+
+	return nil
+}
+
+// Ledger returns the ledger of the transaction to its buyer or seller.
+func (s Service) Ledger(ctx context.Context, userID, id uuid.UUID) (ledger.Response, error) {
+	t, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return ledger.Response{}, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	if _, err := s.resolve(ctx, userID, t); err != nil {
+		return ledger.Response{}, err
+	}
+
+	resp, err := s.ledger.TransactionLedger(ctx, t.ID)
+	if err != nil {
+		return ledger.Response{}, fmt.Errorf("failed to get transaction ledger: %w", err)
+	}
+
+	return resp, nil
+}
+
+// withDetails adds the payout, the refund and the shipment to the response.
+func (s Service) withDetails(ctx context.Context, resp Response) (Response, error) {
+	resp, err := s.withPayout(ctx, resp)
+	if err != nil {
+		return Response{}, err
+	}
+
+	resp, err = s.withRefund(ctx, resp)
+	if err != nil {
+		return Response{}, err
+	}
+
+	return s.withShipment(ctx, resp)
+}
+
+// withPayout adds the payout to the response when the transaction has one.
+func (s Service) withPayout(ctx context.Context, resp Response) (Response, error) {
+	p, err := s.payoutRepository.GetPayout(ctx, resp.ID)
+	if err != nil {
+		if errors.As(err, &ierr.PayoutNotFound{}) {
+			return resp, nil
+		}
+
+		return Response{}, fmt.Errorf("failed to get payout: %w", err)
+	}
+
+	resp.Payout = newPayoutResponse(p)
+	return resp, nil
+}
+
+// withRefund adds the refund to the response when the transaction has one.
+func (s Service) withRefund(ctx context.Context, resp Response) (Response, error) {
+	r, err := s.refundRepository.GetRefund(ctx, resp.ID)
+	if err != nil {
+		if errors.As(err, &ierr.RefundNotFound{}) {
+			return resp, nil
+		}
+
+		return Response{}, fmt.Errorf("failed to get refund: %w", err)
+	}
+
+	resp.Refund = newRefundResponse(r)
+	return resp, nil
+}
+
+// resolve returns the command of the caller as buyer or seller of the transaction, or as admin
+// for an admin who is neither. Other users get not found so the existence of a transaction is
+// not leaked to them.
+func (s Service) resolve(ctx context.Context, userID uuid.UUID, t Transaction) (command, error) {
+	caller, err := s.userRepository.GetByID(ctx, userID)
+	if err != nil {
+		return command{}, fmt.Errorf("failed to get caller by id: %w", err)
+	}
+
+	switch {
+	case userID == t.Buyer.ID:
+		return newCommand(buyer, caller), nil
+	case userID == t.Seller.ID:
+		return newCommand(seller, caller), nil
+	case caller.IsAdmin:
+		return newCommand(admin, caller), nil
+	default:
+		return command{}, ierr.TransactionNotFound{ID: t.ID}
+	}
+}
+
+func newCommand(role Actors, u user.User) command {
+	c := command{actor: role}
+	switch role {
+	case buyer:
+		c.buyer = newBuyer(u)
+	case seller:
+		c.seller = newSeller(u)
+	case admin:
+		c.admin = Admin{ID: u.ID}
+	}
+
+	return c
+}
+
+func newBuyer(u user.User) Buyer {
+	return Buyer{
+		ID:                    u.ID,
+		PhoneNumberVerifiedAt: u.PhoneNumberVerifiedAt,
+	}
+}
+
+func newSeller(u user.User) Seller {
+	return Seller{
+		ID:                    u.ID,
+		PhoneNumberVerifiedAt: u.PhoneNumberVerifiedAt,
+		BankAccount: BankAccount{
+			ID:         u.BankAccount.ID,
+			VerifiedAt: u.BankAccount.VerifiedAt,
+		},
+	}
+}
+
+func parseRole(role string) (Actors, error) {
+	switch role {
+	case buyer.String():
+		return buyer, nil
+	case seller.String():
+		return seller, nil
+	default:
+		return 0, ierr.TransactionRoleNotValid{Role: role}
+	}
+}
+
+func toItems(req []ItemRequest) []Item {
+	items := make([]Item, 0, len(req))
+	for _, item := range req {
+		items = append(items, Item{
+			Name:        item.Name,
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			Price:       NewMoney(item.Price.Amount, Currency(item.Price.Currency)),
+		})
+	}
+
+	return items
+}
+
+// Dependencies are what the service is built on. A single postgres repository implements most of
+// the repositories, they are separate interfaces so every part of the service names what it uses.
+type Dependencies struct {
+	Repository         Repository
+	UserRepository     UserRepository
+	PaymentRepository  PaymentRepository
+	PaymentProvider    PaymentProvider
+	Transactor         Transactor
+	PayoutRepository   PayoutRepository
+	Disburser          Disburser
+	Ledger             Ledger
+	Notifier           Notifier
+	RefundRepository   RefundRepository
+	ShipmentRepository ShipmentRepository
+	CourierTracker     CourierTracker
+	TimelineRepository TimelineRepository
+	EventStore         EventStore
+	Outbox             Outbox
+	// Deadlines caps the charges created for a transaction at its payment deadline.
+	Deadlines Deadlines
+	// DisputeWindow is how long the seller is given to respond to a dispute, defaultDisputeWindow
+	// when zero.
+	DisputeWindow time.Duration
+}
+
+func NewService(d Dependencies) *Service {
+	return &Service{
+		repository:         d.Repository,
+		userRepository:     d.UserRepository,
+		paymentRepository:  d.PaymentRepository,
+		paymentProvider:    d.PaymentProvider,
+		transactor:         d.Transactor,
+		payoutRepository:   d.PayoutRepository,
+		disburser:          d.Disburser,
+		ledger:             d.Ledger,
+		notifier:           d.Notifier,
+		refundRepository:   d.RefundRepository,
+		shipmentRepository: d.ShipmentRepository,
+		courierTracker:     d.CourierTracker,
+		timelineRepository: d.TimelineRepository,
+		eventStore:         d.EventStore,
+		outbox:             d.Outbox,
+		deadlines:          d.Deadlines,
+		disputeWindow:      d.DisputeWindow,
+	}
+}
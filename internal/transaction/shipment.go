@@ -0,0 +1,218 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"rekber/ierr"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const maxAirwayBillLength = 50
+
+// couriers are the courier codes shipments can be tracked with.
+var couriers = map[string]bool{
+	"jne":      true,
+	"jnt":      true,
+	"sicepat":  true,
+	"anteraja": true,
+	"pos":      true,
+	"tiki":     true,
+}
+
+type ShipmentStatus string
+
+const (
+	shipmentInTransit ShipmentStatus = "in_transit"
+	shipmentDelivered ShipmentStatus = "delivered"
+)
+
+// Shipping is the courier and airway bill the seller ships the goods with.
+type Shipping struct {
+	CourierCode string
+	AirwayBill  string
+}
+
+func (s Shipping) isZero() bool {
+	return s.CourierCode == "" && s.AirwayBill == ""
+}
+
+func (s Shipping) validate() error {
+	if !couriers[s.CourierCode] {
+		return ierr.ShipmentNotValid{Reason: "courier " + s.CourierCode + " is not supported"}
+	}
+
+	if s.AirwayBill == "" || len(s.AirwayBill) > maxAirwayBillLength {
+		return ierr.ShipmentNotValid{Reason: fmt.Sprintf("airway bill must be 1 to %d characters", maxAirwayBillLength)}
+	}
+
+	return nil
+}
+
+type TrackingEvent struct {
+	Status      string
+	Description string
+	Location    string
+	OccurredAt  time.Time
+}
+
+// Tracking is the progress of a shipment reported by the courier.
+type Tracking struct {
+	Events      []TrackingEvent
+	Delivered   bool
+	DeliveredAt time.Time
+}
+
+// Shipment follows the goods from the seller to the buyer, the buyer inspection window starts
+// once the courier reports them delivered.
+type Shipment struct {
+	ID            uuid.UUID
+	TransactionID uuid.UUID
+	Shipping
+	Status        ShipmentStatus
+	Events        []TrackingEvent
+	DeliveredAt   time.Time
+	LastCheckedAt time.Time
+	LastError     string
+	CreatedAt     time.Time
+}
+
+func newShipment(transactionID uuid.UUID, s Shipping) Shipment {
+	return Shipment{
+		ID:            uuid.New(),
+		TransactionID: transactionID,
+		Shipping:      s,
+		Status:        shipmentInTransit,
+		CreatedAt:     time.Now(),
+	}
+}
+
+func (s Shipment) track(tr Tracking) Shipment {
+	s.LastCheckedAt = time.Now()
+	s.LastError = ""
+	s.Events = tr.Events
+	if tr.Delivered {
+		s.Status = shipmentDelivered
+		s.DeliveredAt = tr.DeliveredAt
+	}
+
+	return s
+}
+
+type CourierTracker interface {
+	Track(ctx context.Context, s Shipping) (Tracking, error)
+}
+
+type ShipmentRepository interface {
+	SaveShipment(ctx context.Context, s Shipment) error
+	// GetShipment returns the shipment of the transaction, or ierr.ShipmentNotFound.
+	GetShipment(ctx context.Context, transactionID uuid.UUID) (Shipment, error)
+	ListShipments(ctx context.Context, transactionIDs []uuid.UUID) ([]Shipment, error)
+	// LockDueShipment locks a shipment in transit last checked before checkedBefore whose
+	// transaction is done by seller and still awaits the delivery, skipping shipments locked by
+	// other workers. It returns ierr.ShipmentNotFound when there is none, it
+	// must be called within a transaction.
+	LockDueShipment(ctx context.Context, checkedBefore time.Time) (Shipment, error)
+	UpdateShipment(ctx context.Context, s Shipment) error
+}
+
+func (s Service) createShipment(ctx context.Context, t Transaction, c command) error {
+	if c.shipping.isZero() {
+		return nil
+	}
+
+	if err := s.shipmentRepository.SaveShipment(ctx, newShipment(t.ID, c.shipping)); err != nil {
+		return fmt.Errorf("failed to save shipment: %w", err)
+	}
+
+	return nil
+}
+
+// TrackShipments asks the courier about every shipment in transit not checked within checkEvery
+// and returns how many were checked. A shipment reported delivered starts the inspection window
+// of its transaction. A failed check is recorded on the shipment and retried later.
+func (s Service) TrackShipments(ctx context.Context, checkEvery time.Duration) (int, error) {
+	// checkedBefore is fixed for the run, so every shipment is checked at most once even when
+	// checkEvery is zero.
+	checkedBefore := time.Now().Add(-checkEvery)
+	checked := 0
+	for {
+		err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			sh, err := s.shipmentRepository.LockDueShipment(ctx, checkedBefore)
+			if err != nil {
+				return err
+			}
+
+			tr, err := s.courierTracker.Track(ctx, sh.Shipping)
+			if err != nil {
+				sh.LastCheckedAt = time.Now()
+				sh.LastError = err.Error()
+				return s.updateShipment(ctx, sh)
+			}
+
+			sh = sh.track(tr)
+			if err := s.updateShipment(ctx, sh); err != nil {
+				return err
+			}
+
+			if sh.Status != shipmentDelivered {
+				return nil
+			}
+
+			return s.deliver(ctx, sh.TransactionID, tr.DeliveredAt)
+		})
+		if errors.As(err, &ierr.ShipmentNotFound{}) {
+			return checked, nil
+		}
+
+		if err != nil {
+			return checked, err
+		}
+
+		checked++
+	}
+}
+
+func (s Service) updateShipment(ctx context.Context, sh Shipment) error {
+	if err := s.shipmentRepository.UpdateShipment(ctx, sh); err != nil {
+		return fmt.Errorf("failed to update shipment: %w", err)
+	}
+
+	return nil
+}
+
+// deliver starts the inspection window from deliveredAt, unless the transaction has moved on
+// already, e.g. the buyer confirmed or disputed or the delivery timeout started the inspection
+// before the courier reported the delivery.
+func (s Service) deliver(ctx context.Context, transactionID uuid.UUID, deliveredAt time.Time) error {
+	t, err := s.repository.GetByID(ctx, transactionID)
+	if err != nil {
+		return fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	if t.Status != doneBySeller || !t.InspectionStartedAt.IsZero() {
+		return nil
+	}
+
+	c := System{}.command()
+	c.deliveredAt = deliveredAt
+	_, err = s.transition(ctx, t, deliver, c)
+	return err
+}
+
+// withShipment adds the shipment to the response when the transaction has one.
+func (s Service) withShipment(ctx context.Context, resp Response) (Response, error) {
+	sh, err := s.shipmentRepository.GetShipment(ctx, resp.ID)
+	if err != nil {
+		if errors.As(err, &ierr.ShipmentNotFound{}) {
+			return resp, nil
+		}
+
+		return Response{}, fmt.Errorf("failed to get shipment: %w", err)
+	}
+
+	resp.Shipment = newShipmentResponse(sh)
+	return resp, nil
+}
@@ -0,0 +1,243 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"rekber/ierr"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/google/uuid"
+)
+
+type fakeShipmentRepository struct {
+	ShipmentRepository
+	shipments []Shipment
+	trxs      map[uuid.UUID]Transaction
+}
+
+func (f *fakeShipmentRepository) SaveShipment(ctx context.Context, s Shipment) error {
+	f.shipments = append(f.shipments, s)
+	return nil
+}
+
+func (f *fakeShipmentRepository) GetShipment(ctx context.Context, transactionID uuid.UUID) (Shipment, error) {
+	for _, s := range f.shipments {
+		if s.TransactionID == transactionID {
+			return s, nil
+		}
+	}
+
+	return Shipment{}, ierr.ShipmentNotFound{TransactionID: transactionID}
+}
+
+func (f *fakeShipmentRepository) LockDueShipment(ctx context.Context, checkedBefore time.Time) (Shipment, error) {
+	for _, s := range f.shipments {
+		t := f.trxs[s.TransactionID]
+		if s.Status == shipmentInTransit && s.LastCheckedAt.Before(checkedBefore) && t.Status == doneBySeller && t.InspectionStartedAt.IsZero() {
+			return s, nil
+		}
+	}
+
+	return Shipment{}, ierr.ShipmentNotFound{}
+}
+
+func (f *fakeShipmentRepository) UpdateShipment(ctx context.Context, s Shipment) error {
+	for i := range f.shipments {
+		if f.shipments[i].ID == s.ID {
+			f.shipments[i] = s
+		}
+	}
+
+	return nil
+}
+
+type fakeCourierTracker struct {
+	trackings map[string]Tracking
+}
+
+func (f fakeCourierTracker) Track(ctx context.Context, s Shipping) (Tracking, error) {
+	tr, ok := f.trackings[s.AirwayBill]
+	if !ok {
+		return Tracking{}, errors.New("airway bill not found")
+	}
+
+	return tr, nil
+}
+
+func TestSystem_Deliver(t *testing.T) {
+	timeNow := time.Date(2023, 9, 30, 21, 0, 0, 0, time.UTC)
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time {
+		return timeNow
+	})
+	defer patches.Reset()
+
+	trxUUID := uuid.New()
+
+	tests := []struct {
+		name        string
+		t           Transaction
+		deliveredAt time.Time
+		want        Transaction
+		wantErr     bool
+	}{
+		{
+			name:        "delivery starts the inspection when the goods are delivered",
+			t:           Transaction{ID: trxUUID, Status: doneBySeller},
+			deliveredAt: timeNow.Add(-time.Hour),
+			want:        Transaction{ID: trxUUID, Status: doneBySeller, InspectionStartedAt: timeNow.Add(-time.Hour)},
+		},
+		{
+			name: "delivery without the delivery time starts the inspection now",
+			t:    Transaction{ID: trxUUID, Status: doneBySeller},
+			want: Transaction{ID: trxUUID, Status: doneBySeller, InspectionStartedAt: timeNow},
+		},
+		{
+			name:    "inspection has started already",
+			t:       Transaction{ID: trxUUID, Status: doneBySeller, InspectionStartedAt: timeNow.Add(-time.Hour)},
+			wantErr: true,
+		},
+		{
+			name:    "transaction is not done by seller",
+			t:       Transaction{ID: trxUUID, Status: paid},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := System{}.Deliver(tt.t, tt.deliveredAt)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("System.Deliver() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("System.Deliver() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_TrackShipments(t *testing.T) {
+	timeNow := time.Date(2023, 9, 30, 21, 0, 0, 0, time.UTC)
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time {
+		return timeNow
+	})
+	defer patches.Reset()
+
+	delivered := uuid.New()
+	inTransit := uuid.New()
+	confirmed := uuid.New()
+	inspecting := uuid.New()
+	unknown := uuid.New()
+	checked := uuid.New()
+
+	deliveredAt := timeNow.Add(-time.Hour)
+	events := []TrackingEvent{{Status: "picked_up", Description: "picked up by courier", OccurredAt: timeNow.Add(-24 * time.Hour)}}
+	trackings := map[string]Tracking{
+		"DELIVERED": {Events: events, Delivered: true, DeliveredAt: deliveredAt},
+		"TRANSIT":   {Events: events},
+	}
+
+	trxs := func() map[uuid.UUID]Transaction {
+		return map[uuid.UUID]Transaction{
+			delivered:  {ID: delivered, Status: doneBySeller},
+			inTransit:  {ID: inTransit, Status: doneBySeller},
+			confirmed:  {ID: confirmed, Status: success},
+			inspecting: {ID: inspecting, Status: doneBySeller, InspectionStartedAt: timeNow.Add(-2 * time.Hour)},
+			unknown:    {ID: unknown, Status: doneBySeller},
+			checked:    {ID: checked, Status: doneBySeller},
+		}
+	}
+	shipment := func(id uuid.UUID, airwayBill string, lastCheckedAt time.Time) Shipment {
+		return Shipment{ID: uuid.New(), TransactionID: id, Shipping: Shipping{CourierCode: "jne", AirwayBill: airwayBill}, Status: shipmentInTransit, LastCheckedAt: lastCheckedAt}
+	}
+	shipments := func() []Shipment {
+		return []Shipment{
+			shipment(delivered, "DELIVERED", time.Time{}),
+			shipment(inTransit, "TRANSIT", timeNow.Add(-time.Hour)),
+			shipment(confirmed, "DELIVERED", time.Time{}),
+			shipment(inspecting, "DELIVERED", time.Time{}),
+			shipment(unknown, "UNKNOWN", time.Time{}),
+			shipment(checked, "DELIVERED", timeNow.Add(-time.Minute)),
+		}
+	}
+
+	tests := []struct {
+		name           string
+		checkEvery     time.Duration
+		want           int
+		wantInspection map[uuid.UUID]time.Time
+		wantStatus     map[uuid.UUID]ShipmentStatus
+		wantChecked    []uuid.UUID
+	}{
+		{
+			name:           "shipments not checked recently of transactions awaiting delivery are checked",
+			checkEvery:     30 * time.Minute,
+			want:           3,
+			wantInspection: map[uuid.UUID]time.Time{delivered: deliveredAt, inspecting: timeNow.Add(-2 * time.Hour)},
+			wantStatus:     map[uuid.UUID]ShipmentStatus{delivered: shipmentDelivered},
+			wantChecked:    []uuid.UUID{delivered, inTransit, unknown},
+		},
+		{
+			name:           "zero check every checks every shipment once",
+			checkEvery:     0,
+			want:           4,
+			wantInspection: map[uuid.UUID]time.Time{delivered: deliveredAt, inspecting: timeNow.Add(-2 * time.Hour), checked: deliveredAt},
+			wantStatus:     map[uuid.UUID]ShipmentStatus{delivered: shipmentDelivered, checked: shipmentDelivered},
+			wantChecked:    []uuid.UUID{delivered, inTransit, unknown, checked},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepository{trxs: trxs()}
+			shipmentRepo := &fakeShipmentRepository{shipments: shipments(), trxs: repo.trxs}
+			s := Service{
+				repository:         repo,
+				transactor:         fakeTransactor{},
+				timelineRepository: &fakeTimelineRepository{},
+				eventStore:         &fakeEventStore{},
+				outbox:             &fakeOutbox{},
+				shipmentRepository: shipmentRepo,
+				courierTracker:     fakeCourierTracker{trackings: trackings},
+			}
+
+			got, err := s.TrackShipments(context.Background(), tt.checkEvery)
+			if err != nil {
+				t.Fatalf("Service.TrackShipments() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Service.TrackShipments() = %v, want %v", got, tt.want)
+			}
+
+			for id, trx := range repo.trxs {
+				if !trx.InspectionStartedAt.Equal(tt.wantInspection[id]) {
+					t.Errorf("transaction %v inspection started at %v, want %v", id, trx.InspectionStartedAt, tt.wantInspection[id])
+				}
+			}
+
+			wantChecked := map[uuid.UUID]bool{}
+			for _, id := range tt.wantChecked {
+				wantChecked[id] = true
+			}
+			for _, sh := range shipmentRepo.shipments {
+				wantStatus, ok := tt.wantStatus[sh.TransactionID]
+				if !ok {
+					wantStatus = shipmentInTransit
+				}
+				if sh.Status != wantStatus {
+					t.Errorf("shipment of %v = %v, want %v", sh.TransactionID, sh.Status, wantStatus)
+				}
+
+				if wantChecked[sh.TransactionID] != sh.LastCheckedAt.Equal(timeNow) {
+					t.Errorf("shipment of %v last checked at %v", sh.TransactionID, sh.LastCheckedAt)
+				}
+
+				wantError := sh.TransactionID == unknown
+				if wantError != (sh.LastError != "") {
+					t.Errorf("shipment of %v last error = %q", sh.TransactionID, sh.LastError)
+				}
+			}
+		})
+	}
+}
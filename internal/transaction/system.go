@@ -0,0 +1,40 @@
+package transaction
+
+import "time"
+
+// System is the actor for transitions which are not triggered by buyer or
+// seller, e.g. expiring a transaction that is not paid in time.
+type System struct{}
+
+// Pay marks the transaction paid once the payment provider confirms the buyer payment.
+func (s System) Pay(t Transaction) (Transaction, error) {
+	return fire(t, pay, s.command())
+}
+
+// Expire ends a transaction which is not answered or paid before its deadline.
+func (s System) Expire(t Transaction, reason string) (Transaction, error) {
+	c := s.command()
+	c.reason = reason
+
+	return fire(t, expire, c)
+}
+
+// Release completes a transaction the buyer did not confirm within the inspection period.
+func (s System) Release(t Transaction) (Transaction, error) {
+	return fire(t, release, s.command())
+}
+
+// Deliver starts the inspection period from deliveredAt once the courier delivers the goods to the
+// buyer, or from now when deliveredAt is zero.
+func (s System) Deliver(t Transaction, deliveredAt time.Time) (Transaction, error) {
+	c := s.command()
+	c.deliveredAt = deliveredAt
+
+	return fire(t, deliver, c)
+}
+
+func (s System) command() command {
+	return command{
+		actor: system,
+	}
+}
@@ -0,0 +1,160 @@
+package transaction
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/google/uuid"
+)
+
+func TestSystem_Pay(t *testing.T) {
+	trxUUID := uuid.New()
+
+	paidAt := time.Now()
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time {
+		return paidAt
+	})
+	defer patches.Reset()
+
+	type args struct {
+		t Transaction
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    Transaction
+		wantErr bool
+	}{
+		{
+			name: "payment confirmed for transaction waiting for payment",
+			args: args{
+				t: Transaction{
+					ID:     trxUUID,
+					Status: waitingForPayment,
+				},
+			},
+			want: Transaction{
+				ID:     trxUUID,
+				Status: paid,
+				PaidAt: paidAt,
+			},
+			wantErr: false,
+		},
+		{
+			name: "transaction is not accepted yet",
+			args: args{
+				t: Transaction{
+					ID:     trxUUID,
+					Status: waitingForApproval,
+				},
+			},
+			want:    Transaction{},
+			wantErr: true,
+		},
+		{
+			name: "transaction is paid already",
+			args: args{
+				t: Transaction{
+					ID:     trxUUID,
+					Status: paid,
+				},
+			},
+			want:    Transaction{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := System{}.Pay(tt.args.t)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("System.Pay() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("System.Pay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSystem_Expire(t *testing.T) {
+	trxUUID := uuid.New()
+
+	expiredAt := time.Now()
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time {
+		return expiredAt
+	})
+	defer patches.Reset()
+
+	type args struct {
+		t      Transaction
+		reason string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    Transaction
+		wantErr bool
+	}{
+		{
+			name: "transaction waiting for approval is expired",
+			args: args{
+				t: Transaction{
+					ID:     trxUUID,
+					Status: waitingForApproval,
+				},
+				reason: approvalExpiredReason,
+			},
+			want: Transaction{
+				ID:            trxUUID,
+				Status:        expired,
+				ExpiredAt:     expiredAt,
+				ExpiredReason: approvalExpiredReason,
+			},
+			wantErr: false,
+		},
+		{
+			name: "transaction waiting for payment is expired",
+			args: args{
+				t: Transaction{
+					ID:     trxUUID,
+					Status: waitingForPayment,
+				},
+				reason: paymentExpiredReason,
+			},
+			want: Transaction{
+				ID:            trxUUID,
+				Status:        expired,
+				ExpiredAt:     expiredAt,
+				ExpiredReason: paymentExpiredReason,
+			},
+			wantErr: false,
+		},
+		{
+			name: "paid transaction cannot be expired",
+			args: args{
+				t: Transaction{
+					ID:     trxUUID,
+					Status: paid,
+				},
+				reason: paymentExpiredReason,
+			},
+			want:    Transaction{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := System{}.Expire(tt.args.t, tt.args.reason)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("System.Expire() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("System.Expire() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package transaction
+
+import (
+	"context"
+	"fmt"
+	"rekber/requestid"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TimelineEvent records a transition of a transaction, events are never changed once recorded.
+type TimelineEvent struct {
+	ID            uuid.UUID
+	TransactionID uuid.UUID
+	// From is zero for the event which creates the transaction.
+	From   Status
+	To     Status
+	Action Action
+	Actor  Actors
+	// ActorUserID is nil when the actor is system.
+	ActorUserID uuid.UUID
+	Reason      string
+	// RequestID is the request which fired the action, it is empty for background jobs.
+	RequestID string
+	CreatedAt time.Time
+}
+
+type TimelineRepository interface {
+	SaveTimelineEvent(ctx context.Context, e TimelineEvent) error
+	// ListTimelineEvents returns the events of the transaction, oldest first.
+	ListTimelineEvents(ctx context.Context, transactionID uuid.UUID) ([]TimelineEvent, error)
+}
+
+func newTimelineEvent(ctx context.Context, from Status, to Transaction, a Action, c command) TimelineEvent {
+	return TimelineEvent{
+		ID:            uuid.New(),
+		TransactionID: to.ID,
+		From:          from,
+		To:            to.Status,
+		Action:        a,
+		Actor:         c.actor,
+		ActorUserID:   c.userID(),
+		Reason:        c.reason,
+		RequestID:     requestid.FromContext(ctx),
+		CreatedAt:     time.Now(),
+	}
+}
+
+func (s Service) record(ctx context.Context, from Status, to Transaction, a Action, c command) error {
+	if err := s.timelineRepository.SaveTimelineEvent(ctx, newTimelineEvent(ctx, from, to, a, c)); err != nil {
+		return fmt.Errorf("failed to save timeline event: %w", err)
+	}
+
+	return nil
+}
+
+// Timeline returns every transition of the transaction to its buyer, its seller or an admin.
+func (s Service) Timeline(ctx context.Context, userID, id uuid.UUID) ([]TimelineEventResponse, error) {
+	t, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	if _, err := s.resolve(ctx, userID, t); err != nil {
+		return nil, err
+	}
+
+	events, err := s.timelineRepository.ListTimelineEvents(ctx, t.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list timeline events: %w", err)
+	}
+
+	result := make([]TimelineEventResponse, 0, len(events))
+	for _, e := range events {
+		result = append(result, newTimelineEventResponse(e))
+	}
+
+	return result, nil
+}
@@ -0,0 +1,152 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"rekber/ierr"
+	"rekber/requestid"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/google/uuid"
+)
+
+type fakeTimelineRepository struct {
+	events []TimelineEvent
+}
+
+func (f *fakeTimelineRepository) SaveTimelineEvent(ctx context.Context, e TimelineEvent) error {
+	f.events = append(f.events, e)
+	return nil
+}
+
+func (f *fakeTimelineRepository) ListTimelineEvents(ctx context.Context, transactionID uuid.UUID) ([]TimelineEvent, error) {
+	var events []TimelineEvent
+	for _, e := range f.events {
+		if e.TransactionID == transactionID {
+			events = append(events, e)
+		}
+	}
+
+	return events, nil
+}
+
+func TestService_act_recordsTimelineEvent(t *testing.T) {
+	timeNow := time.Date(2023, 9, 30, 21, 0, 0, 0, time.UTC)
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time {
+		return timeNow
+	})
+	defer patches.Reset()
+
+	trxUUID := uuid.New()
+	buyerUUID := uuid.New()
+	sellerUUID := uuid.New()
+	ctx := requestid.NewContext(context.Background(), "request-1")
+
+	repo := &fakeRepository{trxs: map[uuid.UUID]Transaction{
+		trxUUID: {ID: trxUUID, Buyer: Buyer{ID: buyerUUID}, Seller: Seller{ID: sellerUUID}, CreatedBy: buyer, Status: waitingForApproval},
+	}}
+	timelineRepo := &fakeTimelineRepository{}
+	s := Service{
+		repository:         repo,
+		userRepository:     fakeUserRepository{},
+		payoutRepository:   &fakePayoutRepository{},
+		refundRepository:   &fakeRefundRepository{},
+		shipmentRepository: &fakeShipmentRepository{},
+		transactor:         fakeTransactor{},
+		timelineRepository: timelineRepo,
+		eventStore:         &fakeEventStore{},
+		outbox:             &fakeOutbox{},
+	}
+
+	if _, err := s.Reject(ctx, sellerUUID, trxUUID, RejectRequest{Reason: "out of stock"}); err != nil {
+		t.Fatalf("Service.Reject() error = %v", err)
+	}
+
+	if len(timelineRepo.events) != 1 {
+		t.Fatalf("timeline events = %v, want 1", len(timelineRepo.events))
+	}
+
+	got := timelineRepo.events[0]
+	got.ID = uuid.Nil
+	want := TimelineEvent{
+		TransactionID: trxUUID,
+		From:          waitingForApproval,
+		To:            rejected,
+		Action:        reject,
+		Actor:         seller,
+		ActorUserID:   sellerUUID,
+		Reason:        "out of stock",
+		RequestID:     "request-1",
+		CreatedAt:     timeNow,
+	}
+	if got != want {
+		t.Errorf("timeline event = %v, want %v", got, want)
+	}
+}
+
+func TestService_Timeline(t *testing.T) {
+	trxUUID := uuid.New()
+	buyerUUID := uuid.New()
+	adminUUID := uuid.New()
+
+	events := []TimelineEvent{
+		{ID: uuid.New(), TransactionID: trxUUID, To: waitingForApproval, Action: create, Actor: buyer, ActorUserID: buyerUUID},
+		{ID: uuid.New(), TransactionID: trxUUID, From: waitingForApproval, To: expired, Action: expire, Actor: system, Reason: approvalExpiredReason},
+		{ID: uuid.New(), TransactionID: uuid.New(), To: waitingForApproval, Action: create, Actor: buyer, ActorUserID: buyerUUID},
+	}
+
+	tests := []struct {
+		name    string
+		userID  uuid.UUID
+		want    []string
+		wantErr error
+	}{
+		{
+			name:   "buyer sees every transition of the transaction",
+			userID: buyerUUID,
+			want:   []string{"create", "expire"},
+		},
+		{
+			name:   "admin sees the timeline of any transaction",
+			userID: adminUUID,
+			want:   []string{"create", "expire"},
+		},
+		{
+			name:    "other user does not find the transaction",
+			userID:  uuid.New(),
+			wantErr: ierr.TransactionNotFound{ID: trxUUID},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Service{
+				repository: &fakeRepository{trxs: map[uuid.UUID]Transaction{
+					trxUUID: {ID: trxUUID, Buyer: Buyer{ID: buyerUUID}, Seller: Seller{ID: uuid.New()}, Status: expired},
+				}},
+				userRepository:     fakeUserRepository{admins: map[uuid.UUID]bool{adminUUID: true}},
+				timelineRepository: &fakeTimelineRepository{events: events},
+				eventStore:         &fakeEventStore{},
+				outbox:             &fakeOutbox{},
+			}
+
+			got, err := s.Timeline(context.Background(), tt.userID, trxUUID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Service.Timeline() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("Service.Timeline() = %v, want %v", got, tt.want)
+			}
+			for i, e := range got {
+				if e.Action != tt.want[i] {
+					t.Errorf("Service.Timeline()[%d].Action = %v, want %v", i, e.Action, tt.want[i])
+				}
+			}
+			if len(got) > 0 && (got[0].ActorUserID == nil || got[1].ActorUserID != nil || got[0].From != "") {
+				t.Errorf("Service.Timeline() = %v, want actor user id of buyer only and no from status on create", got)
+			}
+		})
+	}
+}
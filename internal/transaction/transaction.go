@@ -11,8 +11,25 @@ type Actors int
 const (
 	buyer Actors = iota + 1
 	seller
+	system
+	admin
 )
 
+func (a Actors) String() string {
+	switch a {
+	case buyer:
+		return "buyer"
+	case seller:
+		return "seller"
+	case system:
+		return "system"
+	case admin:
+		return "admin"
+	default:
+		return ""
+	}
+}
+
 type Status int
 
 const (
@@ -28,6 +45,11 @@ const (
 
 	// transfer to seller
 	success // also means done by buyer
+
+	// dispute raised by buyer before confirming, settled by admin
+	disputed
+	refunded // whole payment is returned to buyer
+	resolved // payment is split between buyer and seller
 )
 
 func (s Status) String() string {
@@ -46,6 +68,12 @@ func (s Status) String() string {
 		return "done by seller"
 	case success:
 		return "success"
+	case disputed:
+		return "disputed"
+	case refunded:
+		return "refunded"
+	case resolved:
+		return "resolved"
 	default:
 		return ""
 	}
@@ -58,6 +86,10 @@ type Transaction struct {
 	Seller Seller
 	Buyer  Buyer
 
+	// Amount information
+	Items     []Item
+	Breakdown Breakdown
+
 	// Creation information
 	CreatedBy Actors
 	CreatedAt time.Time
@@ -74,25 +106,57 @@ type Transaction struct {
 	// Payment information
 	PaidAt time.Time
 
+	// Expired information
+	ExpiredAt     time.Time
+	ExpiredReason string
+
 	// Done information
 	SuccessAt      time.Time
 	DoneBySellerAt time.Time
+	// SuccessBy is buyer when the buyer confirms, or system when the funds are released
+	// automatically after the inspection period.
+	SuccessBy Actors
+	// InspectionStartedAt is when the buyer inspection period starts, it is when the seller marks
+	// the transaction done, or when the courier delivers the goods if they are shipped.
+	InspectionStartedAt time.Time
+	// ReleaseRemindedAt is when both parties were told the funds are about to be released.
+	ReleaseRemindedAt time.Time
+
+	// Dispute information
+	DisputedAt         time.Time
+	DisputeReason      string
+	DisputeDeadline    time.Time // seller is expected to respond before it
+	DisputeResponse    string
+	DisputeRespondedAt time.Time
+	// DisputeEscalatedAt is when the dispute was handed to admins since the seller did not respond
+	// before the deadline.
+	DisputeEscalatedAt time.Time
+
+	// Resolution information
+	ResolvedAt     time.Time
+	ResolvedBy     uuid.UUID // admin who resolved the dispute
+	ResolutionNote string
+	// RefundAmount is returned to buyer, it is the item total when refunded and part of the
+	// seller net when resolved.
+	RefundAmount Money
 
 	// State information
 	Status Status
+	// Version is the number of events the transaction is folded from.
+	Version int
+}
+
+// sellerAmount is the money released to seller, the seller net minus what is refunded to buyer.
+func (t Transaction) sellerAmount() Money {
+	return NewMoney(t.Breakdown.SellerNet.Amount-t.RefundAmount.Amount, t.Breakdown.SellerNet.Currency)
 }
 
 func (t Transaction) VerifyLastStatus(updated Status) bool {
-	switch t.Status {
-	case waitingForApproval:
-		return (updated == waitingForPayment) || (updated == rejected)
-	case waitingForPayment:
-		return (updated == paid) || (updated == expired)
-	case paid:
-		return updated == doneBySeller
-	case doneBySeller:
-		return updated == success
-	default:
-		return false
+	for _, tr := range transitions {
+		if tr.from == t.Status && tr.to == updated {
+			return true
+		}
 	}
+
+	return false
 }
@@ -121,6 +121,36 @@ func TestTransaction_VerifyLastStatus(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name: "status is done by seller, next to disputed",
+			fields: fields{
+				Status: doneBySeller,
+			},
+			args: args{
+				update: disputed,
+			},
+			want: true,
+		},
+		{
+			name: "status is disputed, next to resolved",
+			fields: fields{
+				Status: disputed,
+			},
+			args: args{
+				update: resolved,
+			},
+			want: true,
+		},
+		{
+			name: "status is success, next to disputed",
+			fields: fields{
+				Status: success,
+			},
+			args: args{
+				update: disputed,
+			},
+			want: false,
+		},
 		{
 			name: "status is done by seller, next to paid",
 			fields: fields{
@@ -208,6 +238,21 @@ func TestStatus_String(t *testing.T) {
 			s:    success,
 			want: "success",
 		},
+		{
+			name: "disputed",
+			s:    disputed,
+			want: "disputed",
+		},
+		{
+			name: "refunded",
+			s:    refunded,
+			want: "refunded",
+		},
+		{
+			name: "resolved",
+			s:    resolved,
+			want: "resolved",
+		},
 		{
 			name: "unknown status",
 			s:    0,
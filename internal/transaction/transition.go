@@ -0,0 +1,402 @@
+package transaction
+
+import (
+	"rekber/ierr"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// defaultDisputeWindow is how long the seller is given to respond to a dispute opened without a
+// window, like the disputes opened before the window was recorded on their event.
+const defaultDisputeWindow = 72 * time.Hour
+
+type Action int
+
+const (
+	accept Action = iota + 1
+	reject
+	pay
+	expire
+	done
+	release
+	dispute
+	respond
+	refund
+	split
+	deliver
+	create
+)
+
+func (a Action) String() string {
+	switch a {
+	case accept:
+		return "accept"
+	case reject:
+		return "reject"
+	case pay:
+		return "pay"
+	case expire:
+		return "expire"
+	case done:
+		return "done"
+	case release:
+		return "release"
+	case dispute:
+		return "dispute"
+	case respond:
+		return "respond"
+	case refund:
+		return "refund"
+	case split:
+		return "split"
+	case deliver:
+		return "deliver"
+	case create:
+		return "create"
+	default:
+		return ""
+	}
+}
+
+// command carries the actor who fires an action and the input of the action.
+type command struct {
+	actor    Actors
+	buyer    Buyer
+	seller   Seller
+	admin    Admin
+	reason   string
+	refund   Money
+	shipping Shipping
+	// window is how long the seller is given to respond to the dispute being opened.
+	window time.Duration
+	// deliveredAt is when the courier delivered the goods, zero when it is not reported.
+	deliveredAt time.Time
+	// at is when the action is fired, it is set by fire unless the action is replayed from its event.
+	at time.Time
+}
+
+// userID returns the user behind the actor, nil for system.
+func (c command) userID() uuid.UUID {
+	switch c.actor {
+	case buyer:
+		return c.buyer.ID
+	case seller:
+		return c.seller.ID
+	case admin:
+		return c.admin.ID
+	default:
+		return uuid.Nil
+	}
+}
+
+// guard must pass before the transition is applied.
+type guard func(t Transaction, c command) error
+
+// hook is the side effect applied to the transaction after the status is moved.
+type hook func(t *Transaction, c command)
+
+type transition struct {
+	from   Status
+	action Action
+	actor  Actors
+	to     Status
+	guards []guard
+	// validators check the input of the action, unlike guards they do not decide whether the
+	// action is available.
+	validators []guard
+	hook       hook
+}
+
+// transitions is the single source of truth of the transaction state machine,
+// every actor method is fired through this table.
+var transitions = []transition{
+	// transaction created by buyer is answered by seller
+	{from: waitingForApproval, action: accept, actor: seller, to: waitingForPayment, guards: []guard{sellerIsEligible, createdBy(buyer)}, hook: markAccepted},
+	{from: waitingForApproval, action: reject, actor: seller, to: rejected, guards: []guard{createdBy(buyer)}, hook: markRejected},
+
+	// transaction created by seller is answered by buyer
+	{from: waitingForApproval, action: accept, actor: buyer, to: waitingForPayment, guards: []guard{buyerIsEligible, createdBy(seller)}, hook: markAccepted},
+	{from: waitingForApproval, action: reject, actor: buyer, to: rejected, guards: []guard{createdBy(seller)}, hook: markRejected},
+
+	// transaction which is not answered within the approval window
+	{from: waitingForApproval, action: expire, actor: system, to: expired, hook: markExpired},
+
+	// payment, buyer pays through the payment provider which confirms it as system
+	{from: waitingForPayment, action: pay, actor: system, to: paid, hook: markPaid},
+	{from: waitingForPayment, action: expire, actor: system, to: expired, hook: markExpired},
+
+	// fulfillment
+	{from: paid, action: done, actor: seller, to: doneBySeller, guards: []guard{sellerIsEligible}, validators: []guard{shippingIsValid}, hook: markDoneBySeller},
+	{from: doneBySeller, action: deliver, actor: system, to: doneBySeller, guards: []guard{awaitingDelivery}, hook: markDelivered},
+	{from: doneBySeller, action: done, actor: buyer, to: success, guards: []guard{buyerIsEligible}, hook: markSuccess},
+
+	// buyer who does not confirm within the inspection period
+	{from: doneBySeller, action: release, actor: system, to: success, hook: markSuccess},
+
+	// dispute, buyer raises an issue instead of confirming and admin settles it
+	{from: paid, action: dispute, actor: buyer, to: disputed, guards: []guard{buyerIsEligible}, validators: []guard{reasonIsGiven}, hook: markDisputed},
+	{from: doneBySeller, action: dispute, actor: buyer, to: disputed, guards: []guard{buyerIsEligible}, validators: []guard{reasonIsGiven}, hook: markDisputed},
+	{from: disputed, action: respond, actor: seller, to: disputed, guards: []guard{notRespondedYet, beforeDisputeDeadline}, validators: []guard{reasonIsGiven}, hook: markDisputeResponded},
+	{from: disputed, action: refund, actor: admin, to: refunded, hook: markRefunded},
+	{from: disputed, action: release, actor: admin, to: success, hook: markReleased},
+	{from: disputed, action: split, actor: admin, to: resolved, validators: []guard{refundIsPartial}, hook: markSplit},
+}
+
+func buyerIsEligible(_ Transaction, c command) error {
+	if !c.buyer.IsEligible() {
+		return ierr.BuyerIsNotEligible{ID: c.buyer.ID, Reason: "phone number is not verified yet"}
+	}
+
+	return nil
+}
+
+func sellerIsEligible(_ Transaction, c command) error {
+	if !c.seller.IsEligible() {
+		return ierr.SellerIsNotEligible{ID: c.seller.ID, Reason: c.seller.notEligibleReason()}
+	}
+
+	return nil
+}
+
+func createdBy(a Actors) guard {
+	return func(t Transaction, _ command) error {
+		if t.CreatedBy == a {
+			return nil
+		}
+
+		if a == seller {
+			return ierr.TransactionNotCreatedBySeller{}
+		}
+
+		return ierr.TransactionNotCreatedByBuyer{}
+	}
+}
+
+func reasonIsGiven(_ Transaction, c command) error {
+	if strings.TrimSpace(c.reason) == "" {
+		return ierr.DisputeNotValid{Reason: "reason is required"}
+	}
+
+	return nil
+}
+
+// shippingIsValid allows marking done without shipping, e.g. for goods handed over in person.
+func shippingIsValid(_ Transaction, c command) error {
+	if c.shipping.isZero() {
+		return nil
+	}
+
+	return c.shipping.validate()
+}
+
+func awaitingDelivery(t Transaction, _ command) error {
+	if !t.InspectionStartedAt.IsZero() {
+		return ierr.ShipmentNotValid{Reason: "inspection has started already"}
+	}
+
+	return nil
+}
+
+func notRespondedYet(t Transaction, _ command) error {
+	if !t.DisputeRespondedAt.IsZero() {
+		return ierr.DisputeNotValid{Reason: "seller has responded already"}
+	}
+
+	return nil
+}
+
+// beforeDisputeDeadline closes the response once the dispute deadline passes, the dispute is
+// escalated to admins then.
+func beforeDisputeDeadline(t Transaction, c command) error {
+	if !t.DisputeDeadline.IsZero() && !c.at.Before(t.DisputeDeadline) {
+		return ierr.DisputeNotValid{Reason: "response deadline has passed"}
+	}
+
+	return nil
+}
+
+// refundIsPartial allows refunding part of the seller net only, a full refund or release has
+// its own action and the escrow fee is kept by the platform.
+func refundIsPartial(t Transaction, c command) error {
+	if c.refund.Currency != t.Breakdown.SellerNet.Currency {
+		return ierr.DisputeResolutionNotValid{Reason: "refund currency should be " + string(t.Breakdown.SellerNet.Currency)}
+	}
+
+	if c.refund.Amount <= 0 || c.refund.Amount >= t.Breakdown.SellerNet.Amount {
+		return ierr.DisputeResolutionNotValid{Reason: "refund should be more than zero and less than " + t.Breakdown.SellerNet.String()}
+	}
+
+	return nil
+}
+
+func markAccepted(t *Transaction, c command) {
+	t.AcceptedAt = c.at
+	t.AcceptedBy = c.actor
+}
+
+func markRejected(t *Transaction, c command) {
+	t.RejectedAt = c.at
+	t.RejectedBy = c.actor
+	t.RejectedReason = c.reason
+}
+
+func markPaid(t *Transaction, c command) {
+	t.PaidAt = c.at
+}
+
+func markExpired(t *Transaction, c command) {
+	t.ExpiredAt = c.at
+	t.ExpiredReason = c.reason
+}
+
+// markDoneBySeller starts the inspection right away unless the goods are shipped, in which case
+// it starts on delivery.
+func markDoneBySeller(t *Transaction, c command) {
+	t.DoneBySellerAt = c.at
+	if c.shipping.isZero() {
+		t.InspectionStartedAt = t.DoneBySellerAt
+	}
+}
+
+// markDelivered starts the inspection when the courier delivered the goods, or now when the courier
+// did not report the time.
+func markDelivered(t *Transaction, c command) {
+	t.InspectionStartedAt = c.deliveredAt
+	if t.InspectionStartedAt.IsZero() {
+		t.InspectionStartedAt = c.at
+	}
+}
+
+func markSuccess(t *Transaction, c command) {
+	t.SuccessAt = c.at
+	t.SuccessBy = c.actor
+}
+
+func markDisputed(t *Transaction, c command) {
+	t.DisputedAt = c.at
+	t.DisputeReason = c.reason
+	window := c.window
+	if window <= 0 {
+		window = defaultDisputeWindow
+	}
+
+	t.DisputeDeadline = t.DisputedAt.Add(window)
+}
+
+func markDisputeResponded(t *Transaction, c command) {
+	t.DisputeResponse = c.reason
+	t.DisputeRespondedAt = c.at
+}
+
+func markResolved(t *Transaction, c command) {
+	t.ResolvedAt = c.at
+	t.ResolvedBy = c.admin.ID
+	t.ResolutionNote = c.reason
+}
+
+func markRefunded(t *Transaction, c command) {
+	markResolved(t, c)
+	t.RefundAmount = t.Breakdown.ItemTotal
+}
+
+func markReleased(t *Transaction, c command) {
+	markResolved(t, c)
+	markSuccess(t, c)
+}
+
+func markSplit(t *Transaction, c command) {
+	markResolved(t, c)
+	t.RefundAmount = c.refund
+}
+
+func findTransition(from Status, a Action, actor Actors) (transition, bool) {
+	for _, tr := range transitions {
+		if tr.from == from && tr.action == a && tr.actor == actor {
+			return tr, true
+		}
+	}
+
+	return transition{}, false
+}
+
+func (tr transition) check(t Transaction, c command) error {
+	for _, g := range tr.guards {
+		if err := g(t, c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fire applies the action to the transaction if the transition table allows it.
+func fire(t Transaction, a Action, c command) (Transaction, error) {
+	tr, ok := findTransition(t.Status, a, c.actor)
+	if !ok {
+		return Transaction{}, actionNotAllowed(t, a, c.actor)
+	}
+
+	// guards on deadlines compare against the time the action is fired
+	if c.at.IsZero() {
+		c.at = time.Now()
+	}
+
+	if err := tr.check(t, c); err != nil {
+		return Transaction{}, err
+	}
+
+	for _, v := range tr.validators {
+		if err := v(t, c); err != nil {
+			return Transaction{}, err
+		}
+	}
+
+	return tr.apply(t, c), nil
+}
+
+func (tr transition) apply(t Transaction, c command) Transaction {
+	t.Status = tr.to
+	if tr.hook != nil {
+		tr.hook(&t, c)
+	}
+
+	return t
+}
+
+func actionNotAllowed(t Transaction, a Action, actor Actors) error {
+	for _, tr := range transitions {
+		if tr.action == a && tr.actor == actor {
+			return ierr.TransactionStatusNotValid{
+				LastStatus: t.Status.String(),
+				NewStatus:  tr.to.String(),
+			}
+		}
+	}
+
+	return ierr.TransactionActionNotAllowed{Action: a.String(), Actor: actor.String()}
+}
+
+// availableActions returns the actions the actor can take on the transaction
+// in its current status, in the order of the transition table.
+func availableActions(t Transaction, c command) []Action {
+	if c.at.IsZero() {
+		c.at = time.Now()
+	}
+
+	actions := []Action{}
+	for _, tr := range transitions {
+		if tr.from != t.Status || tr.actor != c.actor {
+			continue
+		}
+
+		if err := tr.check(t, c); err != nil {
+			continue
+		}
+
+		actions = append(actions, tr.action)
+	}
+
+	return actions
+}
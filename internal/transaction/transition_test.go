@@ -0,0 +1,215 @@
+package transaction
+
+import (
+	"errors"
+	"reflect"
+	"rekber/ierr"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestBuyer_AvailableActions(t *testing.T) {
+	eligibleBuyer := Buyer{
+		ID:                    uuid.New(),
+		PhoneNumberVerifiedAt: time.Now(),
+	}
+
+	tests := []struct {
+		name string
+		b    Buyer
+		t    Transaction
+		want []Action
+	}{
+		{
+			name: "transaction created by seller can be accepted or rejected",
+			b:    eligibleBuyer,
+			t:    Transaction{CreatedBy: seller, Status: waitingForApproval},
+			want: []Action{accept, reject},
+		},
+		{
+			name: "transaction created by buyer waits for seller",
+			b:    eligibleBuyer,
+			t:    Transaction{CreatedBy: buyer, Status: waitingForApproval},
+			want: []Action{},
+		},
+		{
+			name: "unverified buyer can only reject",
+			b:    Buyer{ID: uuid.New()},
+			t:    Transaction{CreatedBy: seller, Status: waitingForApproval},
+			want: []Action{reject},
+		},
+		{
+			name: "accepted transaction waits for payment confirmation from payment provider",
+			b:    eligibleBuyer,
+			t:    Transaction{Status: waitingForPayment},
+			want: []Action{},
+		},
+		{
+			name: "paid transaction waits for seller or can be disputed",
+			b:    eligibleBuyer,
+			t:    Transaction{Status: paid},
+			want: []Action{dispute},
+		},
+		{
+			name: "transaction done by seller can be confirmed or disputed",
+			b:    eligibleBuyer,
+			t:    Transaction{Status: doneBySeller},
+			want: []Action{done, dispute},
+		},
+		{
+			name: "disputed transaction waits for admin",
+			b:    eligibleBuyer,
+			t:    Transaction{Status: disputed},
+			want: []Action{},
+		},
+		{
+			name: "success transaction has no action",
+			b:    eligibleBuyer,
+			t:    Transaction{Status: success},
+			want: []Action{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.b.AvailableActions(tt.t); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Buyer.AvailableActions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeller_AvailableActions(t *testing.T) {
+	eligibleSeller := Seller{
+		ID:                    uuid.New(),
+		PhoneNumberVerifiedAt: time.Now(),
+		BankAccount: BankAccount{
+			ID:         uuid.New(),
+			VerifiedAt: time.Now(),
+		},
+	}
+
+	tests := []struct {
+		name string
+		s    Seller
+		t    Transaction
+		want []Action
+	}{
+		{
+			name: "transaction created by buyer can be accepted or rejected",
+			s:    eligibleSeller,
+			t:    Transaction{CreatedBy: buyer, Status: waitingForApproval},
+			want: []Action{accept, reject},
+		},
+		{
+			name: "seller without bank account can only reject",
+			s:    Seller{ID: uuid.New(), PhoneNumberVerifiedAt: time.Now()},
+			t:    Transaction{CreatedBy: buyer, Status: waitingForApproval},
+			want: []Action{reject},
+		},
+		{
+			name: "accepted transaction waits for buyer payment",
+			s:    eligibleSeller,
+			t:    Transaction{Status: waitingForPayment},
+			want: []Action{},
+		},
+		{
+			name: "paid transaction can be done",
+			s:    eligibleSeller,
+			t:    Transaction{Status: paid},
+			want: []Action{done},
+		},
+		{
+			name: "disputed transaction can be responded",
+			s:    eligibleSeller,
+			t:    Transaction{Status: disputed},
+			want: []Action{respond},
+		},
+		{
+			name: "disputed transaction can be responded once",
+			s:    eligibleSeller,
+			t:    Transaction{Status: disputed, DisputeRespondedAt: time.Now()},
+			want: []Action{},
+		},
+		{
+			name: "disputed transaction can not be responded after the deadline",
+			s:    eligibleSeller,
+			t:    Transaction{Status: disputed, DisputeDeadline: time.Now().Add(-time.Minute)},
+			want: []Action{},
+		},
+		{
+			name: "disputed transaction can be responded before the deadline",
+			s:    eligibleSeller,
+			t:    Transaction{Status: disputed, DisputeDeadline: time.Now().Add(time.Minute)},
+			want: []Action{respond},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.AvailableActions(tt.t); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Seller.AvailableActions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_fire(t *testing.T) {
+	tests := []struct {
+		name    string
+		t       Transaction
+		a       Action
+		c       command
+		wantErr error
+	}{
+		{
+			name:    "action is known but not from the current status",
+			t:       Transaction{Status: success},
+			a:       pay,
+			c:       command{actor: system},
+			wantErr: ierr.TransactionStatusNotValid{LastStatus: success.String(), NewStatus: paid.String()},
+		},
+		{
+			name:    "action is never allowed for the actor",
+			t:       Transaction{Status: waitingForPayment},
+			a:       pay,
+			c:       command{actor: seller},
+			wantErr: ierr.TransactionActionNotAllowed{Action: pay.String(), Actor: seller.String()},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := fire(tt.t, tt.a, tt.c)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("fire() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAction_String(t *testing.T) {
+	tests := []struct {
+		name string
+		a    Action
+		want string
+	}{
+		{name: "accept", a: accept, want: "accept"},
+		{name: "reject", a: reject, want: "reject"},
+		{name: "pay", a: pay, want: "pay"},
+		{name: "expire", a: expire, want: "expire"},
+		{name: "done", a: done, want: "done"},
+		{name: "release", a: release, want: "release"},
+		{name: "dispute", a: dispute, want: "dispute"},
+		{name: "respond", a: respond, want: "respond"},
+		{name: "refund", a: refund, want: "refund"},
+		{name: "split", a: split, want: "split"},
+		{name: "unknown action", a: 0, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.String(); got != tt.want {
+				t.Errorf("Action.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
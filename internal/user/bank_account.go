@@ -0,0 +1,270 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"rekber/ierr"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+const (
+	minBankAccountNumberLength = 5
+	maxBankAccountNumberLength = 20
+	maxBankAccountNameLength   = 100
+)
+
+type BankAccountRepository interface {
+	ListBanks(ctx context.Context) ([]Bank, error)
+	GetBank(ctx context.Context, code string) (Bank, error)
+	// SaveBankAccount saves the bank account and returns it, making it the primary one when the user
+	// has no other bank account. It decides under a lock on the user, so of concurrent first bank
+	// accounts only one becomes the primary.
+	SaveBankAccount(ctx context.Context, b BankAccount) (BankAccount, error)
+	ListBankAccounts(ctx context.Context, userID uuid.UUID) ([]BankAccount, error)
+	// SetPrimaryBankAccount makes the bank account the only primary one of the user, it returns
+	// ierr.BankAccountNotFound when the user has no such bank account.
+	SetPrimaryBankAccount(ctx context.Context, userID, id uuid.UUID) error
+	DeleteBankAccount(ctx context.Context, userID, id uuid.UUID) error
+	// HasPendingPayout tells whether a payout still to be transferred is going to the bank account.
+	HasPendingPayout(ctx context.Context, id uuid.UUID) (bool, error)
+	// HasUnsettledSale tells whether the user is the seller of a paid transaction which is not
+	// settled yet, so its payout is still to be created.
+	HasUnsettledSale(ctx context.Context, userID uuid.UUID) (bool, error)
+	MarkBankAccountVerified(ctx context.Context, userID, id uuid.UUID, verifiedAt time.Time) error
+}
+
+// AccountInquirer looks up a bank account at the bank, usually through a disbursement provider.
+type AccountInquirer interface {
+	// InquireAccount returns the holder name of the bank account, or ierr.BankAccountInquiryNotFound
+	// when the bank has no such account.
+	InquireAccount(ctx context.Context, bankCode, number string) (string, error)
+}
+
+func (b BankAccount) validate() error {
+	if len(b.Number) < minBankAccountNumberLength || len(b.Number) > maxBankAccountNumberLength {
+		return ierr.BankAccountNotValid{Reason: fmt.Sprintf("number must be %d to %d digits", minBankAccountNumberLength, maxBankAccountNumberLength)}
+	}
+
+	for _, r := range b.Number {
+		if !unicode.IsDigit(r) {
+			return ierr.BankAccountNotValid{Reason: "number must only contain digits"}
+		}
+	}
+
+	if b.Name == "" || len(b.Name) > maxBankAccountNameLength {
+		return ierr.BankAccountNotValid{Reason: fmt.Sprintf("name must be 1 to %d characters", maxBankAccountNameLength)}
+	}
+
+	return nil
+}
+
+// sameHolderName compares holder names the way banks print them, ignoring case, punctuation and spacing.
+func sameHolderName(a, b string) bool {
+	return normalizeHolderName(a) == normalizeHolderName(b)
+}
+
+func normalizeHolderName(name string) string {
+	fields := strings.FieldsFunc(strings.ToUpper(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	return strings.Join(fields, " ")
+}
+
+func (s Service) ListBanks(ctx context.Context) ([]BankResponse, error) {
+	banks, err := s.bankAccountRepository.ListBanks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list banks: %w", err)
+	}
+
+	result := make([]BankResponse, 0, len(banks))
+	for _, b := range banks {
+		result = append(result, BankResponse{
+			Code: b.Code,
+			Name: b.Name,
+		})
+	}
+
+	return result, nil
+}
+
+// AddBankAccount adds a bank account to the user, the first one becomes the primary bank account.
+func (s Service) AddBankAccount(ctx context.Context, userID uuid.UUID, req AddBankAccountRequest) (BankAccountResponse, error) {
+	bank, err := s.bankAccountRepository.GetBank(ctx, req.BankCode)
+	if err != nil {
+		return BankAccountResponse{}, fmt.Errorf("failed to get bank: %w", err)
+	}
+
+	b := BankAccount{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Number:    req.Number,
+		Name:      req.Name,
+		Bank:      bank,
+		CreatedAt: time.Now(),
+	}
+
+	if err := b.validate(); err != nil {
+		return BankAccountResponse{}, err
+	}
+
+	b, err = s.bankAccountRepository.SaveBankAccount(ctx, b)
+	if err != nil {
+		return BankAccountResponse{}, fmt.Errorf("failed to save bank account: %w", err)
+	}
+
+	return newBankAccountResponse(b), nil
+}
+
+func (s Service) ListBankAccounts(ctx context.Context, userID uuid.UUID) ([]BankAccountResponse, error) {
+	accounts, err := s.bankAccountRepository.ListBankAccounts(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bank accounts: %w", err)
+	}
+
+	result := make([]BankAccountResponse, 0, len(accounts))
+	for _, b := range accounts {
+		result = append(result, newBankAccountResponse(b))
+	}
+
+	return result, nil
+}
+
+// SetPrimaryBankAccount makes the bank account the one receiving payouts. While a paid transaction
+// of the seller is not settled, only a verified bank account may become the primary one.
+func (s Service) SetPrimaryBankAccount(ctx context.Context, userID, id uuid.UUID) error {
+	accounts, err := s.bankAccountRepository.ListBankAccounts(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to list bank accounts: %w", err)
+	}
+
+	var b BankAccount
+	for _, account := range accounts {
+		if account.ID == id {
+			b = account
+		}
+	}
+
+	if b.ID == uuid.Nil {
+		return ierr.BankAccountNotFound{ID: id}
+	}
+
+	if !b.IsVerified() {
+		unsettled, err := s.bankAccountRepository.HasUnsettledSale(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to check unsettled sale: %w", err)
+		}
+
+		if unsettled {
+			return ierr.BankAccountNotVerified{ID: id}
+		}
+	}
+
+	if err := s.bankAccountRepository.SetPrimaryBankAccount(ctx, userID, id); err != nil {
+		return fmt.Errorf("failed to set primary bank account: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveBankAccount removes the bank account, when it is the primary one the oldest remaining
+// bank account becomes the primary so a seller does not silently lose eligibility.
+func (s Service) RemoveBankAccount(ctx context.Context, userID, id uuid.UUID) error {
+	accounts, err := s.bankAccountRepository.ListBankAccounts(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to list bank accounts: %w", err)
+	}
+
+	var (
+		removed   BankAccount
+		remaining []BankAccount
+	)
+	for _, b := range accounts {
+		if b.ID == id {
+			removed = b
+			continue
+		}
+
+		remaining = append(remaining, b)
+	}
+
+	if removed.ID == uuid.Nil {
+		return ierr.BankAccountNotFound{ID: id}
+	}
+
+	pending, err := s.bankAccountRepository.HasPendingPayout(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to check pending payout: %w", err)
+	}
+
+	if pending {
+		return ierr.BankAccountInUse{ID: id}
+	}
+
+	if err := s.bankAccountRepository.DeleteBankAccount(ctx, userID, id); err != nil {
+		return fmt.Errorf("failed to delete bank account: %w", err)
+	}
+
+	if !removed.IsPrimary || len(remaining) == 0 {
+		return nil
+	}
+
+	if err := s.bankAccountRepository.SetPrimaryBankAccount(ctx, userID, nextPrimary(remaining).ID); err != nil && !errors.As(err, &ierr.BankAccountNotFound{}) {
+		return fmt.Errorf("failed to set primary bank account: %w", err)
+	}
+
+	return nil
+}
+
+// nextPrimary picks the bank account to replace a removed primary one, the oldest verified one so
+// the user can still receive payout, or the oldest one when none is verified.
+func nextPrimary(accounts []BankAccount) BankAccount {
+	for _, b := range accounts {
+		if b.IsVerified() {
+			return b
+		}
+	}
+
+	return accounts[0]
+}
+
+// VerifyBankAccount checks the account number and holder name against the bank before marking
+// the bank account verified, only a verified bank account receives payout.
+func (s Service) VerifyBankAccount(ctx context.Context, userID, id uuid.UUID) (BankAccountResponse, error) {
+	accounts, err := s.bankAccountRepository.ListBankAccounts(ctx, userID)
+	if err != nil {
+		return BankAccountResponse{}, fmt.Errorf("failed to list bank accounts: %w", err)
+	}
+
+	var b BankAccount
+	for _, account := range accounts {
+		if account.ID == id {
+			b = account
+		}
+	}
+
+	if b.ID == uuid.Nil {
+		return BankAccountResponse{}, ierr.BankAccountNotFound{ID: id}
+	}
+
+	holderName, err := s.accountInquirer.InquireAccount(ctx, b.Bank.Code, b.Number)
+	if err != nil {
+		return BankAccountResponse{}, fmt.Errorf("failed to inquire bank account: %w", err)
+	}
+
+	if !sameHolderName(holderName, b.Name) {
+		return BankAccountResponse{}, ierr.BankAccountHolderNameMismatch{ID: b.ID}
+	}
+
+	b.VerifiedAt = time.Now()
+	if err := s.bankAccountRepository.MarkBankAccountVerified(ctx, userID, b.ID, b.VerifiedAt); err != nil {
+		return BankAccountResponse{}, fmt.Errorf("failed to mark bank account verified: %w", err)
+	}
+
+	return newBankAccountResponse(b), nil
+}
@@ -0,0 +1,284 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"rekber/ierr"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestBankAccount_validate(t *testing.T) {
+	type fields struct {
+		Number string
+		Name   string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		wantErr error
+	}{
+		{
+			name: "valid bank account",
+			fields: fields{
+				Number: "1234567890",
+				Name:   "Budi Santoso",
+			},
+		},
+		{
+			name: "number is too short",
+			fields: fields{
+				Number: "1234",
+				Name:   "Budi Santoso",
+			},
+			wantErr: ierr.BankAccountNotValid{Reason: "number must be 5 to 20 digits"},
+		},
+		{
+			name: "number contains non digit",
+			fields: fields{
+				Number: "12345-6789",
+				Name:   "Budi Santoso",
+			},
+			wantErr: ierr.BankAccountNotValid{Reason: "number must only contain digits"},
+		},
+		{
+			name: "name is empty",
+			fields: fields{
+				Number: "1234567890",
+			},
+			wantErr: ierr.BankAccountNotValid{Reason: "name must be 1 to 100 characters"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := BankAccount{
+				Number: tt.fields.Number,
+				Name:   tt.fields.Name,
+			}
+
+			if err := b.validate(); err != tt.wantErr {
+				t.Errorf("BankAccount.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_sameHolderName(t *testing.T) {
+	type args struct {
+		a string
+		b string
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{
+			name: "same name with different case and spacing",
+			args: args{
+				a: "BUDI  SANTOSO",
+				b: "budi santoso ",
+			},
+			want: true,
+		},
+		{
+			name: "same name with punctuation",
+			args: args{
+				a: "M. RIZKY",
+				b: "M Rizky",
+			},
+			want: true,
+		},
+		{
+			name: "different name",
+			args: args{
+				a: "BUDI SANTOSO",
+				b: "Budi Santosa",
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sameHolderName(tt.args.a, tt.args.b); got != tt.want {
+				t.Errorf("sameHolderName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type fakeBankAccountRepository struct {
+	BankAccountRepository
+	accounts  []BankAccount
+	pending   map[uuid.UUID]bool
+	unsettled bool
+}
+
+func (f *fakeBankAccountRepository) ListBankAccounts(ctx context.Context, userID uuid.UUID) ([]BankAccount, error) {
+	return f.accounts, nil
+}
+
+func (f *fakeBankAccountRepository) HasPendingPayout(ctx context.Context, id uuid.UUID) (bool, error) {
+	return f.pending[id], nil
+}
+
+func (f *fakeBankAccountRepository) HasUnsettledSale(ctx context.Context, userID uuid.UUID) (bool, error) {
+	return f.unsettled, nil
+}
+
+func (f *fakeBankAccountRepository) DeleteBankAccount(ctx context.Context, userID, id uuid.UUID) error {
+	for i, b := range f.accounts {
+		if b.ID == id {
+			f.accounts = append(f.accounts[:i:i], f.accounts[i+1:]...)
+			return nil
+		}
+	}
+
+	return ierr.BankAccountNotFound{ID: id}
+}
+
+func (f *fakeBankAccountRepository) SetPrimaryBankAccount(ctx context.Context, userID, id uuid.UUID) error {
+	for i := range f.accounts {
+		f.accounts[i].IsPrimary = f.accounts[i].ID == id
+	}
+
+	return nil
+}
+
+func TestService_RemoveBankAccount(t *testing.T) {
+	userUUID := uuid.New()
+	verifiedAt := time.Date(2023, 9, 30, 21, 0, 0, 0, time.UTC)
+	primary := BankAccount{ID: uuid.New(), UserID: userUUID, IsPrimary: true, VerifiedAt: verifiedAt}
+	oldestUnverified := BankAccount{ID: uuid.New(), UserID: userUUID}
+	verified := BankAccount{ID: uuid.New(), UserID: userUUID, VerifiedAt: verifiedAt}
+	otherUnverified := BankAccount{ID: uuid.New(), UserID: userUUID}
+	otherUserAccountUUID := uuid.New()
+
+	tests := []struct {
+		name        string
+		accounts    []BankAccount
+		pending     map[uuid.UUID]bool
+		id          uuid.UUID
+		wantPrimary uuid.UUID
+		wantErr     error
+	}{
+		{
+			name:        "removed primary is replaced by a verified account before older unverified ones",
+			accounts:    []BankAccount{primary, oldestUnverified, verified},
+			id:          primary.ID,
+			wantPrimary: verified.ID,
+		},
+		{
+			name:        "removed primary is replaced by the oldest account when none is verified",
+			accounts:    []BankAccount{primary, oldestUnverified, otherUnverified},
+			id:          primary.ID,
+			wantPrimary: oldestUnverified.ID,
+		},
+		{
+			name:        "removing other account keeps the primary",
+			accounts:    []BankAccount{primary, verified},
+			id:          verified.ID,
+			wantPrimary: primary.ID,
+		},
+		{
+			name:     "account with pending payout",
+			accounts: []BankAccount{primary, verified},
+			pending:  map[uuid.UUID]bool{primary.ID: true},
+			id:       primary.ID,
+			wantErr:  ierr.BankAccountInUse{ID: primary.ID},
+		},
+		{
+			name:     "account of other user",
+			accounts: []BankAccount{primary},
+			id:       otherUserAccountUUID,
+			wantErr:  ierr.BankAccountNotFound{ID: otherUserAccountUUID},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeBankAccountRepository{accounts: append([]BankAccount(nil), tt.accounts...), pending: tt.pending}
+			s := Service{bankAccountRepository: repo}
+
+			err := s.RemoveBankAccount(context.Background(), userUUID, tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Service.RemoveBankAccount() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(repo.accounts) != len(tt.accounts) {
+					t.Errorf("Service.RemoveBankAccount() deleted the account")
+				}
+				return
+			}
+
+			for _, b := range repo.accounts {
+				if b.ID == tt.id {
+					t.Errorf("Service.RemoveBankAccount() kept the account")
+				}
+				if b.IsPrimary != (b.ID == tt.wantPrimary) {
+					t.Errorf("bank account %v is primary = %v, want primary %v", b.ID, b.IsPrimary, tt.wantPrimary)
+				}
+			}
+		})
+	}
+}
+
+func TestService_SetPrimaryBankAccount(t *testing.T) {
+	userUUID := uuid.New()
+	verifiedAt := time.Date(2023, 9, 30, 21, 0, 0, 0, time.UTC)
+	primary := BankAccount{ID: uuid.New(), UserID: userUUID, IsPrimary: true, VerifiedAt: verifiedAt}
+	verified := BankAccount{ID: uuid.New(), UserID: userUUID, VerifiedAt: verifiedAt}
+	unverified := BankAccount{ID: uuid.New(), UserID: userUUID}
+	otherUserAccountUUID := uuid.New()
+
+	tests := []struct {
+		name        string
+		unsettled   bool
+		id          uuid.UUID
+		wantPrimary uuid.UUID
+		wantErr     error
+	}{
+		{
+			name:        "verified account while a sale is not settled",
+			unsettled:   true,
+			id:          verified.ID,
+			wantPrimary: verified.ID,
+		},
+		{
+			name:        "unverified account without unsettled sale",
+			id:          unverified.ID,
+			wantPrimary: unverified.ID,
+		},
+		{
+			name:        "unverified account while a sale is not settled",
+			unsettled:   true,
+			id:          unverified.ID,
+			wantPrimary: primary.ID,
+			wantErr:     ierr.BankAccountNotVerified{ID: unverified.ID},
+		},
+		{
+			name:        "account of other user",
+			id:          otherUserAccountUUID,
+			wantPrimary: primary.ID,
+			wantErr:     ierr.BankAccountNotFound{ID: otherUserAccountUUID},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeBankAccountRepository{accounts: []BankAccount{primary, verified, unverified}, unsettled: tt.unsettled}
+			s := Service{bankAccountRepository: repo}
+
+			err := s.SetPrimaryBankAccount(context.Background(), userUUID, tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Service.SetPrimaryBankAccount() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			for _, b := range repo.accounts {
+				if b.IsPrimary != (b.ID == tt.wantPrimary) {
+					t.Errorf("bank account %v is primary = %v, want primary %v", b.ID, b.IsPrimary, tt.wantPrimary)
+				}
+			}
+		})
+	}
+}
@@ -1,5 +1,11 @@
 package user
 
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
 const (
 	RegisterState VerifyOTPState = iota + 1
 	LoginState
@@ -14,9 +20,19 @@ type LoginResponse struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
 type SendOTPRequest struct {
 	PhoneNumber string `json:"phone_number"`
 	Captcha     string `json:"captcha"`
+	// IP is the client address, it is set by the handler and not read from the body.
+	IP string `json:"-"`
 }
 
 type SendOTPResponse struct {
@@ -25,14 +41,66 @@ type SendOTPResponse struct {
 
 type VerifyOTPState int
 
+func (s VerifyOTPState) IsValid() bool {
+	switch s {
+	case RegisterState, LoginState:
+		return true
+	default:
+		return false
+	}
+}
+
 type VerifyOTP struct {
 	PhoneNumber string         `json:"phone_number"`
 	OTP         string         `json:"otp"`
 	State       VerifyOTPState `json:"state"`
 	SessionInfo string         `json:"session_info"`
+	// IP is the client address, it is set by the handler and not read from the body.
+	IP string `json:"-"`
 }
 
 type RegisterRequest struct {
 	PhoneNumber string `json:"phone_number"`
 	Name        string `json:"name"`
 }
+
+type BankResponse struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+type AddBankAccountRequest struct {
+	BankCode string `json:"bank_code"`
+	Number   string `json:"number"`
+	Name     string `json:"name"`
+}
+
+type BankAccountResponse struct {
+	ID         uuid.UUID    `json:"id"`
+	Bank       BankResponse `json:"bank"`
+	Number     string       `json:"number"`
+	Name       string       `json:"name"`
+	IsPrimary  bool         `json:"is_primary"`
+	VerifiedAt *time.Time   `json:"verified_at,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+func newBankAccountResponse(b BankAccount) BankAccountResponse {
+	resp := BankAccountResponse{
+		ID: b.ID,
+		Bank: BankResponse{
+			Code: b.Bank.Code,
+			Name: b.Bank.Name,
+		},
+		Number:    b.Number,
+		Name:      b.Name,
+		IsPrimary: b.IsPrimary,
+		CreatedAt: b.CreatedAt,
+	}
+
+	if !b.VerifiedAt.IsZero() {
+		resp.VerifiedAt = &b.VerifiedAt
+	}
+
+	return resp
+}
@@ -0,0 +1,34 @@
+package user
+
+import "testing"
+
+func TestVerifyOTPState_IsValid(t *testing.T) {
+	tests := []struct {
+		name string
+		s    VerifyOTPState
+		want bool
+	}{
+		{
+			name: "register state",
+			s:    RegisterState,
+			want: true,
+		},
+		{
+			name: "login state",
+			s:    LoginState,
+			want: true,
+		},
+		{
+			name: "unknown state",
+			s:    0,
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.IsValid(); got != tt.want {
+				t.Errorf("VerifyOTPState.IsValid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"rekber/config"
+	"rekber/ierr"
+	"time"
+)
+
+// RateLimiter counts hits of a key within a fixed window which starts on the first hit.
+type RateLimiter interface {
+	// Hit counts a hit for the key, it returns the hits within the current window and when the window ends.
+	Hit(ctx context.Context, key string, window time.Duration) (int, time.Time, error)
+	Reset(ctx context.Context, key string) error
+}
+
+// limitSendOTP applies a cooldown between sends and caps sends per phone number and per IP.
+func (s Service) limitSendOTP(ctx context.Context, req SendOTPRequest) error {
+	limit := config.Get().OTP.RateLimit
+
+	type counter struct {
+		key    string
+		window time.Duration
+		max    int
+	}
+
+	counters := []counter{
+		{key: "otp:send:cooldown:" + req.PhoneNumber, window: limit.SendCooldown, max: 1},
+		{key: "otp:send:phone:" + req.PhoneNumber, window: limit.SendWindow, max: limit.MaxSendsPerPhone},
+	}
+	if req.IP != "" {
+		counters = append(counters, counter{key: "otp:send:ip:" + req.IP, window: limit.SendWindow, max: limit.MaxSendsPerIP})
+	}
+
+	for _, k := range counters {
+		if k.window <= 0 || k.max <= 0 {
+			continue
+		}
+
+		hits, resetAt, err := s.rateLimiter.Hit(ctx, k.key, k.window)
+		if err != nil {
+			return fmt.Errorf("failed to count otp send: %w", err)
+		}
+
+		if hits > k.max {
+			return ierr.OTPSendLimited{PhoneNumber: req.PhoneNumber, Retry: time.Until(resetAt)}
+		}
+	}
+
+	return nil
+}
+
+// verifyOTPWithLimit locks the phone number out after too many failed attempts, the lockout ends
+// when the window started by the first failed attempt ends. Every attempt is counted before the OTP
+// is checked so concurrent guesses cannot slip past the limit, and a successful one resets the count.
+// Attempts per IP are capped as well so one client cannot guess across many phone numbers.
+func (s Service) verifyOTPWithLimit(ctx context.Context, req VerifyOTP) error {
+	limit := config.Get().OTP.RateLimit
+	attemptsKey := "otp:verify:attempts:" + req.PhoneNumber
+	lockout := limit.MaxFailedAttempts > 0 && limit.Lockout > 0
+
+	var (
+		attempts int
+		resetAt  time.Time
+	)
+	if lockout {
+		var err error
+		attempts, resetAt, err = s.rateLimiter.Hit(ctx, attemptsKey, limit.Lockout)
+		if err != nil {
+			return fmt.Errorf("failed to count otp attempt: %w", err)
+		}
+
+		if attempts > limit.MaxFailedAttempts {
+			return ierr.OTPVerifyLocked{PhoneNumber: req.PhoneNumber, Retry: time.Until(resetAt)}
+		}
+	}
+
+	if req.IP != "" && limit.MaxVerifiesPerIP > 0 && limit.VerifyWindow > 0 {
+		hits, resetAt, err := s.rateLimiter.Hit(ctx, "otp:verify:ip:"+req.IP, limit.VerifyWindow)
+		if err != nil {
+			return fmt.Errorf("failed to count otp verification: %w", err)
+		}
+
+		if hits > limit.MaxVerifiesPerIP {
+			return ierr.OTPVerifyLocked{PhoneNumber: req.PhoneNumber, Retry: time.Until(resetAt)}
+		}
+	}
+
+	err := s.otpProvider.VerifyOTP(ctx, req.PhoneNumber, req.OTP, req.SessionInfo)
+	if !lockout {
+		return err
+	}
+
+	if errors.As(err, &ierr.InvalidOTP{}) && attempts >= limit.MaxFailedAttempts {
+		return ierr.OTPVerifyLocked{PhoneNumber: req.PhoneNumber, Retry: time.Until(resetAt)}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := s.rateLimiter.Reset(ctx, attemptsKey); err != nil {
+		return fmt.Errorf("failed to reset otp attempts: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,231 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"rekber/config"
+	"rekber/ierr"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+)
+
+type fakeRateLimiter struct {
+	mu   sync.Mutex
+	hits map[string]int
+}
+
+func (f *fakeRateLimiter) Hit(ctx context.Context, key string, window time.Duration) (int, time.Time, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.hits[key]++
+	return f.hits[key], time.Now().Add(window), nil
+}
+
+func (f *fakeRateLimiter) Reset(ctx context.Context, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.hits, key)
+	return nil
+}
+
+type fakeOTPProvider struct {
+	otp string
+}
+
+func (f fakeOTPProvider) VerifyOTP(ctx context.Context, phoneNumber, otp, sessionInfo string) error {
+	if otp != f.otp {
+		return ierr.InvalidOTP{PhoneNumber: phoneNumber, OTP: otp}
+	}
+
+	return nil
+}
+
+func (f fakeOTPProvider) SendOTP(ctx context.Context, phoneNumber, captcha string) (string, error) {
+	return "session", nil
+}
+
+// countingOTPProvider counts the OTPs which reach the provider.
+type countingOTPProvider struct {
+	fakeOTPProvider
+	verified atomic.Int32
+}
+
+func (f *countingOTPProvider) VerifyOTP(ctx context.Context, phoneNumber, otp, sessionInfo string) error {
+	f.verified.Add(1)
+	return f.fakeOTPProvider.VerifyOTP(ctx, phoneNumber, otp, sessionInfo)
+}
+
+func TestService_limitSendOTP(t *testing.T) {
+	timeNow := time.Date(2023, 9, 30, 21, 0, 0, 0, time.UTC)
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time {
+		return timeNow
+	})
+	defer patches.Reset()
+
+	tests := []struct {
+		name    string
+		limit   config.OTPRateLimitConfig
+		reqs    []SendOTPRequest
+		wantErr error
+	}{
+		{
+			name:  "second send within cooldown is limited",
+			limit: config.OTPRateLimitConfig{SendCooldown: time.Minute},
+			reqs: []SendOTPRequest{
+				{PhoneNumber: "8121313231"},
+				{PhoneNumber: "8121313231"},
+			},
+			wantErr: ierr.OTPSendLimited{PhoneNumber: "8121313231", Retry: time.Minute},
+		},
+		{
+			name:  "cooldown is per phone number",
+			limit: config.OTPRateLimitConfig{SendCooldown: time.Minute},
+			reqs: []SendOTPRequest{
+				{PhoneNumber: "8121313231"},
+				{PhoneNumber: "8121313232"},
+			},
+		},
+		{
+			name:  "sends per ip are capped across phone numbers",
+			limit: config.OTPRateLimitConfig{SendWindow: time.Hour, MaxSendsPerIP: 2},
+			reqs: []SendOTPRequest{
+				{PhoneNumber: "8121313231", IP: "10.0.0.1"},
+				{PhoneNumber: "8121313232", IP: "10.0.0.1"},
+				{PhoneNumber: "8121313233", IP: "10.0.0.1"},
+			},
+			wantErr: ierr.OTPSendLimited{PhoneNumber: "8121313233", Retry: time.Hour},
+		},
+		{
+			name:  "zero limit disables limiting",
+			limit: config.OTPRateLimitConfig{},
+			reqs: []SendOTPRequest{
+				{PhoneNumber: "8121313231"},
+				{PhoneNumber: "8121313231"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Set(config.Config{OTP: config.OTPConfig{RateLimit: tt.limit}})
+			s := Service{rateLimiter: &fakeRateLimiter{hits: map[string]int{}}}
+
+			var err error
+			for _, req := range tt.reqs {
+				if err = s.limitSendOTP(context.Background(), req); err != nil {
+					break
+				}
+			}
+
+			if err != tt.wantErr {
+				t.Errorf("Service.limitSendOTP() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestService_verifyOTPWithLimit(t *testing.T) {
+	timeNow := time.Date(2023, 9, 30, 21, 0, 0, 0, time.UTC)
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time {
+		return timeNow
+	})
+	defer patches.Reset()
+
+	limit := config.OTPRateLimitConfig{MaxFailedAttempts: 3, Lockout: 15 * time.Minute}
+	locked := ierr.OTPVerifyLocked{PhoneNumber: "8121313231", Retry: 15 * time.Minute}
+
+	tests := []struct {
+		name    string
+		otps    []string
+		wantErr error
+	}{
+		{
+			name:    "failed attempts below the limit return invalid otp",
+			otps:    []string{"000000", "000000"},
+			wantErr: ierr.InvalidOTP{PhoneNumber: "8121313231", OTP: "000000"},
+		},
+		{
+			name:    "reaching the limit locks the phone number out",
+			otps:    []string{"000000", "000000", "000000"},
+			wantErr: locked,
+		},
+		{
+			name:    "correct otp is rejected while locked out",
+			otps:    []string{"000000", "000000", "000000", "123456"},
+			wantErr: locked,
+		},
+		{
+			name: "successful verification resets failed attempts",
+			otps: []string{"000000", "000000", "123456", "000000", "000000", "123456"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Set(config.Config{OTP: config.OTPConfig{RateLimit: limit}})
+			s := Service{
+				otpProvider: fakeOTPProvider{otp: "123456"},
+				rateLimiter: &fakeRateLimiter{hits: map[string]int{}},
+			}
+
+			var err error
+			for _, otp := range tt.otps {
+				err = s.verifyOTPWithLimit(context.Background(), VerifyOTP{PhoneNumber: "8121313231", OTP: otp})
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Service.verifyOTPWithLimit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestService_verifyOTPWithLimit_concurrent(t *testing.T) {
+	tests := []struct {
+		name         string
+		limit        config.OTPRateLimitConfig
+		guesses      int
+		wantVerified int32
+	}{
+		{
+			name:         "concurrent wrong guesses are capped by the failed attempts limit",
+			limit:        config.OTPRateLimitConfig{MaxFailedAttempts: 3, Lockout: 15 * time.Minute},
+			guesses:      50,
+			wantVerified: 3,
+		},
+		{
+			name:         "concurrent guesses below the limit all reach the provider",
+			limit:        config.OTPRateLimitConfig{MaxFailedAttempts: 10, Lockout: 15 * time.Minute},
+			guesses:      5,
+			wantVerified: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Set(config.Config{OTP: config.OTPConfig{RateLimit: tt.limit}})
+			provider := &countingOTPProvider{fakeOTPProvider: fakeOTPProvider{otp: "123456"}}
+			s := Service{
+				otpProvider: provider,
+				rateLimiter: &fakeRateLimiter{hits: map[string]int{}},
+			}
+
+			var wg sync.WaitGroup
+			for i := 0; i < tt.guesses; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_ = s.verifyOTPWithLimit(context.Background(), VerifyOTP{PhoneNumber: "8121313231", OTP: "000000"})
+				}()
+			}
+			wg.Wait()
+
+			if got := provider.verified.Load(); got != tt.wantVerified {
+				t.Errorf("Service.verifyOTPWithLimit() verified %v guesses, want %v", got, tt.wantVerified)
+			}
+		})
+	}
+}
@@ -2,56 +2,181 @@ package user
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"rekber/ierr"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-type OTPRepository interface {
+// OTPProvider sends OTP code to a phone number and verifies it.
+type OTPProvider interface {
 	VerifyOTP(ctx context.Context, phoneNumber, otp, sessionInfo string) error
 	SendOTP(ctx context.Context, phoneNumber, captcha string) (string, error)
+}
+
+// VerifiedOTPRepository keeps phone numbers which have passed OTP verification for a state.
+// A verified OTP expires after a while and can only be consumed once.
+type VerifiedOTPRepository interface {
 	SaveVerifiedOTP(ctx context.Context, phoneNumber string, state int) error
-	GetVerifiedOTP(ctx context.Context, phoneNumber string, state int) error
+	// ConsumeVerifiedOTP atomically removes the verified OTP, it returns ierr.UserForbiddenAccess
+	// when there is none or it is expired.
+	ConsumeVerifiedOTP(ctx context.Context, phoneNumber string, state int) error
 }
 
 type Repository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (User, error)
 	GetByPhoneNumber(ctx context.Context, phoneNumber string) (User, error)
 	Save(ctx context.Context, u User) error
 }
 
+type RefreshTokenRepository interface {
+	Save(ctx context.Context, r RefreshToken) error
+	GetByID(ctx context.Context, id uuid.UUID) (RefreshToken, error)
+	// Rotate marks the old refresh token as rotated and saves the new one atomically. It returns
+	// ierr.RefreshTokenReused when the old one has been rotated or revoked already.
+	Rotate(ctx context.Context, oldID uuid.UUID, r RefreshToken) error
+	RevokeFamily(ctx context.Context, familyID uuid.UUID) error
+	RevokeByUserID(ctx context.Context, userID uuid.UUID) error
+}
+
+type AccessTokenRevocationRepository interface {
+	Revoke(ctx context.Context, t AccessToken) error
+	// RevokeAllBefore revokes every access token of the user issued at or before the given time.
+	RevokeAllBefore(ctx context.Context, userID uuid.UUID, before time.Time) error
+}
+
 type Service struct {
-	otpRepository OTPRepository
-	repository    Repository
+	otpProvider                     OTPProvider
+	verifiedOTPRepository           VerifiedOTPRepository
+	repository                      Repository
+	refreshTokenRepository          RefreshTokenRepository
+	accessTokenRevocationRepository AccessTokenRevocationRepository
+	rateLimiter                     RateLimiter
+	bankAccountRepository           BankAccountRepository
+	accountInquirer                 AccountInquirer
 }
 
+// Login issues a token pair to the user whose phone number passed OTP verification. The verified
+// OTP is consumed only once the user is found, so a failed lookup does not burn it.
 func (s Service) Login(ctx context.Context, req LoginRequest) (LoginResponse, error) {
-	if err := s.otpRepository.GetVerifiedOTP(ctx, req.PhoneNumber, int(LoginState)); err != nil {
+	user, err := s.repository.GetByPhoneNumber(ctx, req.PhoneNumber)
+	if err != nil {
+		return LoginResponse{}, fmt.Errorf("failed to get user by phone number: %w", err)
+	}
+
+	if err := s.verifiedOTPRepository.ConsumeVerifiedOTP(ctx, req.PhoneNumber, int(LoginState)); err != nil {
 		return LoginResponse{}, fmt.Errorf("failed to verify otp: %w", err)
 	}
 
-	user, err := s.repository.GetByPhoneNumber(ctx, req.PhoneNumber)
+	// every login starts a new refresh token family
+	generatedToken, err := user.generateToken(uuid.New())
 	if err != nil {
-		return LoginResponse{}, fmt.Errorf("failed to get user by phone number: %w", err)
+		return LoginResponse{}, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	if err := s.refreshTokenRepository.Save(ctx, generatedToken.refresh); err != nil {
+		return LoginResponse{}, fmt.Errorf("failed to save refresh token: %w", err)
+	}
+
+	return LoginResponse{
+		AccessToken:  generatedToken.accessToken,
+		RefreshToken: generatedToken.refreshToken,
+	}, nil
+}
+
+// RefreshToken exchanges a valid refresh token for a new token pair. The presented refresh token
+// can only be used once, presenting it again revokes every refresh token of its family.
+func (s Service) RefreshToken(ctx context.Context, claims RefreshToken) (LoginResponse, error) {
+	stored, err := s.refreshTokenRepository.GetByID(ctx, claims.ID)
+	if err != nil {
+		return LoginResponse{}, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	if stored.UserID != claims.UserID || stored.FamilyID != claims.FamilyID || stored.IsRevoked() {
+		return LoginResponse{}, ierr.JWTError{}
+	}
+
+	if stored.IsRotated() {
+		return LoginResponse{}, s.revokeFamily(ctx, stored.FamilyID)
+	}
+
+	user, err := s.repository.GetByID(ctx, stored.UserID)
+	if err != nil {
+		return LoginResponse{}, fmt.Errorf("failed to get user by id: %w", err)
 	}
 
-	generatedToken, err := user.generateToken()
+	generatedToken, err := user.generateToken(stored.FamilyID)
 	if err != nil {
 		return LoginResponse{}, fmt.Errorf("failed to generate token: %w", err)
 	}
 
+	if err := s.refreshTokenRepository.Rotate(ctx, stored.ID, generatedToken.refresh); err != nil {
+		// lost the race against another request presenting the same refresh token
+		if errors.As(err, &ierr.RefreshTokenReused{}) {
+			return LoginResponse{}, s.revokeFamily(ctx, stored.FamilyID)
+		}
+
+		return LoginResponse{}, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+
 	return LoginResponse{
 		AccessToken:  generatedToken.accessToken,
 		RefreshToken: generatedToken.refreshToken,
 	}, nil
 }
 
+func (s Service) revokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	if err := s.refreshTokenRepository.RevokeFamily(ctx, familyID); err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+
+	return ierr.RefreshTokenReused{}
+}
+
+// Logout revokes the access token and, when given, the refresh token family it was issued with.
+func (s Service) Logout(ctx context.Context, accessToken AccessToken, refreshToken *RefreshToken) error {
+	if refreshToken != nil {
+		if refreshToken.UserID != accessToken.UserID {
+			return ierr.JWTError{}
+		}
+
+		if err := s.refreshTokenRepository.RevokeFamily(ctx, refreshToken.FamilyID); err != nil {
+			return fmt.Errorf("failed to revoke refresh token family: %w", err)
+		}
+	}
+
+	if err := s.accessTokenRevocationRepository.Revoke(ctx, accessToken); err != nil {
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+
+	return nil
+}
+
+// LogoutAll revokes every access and refresh token of the user, logging out all devices.
+func (s Service) LogoutAll(ctx context.Context, accessToken AccessToken) error {
+	if err := s.refreshTokenRepository.RevokeByUserID(ctx, accessToken.UserID); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	if err := s.accessTokenRevocationRepository.RevokeAllBefore(ctx, accessToken.UserID, time.Now()); err != nil {
+		return fmt.Errorf("failed to revoke access tokens: %w", err)
+	}
+
+	return nil
+}
+
 func (s Service) VerifyOTP(ctx context.Context, req VerifyOTP) error {
-	if err := s.otpRepository.VerifyOTP(ctx, req.PhoneNumber, req.OTP, req.SessionInfo); err != nil {
+	if !req.State.IsValid() {
+		return ierr.InvalidOTPState{State: int(req.State)}
+	}
+
+	if err := s.verifyOTPWithLimit(ctx, req); err != nil {
 		return fmt.Errorf("failed to verify otp: %w", err)
 	}
 
-	if err := s.otpRepository.SaveVerifiedOTP(ctx, req.PhoneNumber, int(req.State)); err != nil {
+	if err := s.verifiedOTPRepository.SaveVerifiedOTP(ctx, req.PhoneNumber, int(req.State)); err != nil {
 		return fmt.Errorf("failed to save verified otp: %w", err)
 	}
 
@@ -59,7 +184,11 @@ func (s Service) VerifyOTP(ctx context.Context, req VerifyOTP) error {
 }
 
 func (s Service) SendOTP(ctx context.Context, req SendOTPRequest) (SendOTPResponse, error) {
-	sessionInfo, err := s.otpRepository.SendOTP(ctx, req.PhoneNumber, req.Captcha)
+	if err := s.limitSendOTP(ctx, req); err != nil {
+		return SendOTPResponse{}, err
+	}
+
+	sessionInfo, err := s.otpProvider.SendOTP(ctx, req.PhoneNumber, req.Captcha)
 	if err != nil {
 		return SendOTPResponse{}, err
 	}
@@ -69,8 +198,20 @@ func (s Service) SendOTP(ctx context.Context, req SendOTPRequest) (SendOTPRespon
 	}, nil
 }
 
+// Register creates the user whose phone number passed OTP verification. A taken phone number is
+// rejected before the verified OTP is consumed, and the verified OTP is given back when the user
+// cannot be saved, so only a registered user uses it up.
 func (s Service) Register(ctx context.Context, req RegisterRequest) error {
-	if err := s.otpRepository.GetVerifiedOTP(ctx, req.PhoneNumber, int(RegisterState)); err != nil {
+	_, err := s.repository.GetByPhoneNumber(ctx, req.PhoneNumber)
+	if err == nil {
+		return ierr.UserAlreadyExists{PhoneNumber: req.PhoneNumber}
+	}
+
+	if !errors.As(err, &ierr.UserNotFound{}) {
+		return fmt.Errorf("failed to get user by phone number: %w", err)
+	}
+
+	if err := s.verifiedOTPRepository.ConsumeVerifiedOTP(ctx, req.PhoneNumber, int(RegisterState)); err != nil {
 		return fmt.Errorf("failed to get verified otp: %w", err)
 	}
 
@@ -83,15 +224,25 @@ func (s Service) Register(ctx context.Context, req RegisterRequest) error {
 	}
 
 	if err := s.repository.Save(ctx, user); err != nil {
+		if restoreErr := s.verifiedOTPRepository.SaveVerifiedOTP(ctx, req.PhoneNumber, int(RegisterState)); restoreErr != nil {
+			return fmt.Errorf("failed to save user: %w, and to restore verified otp: %v", err, restoreErr)
+		}
+
 		return fmt.Errorf("failed to save user: %w", err)
 	}
 
 	return nil
 }
 
-func NewService(userRepo Repository, otpRepo OTPRepository) *Service {
+func NewService(userRepo Repository, otpProvider OTPProvider, verifiedOTPRepo VerifiedOTPRepository, refreshTokenRepo RefreshTokenRepository, accessTokenRevocationRepo AccessTokenRevocationRepository, rateLimiter RateLimiter, bankAccountRepo BankAccountRepository, accountInquirer AccountInquirer) *Service {
 	return &Service{
-		otpRepository: otpRepo,
-		repository:    userRepo,
+		otpProvider:                     otpProvider,
+		verifiedOTPRepository:           verifiedOTPRepo,
+		repository:                      userRepo,
+		refreshTokenRepository:          refreshTokenRepo,
+		accessTokenRevocationRepository: accessTokenRevocationRepo,
+		rateLimiter:                     rateLimiter,
+		bankAccountRepository:           bankAccountRepo,
+		accountInquirer:                 accountInquirer,
 	}
 }
@@ -0,0 +1,188 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"rekber/config"
+	"rekber/ierr"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type fakeVerifiedOTPRepository struct {
+	VerifiedOTPRepository
+	verified map[string]bool
+}
+
+func (f *fakeVerifiedOTPRepository) SaveVerifiedOTP(ctx context.Context, phoneNumber string, state int) error {
+	f.verified[fmt.Sprintf("%s:%d", phoneNumber, state)] = true
+	return nil
+}
+
+func (f *fakeVerifiedOTPRepository) ConsumeVerifiedOTP(ctx context.Context, phoneNumber string, state int) error {
+	key := fmt.Sprintf("%s:%d", phoneNumber, state)
+	if !f.verified[key] {
+		return ierr.UserForbiddenAccess{PhoneNumber: phoneNumber}
+	}
+
+	delete(f.verified, key)
+	return nil
+}
+
+type fakeRepository struct {
+	Repository
+	users   map[string]User
+	saveErr error
+}
+
+func (f *fakeRepository) GetByPhoneNumber(ctx context.Context, phoneNumber string) (User, error) {
+	u, ok := f.users[phoneNumber]
+	if !ok {
+		return User{}, ierr.UserNotFound{PhoneNumber: phoneNumber}
+	}
+
+	return u, nil
+}
+
+func (f *fakeRepository) Save(ctx context.Context, u User) error {
+	if f.saveErr != nil {
+		return f.saveErr
+	}
+
+	f.users[u.PhoneNumber] = u
+	return nil
+}
+
+type fakeRefreshTokenRepository struct {
+	RefreshTokenRepository
+}
+
+func (f fakeRefreshTokenRepository) Save(ctx context.Context, r RefreshToken) error {
+	return nil
+}
+
+func TestService_Login(t *testing.T) {
+	config.Set(config.Config{
+		JWT: config.JWTConfig{
+			AccessToken:  config.TokenConfig{Duration: time.Hour, SecretKey: "test-secret-key"},
+			RefreshToken: config.TokenConfig{Duration: time.Hour, SecretKey: "test-secret-key"},
+		},
+	})
+
+	phoneNumber := "+6281234567890"
+
+	tests := []struct {
+		name         string
+		users        map[string]User
+		verified     bool
+		wantErr      error
+		wantVerified bool
+	}{
+		{
+			name:     "user with verified otp logs in and consumes it",
+			users:    map[string]User{phoneNumber: {ID: uuid.New(), PhoneNumber: phoneNumber}},
+			verified: true,
+		},
+		{
+			name:         "unknown user keeps the verified otp",
+			users:        map[string]User{},
+			verified:     true,
+			wantErr:      ierr.UserNotFound{PhoneNumber: phoneNumber},
+			wantVerified: true,
+		},
+		{
+			name:    "user without verified otp is refused",
+			users:   map[string]User{phoneNumber: {ID: uuid.New(), PhoneNumber: phoneNumber}},
+			wantErr: ierr.UserForbiddenAccess{PhoneNumber: phoneNumber},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifiedOTPs := &fakeVerifiedOTPRepository{verified: map[string]bool{}}
+			if tt.verified {
+				verifiedOTPs.SaveVerifiedOTP(context.Background(), phoneNumber, int(LoginState))
+			}
+			s := Service{
+				verifiedOTPRepository:  verifiedOTPs,
+				repository:             &fakeRepository{users: tt.users},
+				refreshTokenRepository: fakeRefreshTokenRepository{},
+			}
+
+			if _, err := s.Login(context.Background(), LoginRequest{PhoneNumber: phoneNumber}); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Service.Login() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := len(verifiedOTPs.verified) == 1; got != tt.wantVerified {
+				t.Errorf("Service.Login() verified otp kept = %v, want %v", got, tt.wantVerified)
+			}
+		})
+	}
+}
+
+func TestService_Register(t *testing.T) {
+	phoneNumber := "+6281234567890"
+	errDatabase := errors.New("database is down")
+
+	tests := []struct {
+		name         string
+		users        map[string]User
+		saveErr      error
+		verified     bool
+		wantErr      error
+		wantVerified bool
+		wantUser     bool
+	}{
+		{
+			name:     "user with verified otp is registered and consumes it",
+			users:    map[string]User{},
+			verified: true,
+			wantUser: true,
+		},
+		{
+			name:         "taken phone number keeps the verified otp",
+			users:        map[string]User{phoneNumber: {ID: uuid.New(), PhoneNumber: phoneNumber}},
+			verified:     true,
+			wantErr:      ierr.UserAlreadyExists{PhoneNumber: phoneNumber},
+			wantVerified: true,
+			wantUser:     true,
+		},
+		{
+			name:         "user which cannot be saved gives the verified otp back",
+			users:        map[string]User{},
+			saveErr:      errDatabase,
+			verified:     true,
+			wantErr:      errDatabase,
+			wantVerified: true,
+		},
+		{
+			name:    "user without verified otp is refused",
+			users:   map[string]User{},
+			wantErr: ierr.UserForbiddenAccess{PhoneNumber: phoneNumber},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifiedOTPs := &fakeVerifiedOTPRepository{verified: map[string]bool{}}
+			if tt.verified {
+				verifiedOTPs.SaveVerifiedOTP(context.Background(), phoneNumber, int(RegisterState))
+			}
+			repo := &fakeRepository{users: tt.users, saveErr: tt.saveErr}
+			s := Service{
+				verifiedOTPRepository: verifiedOTPs,
+				repository:            repo,
+			}
+
+			if err := s.Register(context.Background(), RegisterRequest{Name: "Budi Santoso", PhoneNumber: phoneNumber}); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Service.Register() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := len(verifiedOTPs.verified) == 1; got != tt.wantVerified {
+				t.Errorf("Service.Register() verified otp kept = %v, want %v", got, tt.wantVerified)
+			}
+			if _, got := repo.users[phoneNumber]; got != tt.wantUser {
+				t.Errorf("Service.Register() user saved = %v, want %v", got, tt.wantUser)
+			}
+		})
+	}
+}
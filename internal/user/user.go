@@ -15,10 +15,21 @@ type Bank struct {
 }
 
 type BankAccount struct {
-	ID     uuid.UUID
-	Number string
-	Name   string
-	Bank   Bank
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Number    string
+	Name      string
+	Bank      Bank
+	IsPrimary bool
+	// VerifiedAt is when the holder name was confirmed by the bank through account inquiry.
+	VerifiedAt time.Time
+	CreatedAt  time.Time
+}
+
+// IsVerified tells whether the holder name is confirmed by the bank, only a verified bank account
+// receives payout.
+func (b BankAccount) IsVerified() bool {
+	return b.ID != uuid.Nil && !b.VerifiedAt.IsZero()
 }
 
 type User struct {
@@ -27,21 +38,61 @@ type User struct {
 	Name                  string
 	PhoneNumberVerifiedAt time.Time
 	BankAccount           BankAccount
-	CreatedAt             time.Time
+	// IsAdmin allows the user to resolve disputes of any transaction.
+	IsAdmin   bool
+	CreatedAt time.Time
+}
+
+// AccessToken is the claims of an issued access token, used to revoke it before it expires.
+type AccessToken struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	IssuedAt  time.Time
+	ExpiredAt time.Time
+}
+
+// RefreshToken is the server side state of an issued refresh token. Every refresh
+// token rotated from the same login shares the same FamilyID.
+type RefreshToken struct {
+	ID        uuid.UUID
+	FamilyID  uuid.UUID
+	UserID    uuid.UUID
+	ExpiredAt time.Time
+	RotatedAt time.Time
+	RevokedAt time.Time
+	CreatedAt time.Time
+}
+
+func (r RefreshToken) IsRotated() bool {
+	return !r.RotatedAt.IsZero()
+}
+
+func (r RefreshToken) IsRevoked() bool {
+	return !r.RevokedAt.IsZero()
 }
 
 type token struct {
 	accessToken  string
 	refreshToken string
+	refresh      RefreshToken
 }
 
-func (u User) generateToken() (token, error) {
+// generateToken generates a new access and refresh token pair, the refresh token belongs to familyID.
+func (u User) generateToken(familyID uuid.UUID) (token, error) {
 	accessToken, err := u.generateAccessToken()
 	if err != nil {
 		return token{}, fmt.Errorf("failed to generate access token: %w", err)
 	}
 
-	refreshToken, err := u.generateRefreshToken()
+	refresh := RefreshToken{
+		ID:        uuid.New(),
+		FamilyID:  familyID,
+		UserID:    u.ID,
+		ExpiredAt: time.Now().Add(config.Get().JWT.RefreshToken.Duration),
+		CreatedAt: time.Now(),
+	}
+
+	refreshToken, err := u.generateRefreshToken(refresh)
 	if err != nil {
 		return token{}, fmt.Errorf("failed to generate refresh token: %w", err)
 	}
@@ -49,15 +100,22 @@ func (u User) generateToken() (token, error) {
 	return token{
 		accessToken:  accessToken,
 		refreshToken: refreshToken,
+		refresh:      refresh,
 	}, nil
 }
 
 func (u User) generateAccessToken() (string, error) {
-	expiredAt := jwt.NewNumericDate(time.Now().Add(config.Get().JWT.AccessToken.Duration))
+	now := time.Now()
+	expiredAt := jwt.NewNumericDate(now.Add(config.Get().JWT.AccessToken.Duration))
 	claims := jwt.MapClaims{
 		"user_id": u.ID.String(),
-		"exp":     expiredAt,
-		"iss":     config.Get().App.Name,
+		"jti":     uuid.New().String(),
+		"iat":     jwt.NewNumericDate(now),
+		// iat has second precision, a token issued right after logging out of all devices in the
+		// same second must not be revoked by it
+		"iat_ms": now.UnixMilli(),
+		"exp":    expiredAt,
+		"iss":    config.Get().App.Name,
 	}
 
 	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -68,12 +126,13 @@ func (u User) generateAccessToken() (string, error) {
 	return signedToken, nil
 }
 
-func (u User) generateRefreshToken() (string, error) {
-	expiredAt := jwt.NewNumericDate(time.Now().Add(config.Get().JWT.RefreshToken.Duration))
+func (u User) generateRefreshToken(r RefreshToken) (string, error) {
 	claims := jwt.MapClaims{
-		"user_id": u.ID.String(),
-		"exp":     expiredAt,
-		"iss":     config.Get().App.Name,
+		"user_id":   u.ID.String(),
+		"jti":       r.ID.String(),
+		"family_id": r.FamilyID.String(),
+		"exp":       jwt.NewNumericDate(r.ExpiredAt),
+		"iss":       config.Get().App.Name,
 	}
 
 	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -0,0 +1,109 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RegisterEndpointRequest struct {
+	URL string `json:"url"`
+}
+
+type EndpointResponse struct {
+	ID  uuid.UUID `json:"id"`
+	URL string    `json:"url"`
+	// Secret is only returned when the endpoint is registered.
+	Secret     string     `json:"secret,omitempty"`
+	Active     bool       `json:"active"`
+	CreatedAt  time.Time  `json:"created_at"`
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+}
+
+func newEndpointResponse(e Endpoint) EndpointResponse {
+	resp := EndpointResponse{
+		ID:        e.ID,
+		URL:       e.URL,
+		Active:    e.isActive(),
+		CreatedAt: e.CreatedAt,
+	}
+	if !e.DisabledAt.IsZero() {
+		disabledAt := e.DisabledAt
+		resp.DisabledAt = &disabledAt
+	}
+
+	return resp
+}
+
+type AttemptResponse struct {
+	ResponseCode int       `json:"response_code,omitempty"`
+	ResponseBody string    `json:"response_body,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	DurationMs   int64     `json:"duration_ms"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type DeliveryResponse struct {
+	ID               uuid.UUID         `json:"id"`
+	EndpointID       uuid.UUID         `json:"endpoint_id"`
+	EventID          uuid.UUID         `json:"event_id"`
+	EventType        string            `json:"event_type"`
+	Status           string            `json:"status"`
+	Attempts         int               `json:"attempts"`
+	NextAttemptAt    *time.Time        `json:"next_attempt_at,omitempty"`
+	LastResponseCode int               `json:"last_response_code,omitempty"`
+	LastError        string            `json:"last_error,omitempty"`
+	DeliveredAt      *time.Time        `json:"delivered_at,omitempty"`
+	FailedAt         *time.Time        `json:"failed_at,omitempty"`
+	CreatedAt        time.Time         `json:"created_at"`
+	Log              []AttemptResponse `json:"log"`
+}
+
+func newDeliveryResponse(d Delivery, attempts []Attempt) DeliveryResponse {
+	resp := DeliveryResponse{
+		ID:               d.ID,
+		EndpointID:       d.EndpointID,
+		EventID:          d.EventID,
+		EventType:        d.EventType,
+		Status:           string(d.Status),
+		Attempts:         d.Attempts,
+		LastResponseCode: d.LastResponseCode,
+		LastError:        d.LastError,
+		CreatedAt:        d.CreatedAt,
+		Log:              make([]AttemptResponse, 0, len(attempts)),
+	}
+	if d.Status == Pending {
+		nextAttemptAt := d.NextAttemptAt
+		resp.NextAttemptAt = &nextAttemptAt
+	}
+	if !d.DeliveredAt.IsZero() {
+		deliveredAt := d.DeliveredAt
+		resp.DeliveredAt = &deliveredAt
+	}
+	if !d.FailedAt.IsZero() {
+		failedAt := d.FailedAt
+		resp.FailedAt = &failedAt
+	}
+
+	for _, a := range attempts {
+		resp.Log = append(resp.Log, AttemptResponse{
+			ResponseCode: a.ResponseCode,
+			ResponseBody: a.ResponseBody,
+			Error:        a.Error,
+			DurationMs:   a.Duration.Milliseconds(),
+			CreatedAt:    a.CreatedAt,
+		})
+	}
+
+	return resp
+}
+
+// EventPayload is the body sent to partner endpoints.
+type EventPayload struct {
+	// ID is the same on every delivery of the event, partners deduplicate by it.
+	ID        uuid.UUID       `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
@@ -0,0 +1,317 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"rekber/ierr"
+	"rekber/internal/outbox"
+	"rekber/internal/transaction"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// transactionTopicPrefix is the prefix of the outbox topics of transaction events.
+const transactionTopicPrefix = "transaction."
+
+// Service lets partners, users whose shops embed the escrow, register endpoints to be told when
+// the status of their transactions changes.
+type Service struct {
+	repository Repository
+	sender     Sender
+	transactor Transactor
+}
+
+func (s Service) RegisterEndpoint(ctx context.Context, userID uuid.UUID, req RegisterEndpointRequest) (EndpointResponse, error) {
+	endpoints, err := s.repository.ListEndpoints(ctx, userID)
+	if err != nil {
+		return EndpointResponse{}, fmt.Errorf("failed to list endpoints: %w", err)
+	}
+
+	active := 0
+	for _, e := range endpoints {
+		if e.isActive() {
+			active++
+		}
+	}
+	if active >= maxEndpoints {
+		return EndpointResponse{}, ierr.WebhookEndpointNotValid{Reason: fmt.Sprintf("a partner may have at most %d active endpoints", maxEndpoints)}
+	}
+
+	e, err := newEndpoint(userID, req.URL)
+	if err != nil {
+		return EndpointResponse{}, err
+	}
+
+	if err := s.repository.SaveEndpoint(ctx, e); err != nil {
+		return EndpointResponse{}, fmt.Errorf("failed to save endpoint: %w", err)
+	}
+
+	resp := newEndpointResponse(e)
+	resp.Secret = e.Secret
+	return resp, nil
+}
+
+func (s Service) ListEndpoints(ctx context.Context, userID uuid.UUID) ([]EndpointResponse, error) {
+	endpoints, err := s.repository.ListEndpoints(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list endpoints: %w", err)
+	}
+
+	result := make([]EndpointResponse, 0, len(endpoints))
+	for _, e := range endpoints {
+		result = append(result, newEndpointResponse(e))
+	}
+
+	return result, nil
+}
+
+// DisableEndpoint stops sending events to the endpoint, its pending deliveries are given up once they are due.
+func (s Service) DisableEndpoint(ctx context.Context, userID, id uuid.UUID) (EndpointResponse, error) {
+	e, err := s.endpoint(ctx, userID, id)
+	if err != nil {
+		return EndpointResponse{}, err
+	}
+
+	if !e.isActive() {
+		return newEndpointResponse(e), nil
+	}
+
+	e.DisabledAt = time.Now()
+	if err := s.repository.UpdateEndpoint(ctx, e); err != nil {
+		return EndpointResponse{}, fmt.Errorf("failed to update endpoint: %w", err)
+	}
+
+	return newEndpointResponse(e), nil
+}
+
+// ListDeliveries returns the deliveries of the endpoint, newest first, with the log of their attempts.
+func (s Service) ListDeliveries(ctx context.Context, userID, endpointID uuid.UUID) ([]DeliveryResponse, error) {
+	if _, err := s.endpoint(ctx, userID, endpointID); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.repository.ListDeliveries(ctx, endpointID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deliveries: %w", err)
+	}
+
+	ids := make([]uuid.UUID, 0, len(deliveries))
+	for _, d := range deliveries {
+		ids = append(ids, d.ID)
+	}
+
+	attempts, err := s.repository.ListAttempts(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attempts: %w", err)
+	}
+
+	byDelivery := make(map[uuid.UUID][]Attempt)
+	for _, a := range attempts {
+		byDelivery[a.DeliveryID] = append(byDelivery[a.DeliveryID], a)
+	}
+
+	result := make([]DeliveryResponse, 0, len(deliveries))
+	for _, d := range deliveries {
+		result = append(result, newDeliveryResponse(d, byDelivery[d.ID]))
+	}
+
+	return result, nil
+}
+
+// Redeliver queues the delivery to be sent again by the next run of Deliver, whatever its status.
+func (s Service) Redeliver(ctx context.Context, userID, id uuid.UUID) (DeliveryResponse, error) {
+	var d Delivery
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if d, err = s.repository.LockDelivery(ctx, id); err != nil {
+			return err
+		}
+
+		e, err := s.endpoint(ctx, userID, d.EndpointID)
+		if errors.As(err, &ierr.WebhookEndpointNotFound{}) {
+			return ierr.WebhookDeliveryNotFound{ID: id}
+		}
+		if err != nil {
+			return err
+		}
+
+		if !e.isActive() {
+			return ierr.WebhookEndpointNotValid{Reason: "endpoint is disabled"}
+		}
+
+		d = d.redeliver()
+		if err := s.repository.UpdateDelivery(ctx, d); err != nil {
+			return fmt.Errorf("failed to update delivery: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return DeliveryResponse{}, err
+	}
+
+	attempts, err := s.repository.ListAttempts(ctx, []uuid.UUID{d.ID})
+	if err != nil {
+		return DeliveryResponse{}, fmt.Errorf("failed to list attempts: %w", err)
+	}
+
+	return newDeliveryResponse(d, attempts), nil
+}
+
+// Publish queues a delivery of the transaction event to every active endpoint of its buyer and
+// seller when the event changes the status. It is the outbox publisher of webhooks, so it is
+// called within the transaction of the relay and the deliveries are queued only if the message is
+// marked published.
+func (s Service) Publish(ctx context.Context, m outbox.Message) error {
+	if !strings.HasPrefix(m.Topic, transactionTopicPrefix) {
+		return nil
+	}
+
+	var event transaction.EventMessage
+	if err := json.Unmarshal(m.Payload, &event); err != nil {
+		return fmt.Errorf("failed to unmarshal transaction event: %w", err)
+	}
+
+	if event.Status == event.PreviousStatus {
+		return nil
+	}
+
+	endpoints, err := s.repository.ListActiveEndpoints(ctx, []uuid.UUID{event.BuyerID, event.SellerID})
+	if err != nil {
+		return fmt.Errorf("failed to list endpoints: %w", err)
+	}
+
+	if len(endpoints) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(EventPayload{
+		ID:        m.ID,
+		Type:      m.Topic,
+		CreatedAt: m.CreatedAt,
+		Data:      m.Payload,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	for _, e := range endpoints {
+		if err := s.repository.SaveDelivery(ctx, newDelivery(e.ID, m.ID, m.Topic, payload)); err != nil {
+			return fmt.Errorf("failed to save delivery: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Deliver sends every due delivery once and returns how many were attempted. A failed attempt is
+// logged and retried later, it is not returned as error.
+func (s Service) Deliver(ctx context.Context, maxAttempts int, backoff time.Duration) (int, error) {
+	delivered := 0
+	for {
+		err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			d, err := s.repository.LockDueDelivery(ctx, time.Now())
+			if err != nil {
+				return err
+			}
+
+			e, err := s.repository.GetEndpoint(ctx, d.EndpointID)
+			if err != nil {
+				return fmt.Errorf("failed to get endpoint: %w", err)
+			}
+
+			if !e.isActive() {
+				d = d.cancel("endpoint is disabled")
+			} else {
+				d, err = s.send(ctx, e, d, maxAttempts, backoff)
+				if err != nil {
+					return err
+				}
+			}
+
+			if err := s.repository.UpdateDelivery(ctx, d); err != nil {
+				return fmt.Errorf("failed to update delivery: %w", err)
+			}
+
+			return nil
+		})
+		if errors.As(err, &ierr.WebhookDeliveryNotFound{}) {
+			return delivered, nil
+		}
+
+		if err != nil {
+			return delivered, err
+		}
+
+		delivered++
+	}
+}
+
+// send makes one attempt of the delivery and logs it. Any 2xx response is a success.
+func (s Service) send(ctx context.Context, e Endpoint, d Delivery, maxAttempts int, backoff time.Duration) (Delivery, error) {
+	start := time.Now()
+	resp, sendErr := s.sender.Send(ctx, Request{
+		URL: e.URL,
+		Headers: map[string]string{
+			"Content-Type":         "application/json",
+			"X-Rekber-Event-ID":    d.EventID.String(),
+			"X-Rekber-Delivery-ID": d.ID.String(),
+			"X-Rekber-Timestamp":   strconv.FormatInt(start.Unix(), 10),
+			"X-Rekber-Signature":   sign(e.Secret, start, d.Payload),
+		},
+		Body: d.Payload,
+	})
+
+	a := Attempt{
+		ID:           uuid.New(),
+		DeliveryID:   d.ID,
+		ResponseCode: resp.StatusCode,
+		ResponseBody: logResponseBody(resp.Body),
+		Duration:     time.Since(start),
+		CreatedAt:    start,
+	}
+	if sendErr == nil && (resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices) {
+		sendErr = fmt.Errorf("endpoint responded with status code %d", resp.StatusCode)
+	}
+	if sendErr != nil {
+		a.Error = sendErr.Error()
+	}
+
+	if err := s.repository.SaveAttempt(ctx, a); err != nil {
+		return Delivery{}, fmt.Errorf("failed to save attempt: %w", err)
+	}
+
+	if sendErr != nil {
+		return d.fail(resp.StatusCode, sendErr, maxAttempts, backoff), nil
+	}
+
+	return d.succeed(resp.StatusCode), nil
+}
+
+// endpoint returns the endpoint of the partner, the endpoints of other partners are not found.
+func (s Service) endpoint(ctx context.Context, userID, id uuid.UUID) (Endpoint, error) {
+	e, err := s.repository.GetEndpoint(ctx, id)
+	if err != nil {
+		return Endpoint{}, fmt.Errorf("failed to get endpoint: %w", err)
+	}
+
+	if e.PartnerID != userID {
+		return Endpoint{}, ierr.WebhookEndpointNotFound{ID: id}
+	}
+
+	return e, nil
+}
+
+func NewService(repo Repository, sender Sender, transactor Transactor) *Service {
+	return &Service{
+		repository: repo,
+		sender:     sender,
+		transactor: transactor,
+	}
+}
@@ -0,0 +1,382 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"rekber/ierr"
+	"rekber/internal/outbox"
+	"rekber/internal/transaction"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/google/uuid"
+)
+
+type fakeRepository struct {
+	Repository
+	endpoints  map[uuid.UUID]Endpoint
+	deliveries map[uuid.UUID]Delivery
+	attempts   []Attempt
+}
+
+func (f *fakeRepository) GetEndpoint(ctx context.Context, id uuid.UUID) (Endpoint, error) {
+	e, ok := f.endpoints[id]
+	if !ok {
+		return Endpoint{}, ierr.WebhookEndpointNotFound{ID: id}
+	}
+
+	return e, nil
+}
+
+func (f *fakeRepository) ListActiveEndpoints(ctx context.Context, partnerIDs []uuid.UUID) ([]Endpoint, error) {
+	var result []Endpoint
+	for _, e := range f.endpoints {
+		for _, id := range partnerIDs {
+			if e.PartnerID == id && e.isActive() {
+				result = append(result, e)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (f *fakeRepository) SaveDelivery(ctx context.Context, d Delivery) error {
+	for _, v := range f.deliveries {
+		if v.EndpointID == d.EndpointID && v.EventID == d.EventID {
+			return nil
+		}
+	}
+
+	f.deliveries[d.ID] = d
+	return nil
+}
+
+func (f *fakeRepository) LockDelivery(ctx context.Context, id uuid.UUID) (Delivery, error) {
+	d, ok := f.deliveries[id]
+	if !ok {
+		return Delivery{}, ierr.WebhookDeliveryNotFound{ID: id}
+	}
+
+	return d, nil
+}
+
+func (f *fakeRepository) LockDueDelivery(ctx context.Context, now time.Time) (Delivery, error) {
+	for _, d := range f.deliveries {
+		if d.Status == Pending && !d.NextAttemptAt.After(now) {
+			return d, nil
+		}
+	}
+
+	return Delivery{}, ierr.WebhookDeliveryNotFound{}
+}
+
+func (f *fakeRepository) UpdateDelivery(ctx context.Context, d Delivery) error {
+	f.deliveries[d.ID] = d
+	return nil
+}
+
+func (f *fakeRepository) SaveAttempt(ctx context.Context, a Attempt) error {
+	f.attempts = append(f.attempts, a)
+	return nil
+}
+
+func (f *fakeRepository) ListAttempts(ctx context.Context, deliveryIDs []uuid.UUID) ([]Attempt, error) {
+	var result []Attempt
+	for _, a := range f.attempts {
+		for _, id := range deliveryIDs {
+			if a.DeliveryID == id {
+				result = append(result, a)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+type fakeSender struct {
+	resp     Response
+	err      error
+	requests []Request
+}
+
+func (f *fakeSender) Send(ctx context.Context, r Request) (Response, error) {
+	f.requests = append(f.requests, r)
+	return f.resp, f.err
+}
+
+type fakeTransactor struct{}
+
+func (fakeTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestService_Publish(t *testing.T) {
+	buyerUUID := uuid.New()
+	sellerUUID := uuid.New()
+	buyerEndpoint := Endpoint{ID: uuid.New(), PartnerID: buyerUUID, URL: "https://buyer.example.com/hooks"}
+	sellerEndpoint := Endpoint{ID: uuid.New(), PartnerID: sellerUUID, URL: "https://seller.example.com/hooks"}
+	disabledEndpoint := Endpoint{ID: uuid.New(), PartnerID: sellerUUID, URL: "https://old.example.com/hooks", DisabledAt: time.Now()}
+	otherEndpoint := Endpoint{ID: uuid.New(), PartnerID: uuid.New(), URL: "https://other.example.com/hooks"}
+
+	message := func(topic string, event transaction.EventMessage) outbox.Message {
+		m, err := outbox.NewMessage(topic, event.TransactionID.String(), event)
+		if err != nil {
+			t.Fatalf("failed to create message: %v", err)
+		}
+
+		return m
+	}
+	accepted := message("transaction.accept", transaction.EventMessage{TransactionID: uuid.New(), BuyerID: buyerUUID, SellerID: sellerUUID, PreviousStatus: "waiting for approval", Status: "waiting for payment"})
+	reminded := message("transaction.release_reminded", transaction.EventMessage{TransactionID: uuid.New(), BuyerID: buyerUUID, SellerID: sellerUUID, PreviousStatus: "done by seller", Status: "done by seller"})
+	other := outbox.Message{ID: uuid.New(), Topic: "payout.succeeded", Payload: []byte(`{}`)}
+
+	tests := []struct {
+		name          string
+		messages      []outbox.Message
+		wantEndpoints []uuid.UUID
+	}{
+		{
+			name:          "status change is delivered to active endpoints of buyer and seller",
+			messages:      []outbox.Message{accepted},
+			wantEndpoints: []uuid.UUID{buyerEndpoint.ID, sellerEndpoint.ID},
+		},
+		{
+			name:          "message published twice is delivered once",
+			messages:      []outbox.Message{accepted, accepted},
+			wantEndpoints: []uuid.UUID{buyerEndpoint.ID, sellerEndpoint.ID},
+		},
+		{
+			name:     "event without status change is not delivered",
+			messages: []outbox.Message{reminded},
+		},
+		{
+			name:     "message other than transaction event is not delivered",
+			messages: []outbox.Message{other},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepository{
+				endpoints: map[uuid.UUID]Endpoint{
+					buyerEndpoint.ID:    buyerEndpoint,
+					sellerEndpoint.ID:   sellerEndpoint,
+					disabledEndpoint.ID: disabledEndpoint,
+					otherEndpoint.ID:    otherEndpoint,
+				},
+				deliveries: map[uuid.UUID]Delivery{},
+			}
+			s := NewService(repo, &fakeSender{}, fakeTransactor{})
+
+			for _, m := range tt.messages {
+				if err := s.Publish(context.Background(), m); err != nil {
+					t.Fatalf("Service.Publish() error = %v", err)
+				}
+			}
+
+			if len(repo.deliveries) != len(tt.wantEndpoints) {
+				t.Fatalf("Service.Publish() queued %v deliveries, want %v", len(repo.deliveries), len(tt.wantEndpoints))
+			}
+
+			for _, id := range tt.wantEndpoints {
+				found := false
+				for _, d := range repo.deliveries {
+					if d.EndpointID != id {
+						continue
+					}
+
+					found = true
+					var payload EventPayload
+					if err := json.Unmarshal(d.Payload, &payload); err != nil {
+						t.Fatalf("failed to unmarshal payload: %v", err)
+					}
+					if payload.ID != accepted.ID || payload.Type != "transaction.accept" || d.Status != Pending {
+						t.Errorf("delivery to %v = %+v with payload %+v", id, d, payload)
+					}
+				}
+				if !found {
+					t.Errorf("Service.Publish() did not queue a delivery to %v", id)
+				}
+			}
+		})
+	}
+}
+
+func TestService_Deliver(t *testing.T) {
+	timeNow := time.Date(2023, 9, 30, 21, 0, 0, 0, time.UTC)
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time {
+		return timeNow
+	})
+	defer patches.Reset()
+
+	partnerUUID := uuid.New()
+	endpoint := Endpoint{ID: uuid.New(), PartnerID: partnerUUID, URL: "https://shop.example.com/hooks", Secret: "whsec_test"}
+	disabled := Endpoint{ID: uuid.New(), PartnerID: partnerUUID, URL: "https://old.example.com/hooks", Secret: "whsec_old", DisabledAt: timeNow}
+	payload := []byte(`{"id":"1"}`)
+
+	tests := []struct {
+		name         string
+		endpoint     Endpoint
+		resp         Response
+		sendErr      error
+		wantSent     bool
+		wantStatus   DeliveryStatus
+		wantCode     int
+		wantAttempts int
+	}{
+		{
+			name:         "2xx response succeeds",
+			endpoint:     endpoint,
+			resp:         Response{StatusCode: 204},
+			wantSent:     true,
+			wantStatus:   Succeeded,
+			wantCode:     204,
+			wantAttempts: 1,
+		},
+		{
+			name:         "other response is retried",
+			endpoint:     endpoint,
+			resp:         Response{StatusCode: 503, Body: []byte("maintenance")},
+			wantSent:     true,
+			wantStatus:   Pending,
+			wantCode:     503,
+			wantAttempts: 1,
+		},
+		{
+			name:         "unreachable endpoint is retried",
+			endpoint:     endpoint,
+			sendErr:      errors.New("connection refused"),
+			wantSent:     true,
+			wantStatus:   Pending,
+			wantAttempts: 1,
+		},
+		{
+			name:       "delivery to disabled endpoint is given up",
+			endpoint:   disabled,
+			wantStatus: Failed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDelivery(tt.endpoint.ID, uuid.New(), "transaction.accept", payload)
+			repo := &fakeRepository{
+				endpoints:  map[uuid.UUID]Endpoint{tt.endpoint.ID: tt.endpoint},
+				deliveries: map[uuid.UUID]Delivery{d.ID: d},
+			}
+			sender := &fakeSender{resp: tt.resp, err: tt.sendErr}
+			s := NewService(repo, sender, fakeTransactor{})
+
+			got, err := s.Deliver(context.Background(), 8, time.Minute)
+			if err != nil {
+				t.Fatalf("Service.Deliver() error = %v", err)
+			}
+			if got != 1 {
+				t.Errorf("Service.Deliver() = %v, want 1", got)
+			}
+
+			updated := repo.deliveries[d.ID]
+			if updated.Status != tt.wantStatus || updated.LastResponseCode != tt.wantCode || updated.Attempts != tt.wantAttempts {
+				t.Errorf("delivery = %+v, want status %v, response code %v, attempts %v", updated, tt.wantStatus, tt.wantCode, tt.wantAttempts)
+			}
+
+			if !tt.wantSent {
+				if len(sender.requests) != 0 || len(repo.attempts) != 0 {
+					t.Errorf("Service.Deliver() sent %v requests, want none", len(sender.requests))
+				}
+				return
+			}
+
+			if len(sender.requests) != 1 || len(repo.attempts) != 1 {
+				t.Fatalf("Service.Deliver() sent %v requests and logged %v attempts, want 1", len(sender.requests), len(repo.attempts))
+			}
+
+			r := sender.requests[0]
+			if r.URL != tt.endpoint.URL || string(r.Body) != string(payload) {
+				t.Errorf("request = %+v", r)
+			}
+			if r.Headers["X-Rekber-Timestamp"] != strconv.FormatInt(timeNow.Unix(), 10) || r.Headers["X-Rekber-Signature"] != sign(tt.endpoint.Secret, timeNow, payload) {
+				t.Errorf("request is not signed, headers = %v", r.Headers)
+			}
+			if r.Headers["X-Rekber-Event-ID"] != d.EventID.String() {
+				t.Errorf("request event id = %v, want %v", r.Headers["X-Rekber-Event-ID"], d.EventID)
+			}
+
+			if a := repo.attempts[0]; a.DeliveryID != d.ID || a.ResponseCode != tt.wantCode || a.ResponseBody != string(tt.resp.Body) || (a.Error == "") != (tt.wantStatus == Succeeded) {
+				t.Errorf("attempt = %+v", a)
+			}
+		})
+	}
+}
+
+func TestService_Redeliver(t *testing.T) {
+	timeNow := time.Date(2023, 9, 30, 21, 0, 0, 0, time.UTC)
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time {
+		return timeNow
+	})
+	defer patches.Reset()
+
+	partnerUUID := uuid.New()
+	endpoint := Endpoint{ID: uuid.New(), PartnerID: partnerUUID, URL: "https://shop.example.com/hooks"}
+	disabled := Endpoint{ID: uuid.New(), PartnerID: partnerUUID, URL: "https://old.example.com/hooks", DisabledAt: timeNow}
+
+	failed := Delivery{ID: uuid.New(), EndpointID: endpoint.ID, Status: Failed, Attempts: 8, LastResponseCode: 500, FailedAt: timeNow.Add(-time.Hour)}
+	toDisabled := Delivery{ID: uuid.New(), EndpointID: disabled.ID, Status: Failed, Attempts: 8}
+
+	tests := []struct {
+		name     string
+		userID   uuid.UUID
+		delivery Delivery
+		wantErr  error
+	}{
+		{
+			name:     "failed delivery is sent again",
+			userID:   partnerUUID,
+			delivery: failed,
+		},
+		{
+			name:     "delivery of another partner",
+			userID:   uuid.New(),
+			delivery: failed,
+			wantErr:  ierr.WebhookDeliveryNotFound{ID: failed.ID},
+		},
+		{
+			name:     "delivery to disabled endpoint",
+			userID:   partnerUUID,
+			delivery: toDisabled,
+			wantErr:  ierr.WebhookEndpointNotValid{Reason: "endpoint is disabled"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepository{
+				endpoints:  map[uuid.UUID]Endpoint{endpoint.ID: endpoint, disabled.ID: disabled},
+				deliveries: map[uuid.UUID]Delivery{tt.delivery.ID: tt.delivery},
+				attempts:   []Attempt{{ID: uuid.New(), DeliveryID: tt.delivery.ID, ResponseCode: 500}},
+			}
+			s := NewService(repo, &fakeSender{}, fakeTransactor{})
+
+			got, err := s.Redeliver(context.Background(), tt.userID, tt.delivery.ID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Service.Redeliver() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if !reflect.DeepEqual(repo.deliveries[tt.delivery.ID], tt.delivery) {
+					t.Errorf("Service.Redeliver() changed the delivery to %+v", repo.deliveries[tt.delivery.ID])
+				}
+				return
+			}
+
+			updated := repo.deliveries[tt.delivery.ID]
+			if updated.Status != Pending || updated.Attempts != 0 || !updated.NextAttemptAt.Equal(timeNow) || !updated.FailedAt.IsZero() {
+				t.Errorf("delivery = %+v, want pending from now", updated)
+			}
+			if got.Status != string(Pending) || len(got.Log) != 1 {
+				t.Errorf("Service.Redeliver() = %+v, want pending with the earlier attempt logged", got)
+			}
+		})
+	}
+}
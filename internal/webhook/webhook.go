@@ -0,0 +1,331 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"rekber/ierr"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// maxBackoff caps the exponential backoff between delivery attempts.
+	maxBackoff = 6 * time.Hour
+	// maxEndpoints is how many active endpoints a partner may register.
+	maxEndpoints = 10
+	secretPrefix = "whsec_"
+	// maxResponseBody is how much of the response of an endpoint is logged.
+	maxResponseBody = 1024
+)
+
+// Endpoint is a URL of a partner which receives the status changes of the partner's transactions.
+type Endpoint struct {
+	ID        uuid.UUID
+	PartnerID uuid.UUID
+	URL       string
+	// Secret signs every payload sent to the endpoint, it is shown to the partner only on registration.
+	Secret     string
+	CreatedAt  time.Time
+	DisabledAt time.Time
+}
+
+func (e Endpoint) isActive() bool {
+	return e.DisabledAt.IsZero()
+}
+
+func newEndpoint(partnerID uuid.UUID, rawURL string) (Endpoint, error) {
+	if err := validateURL(rawURL); err != nil {
+		return Endpoint{}, err
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return Endpoint{}, err
+	}
+
+	return Endpoint{
+		ID:        uuid.New(),
+		PartnerID: partnerID,
+		URL:       rawURL,
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// validateURL accepts an absolute https url whose host resolves only to public addresses, so an
+// endpoint cannot make the sender post to the internal network.
+func validateURL(rawURL string) error {
+	if rawURL == "" {
+		return ierr.WebhookEndpointNotValid{Reason: "url is required"}
+	}
+
+	if len(rawURL) > 2048 {
+		return ierr.WebhookEndpointNotValid{Reason: "url is longer than 2048 characters"}
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+		return ierr.WebhookEndpointNotValid{Reason: "url should be an absolute https url"}
+	}
+
+	if u.User != nil {
+		return ierr.WebhookEndpointNotValid{Reason: "url should not contain credentials"}
+	}
+
+	ips, err := net.LookupIP(u.Hostname())
+	if err != nil || len(ips) == 0 {
+		return ierr.WebhookEndpointNotValid{Reason: "url host cannot be resolved"}
+	}
+
+	for _, ip := range ips {
+		if !IsPublicIP(ip) {
+			return ierr.WebhookEndpointNotValid{Reason: "url host should resolve to a public address"}
+		}
+	}
+
+	return nil
+}
+
+// nonPublicPrefixes are the blocks of the IANA IPv4 and IPv6 special-purpose address registries
+// which are not globally reachable, along with multicast and the reserved blocks.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // this network
+	netip.MustParsePrefix("10.0.0.0/8"),      // private
+	netip.MustParsePrefix("100.64.0.0/10"),   // shared address space, carrier-grade nat
+	netip.MustParsePrefix("127.0.0.0/8"),     // loopback
+	netip.MustParsePrefix("169.254.0.0/16"),  // link local
+	netip.MustParsePrefix("172.16.0.0/12"),   // private
+	netip.MustParsePrefix("192.0.0.0/24"),    // ietf protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // documentation
+	netip.MustParsePrefix("192.88.99.0/24"),  // 6to4 relay anycast
+	netip.MustParsePrefix("192.168.0.0/16"),  // private
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // documentation
+	netip.MustParsePrefix("224.0.0.0/4"),     // multicast
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved, including limited broadcast
+	netip.MustParsePrefix("::/128"),          // unspecified
+	netip.MustParsePrefix("::1/128"),         // loopback
+	netip.MustParsePrefix("64:ff9b::/96"),    // ipv4-ipv6 translation
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // local ipv4-ipv6 translation
+	netip.MustParsePrefix("100::/64"),        // discard only
+	netip.MustParsePrefix("2001::/23"),       // ietf protocol assignments, including teredo
+	netip.MustParsePrefix("2001:db8::/32"),   // documentation
+	netip.MustParsePrefix("2002::/16"),       // 6to4
+	netip.MustParsePrefix("3fff::/20"),       // documentation
+	netip.MustParsePrefix("5f00::/16"),       // segment routing
+	netip.MustParsePrefix("fc00::/7"),        // unique local
+	netip.MustParsePrefix("fe80::/10"),       // link local
+	netip.MustParsePrefix("fec0::/10"),       // site local
+	netip.MustParsePrefix("ff00::/8"),        // multicast
+}
+
+// IsPublicIP reports whether ip is reachable on the internet, i.e. it is in none of the
+// nonPublicPrefixes. An ipv4 mapped ipv6 address is checked as the ipv4 address it carries. The
+// sender must only connect to public addresses.
+func IsPublicIP(ip net.IP) bool {
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return false
+	}
+
+	addr = addr.Unmap()
+	for _, p := range nonPublicPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	return secretPrefix + hex.EncodeToString(b), nil
+}
+
+// sign returns the signature of the payload sent at timestamp. Partners compute the HMAC-SHA256 of
+// the timestamp, a dot and the raw body with their secret, and reject payloads whose timestamp is
+// too old so a captured request cannot be replayed.
+func sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type DeliveryStatus string
+
+const (
+	Pending   DeliveryStatus = "pending"
+	Succeeded DeliveryStatus = "succeeded"
+	// Failed deliveries ran out of attempts or their endpoint was disabled, they are only sent again
+	// when the partner asks for a redelivery.
+	Failed DeliveryStatus = "failed"
+)
+
+// Delivery is an event to be sent to an endpoint, it keeps the body so every attempt sends the same bytes.
+type Delivery struct {
+	ID         uuid.UUID
+	EndpointID uuid.UUID
+	// EventID is the id of the outbox message, partners deduplicate deliveries by it.
+	EventID          uuid.UUID
+	EventType        string
+	Payload          []byte
+	Status           DeliveryStatus
+	Attempts         int
+	NextAttemptAt    time.Time
+	LastResponseCode int
+	LastError        string
+	DeliveredAt      time.Time
+	FailedAt         time.Time
+	CreatedAt        time.Time
+}
+
+func newDelivery(endpointID, eventID uuid.UUID, eventType string, payload []byte) Delivery {
+	now := time.Now()
+	return Delivery{
+		ID:            uuid.New(),
+		EndpointID:    endpointID,
+		EventID:       eventID,
+		EventType:     eventType,
+		Payload:       payload,
+		Status:        Pending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+}
+
+func (d Delivery) succeed(responseCode int) Delivery {
+	d.Attempts++
+	d.Status = Succeeded
+	d.LastResponseCode = responseCode
+	d.LastError = ""
+	d.DeliveredAt = time.Now()
+
+	return d
+}
+
+// fail records a failed attempt, the delivery is retried with exponential backoff until maxAttempts is reached.
+func (d Delivery) fail(responseCode int, err error, maxAttempts int, backoff time.Duration) Delivery {
+	d.Attempts++
+	d.LastResponseCode = responseCode
+	d.LastError = err.Error()
+
+	if d.Attempts >= maxAttempts {
+		d.Status = Failed
+		d.FailedAt = time.Now()
+		return d
+	}
+
+	delay := backoff << (d.Attempts - 1)
+	if delay <= 0 || delay > maxBackoff {
+		delay = maxBackoff
+	}
+
+	d.NextAttemptAt = time.Now().Add(delay)
+	return d
+}
+
+// cancel gives up the delivery without sending it, e.g. because its endpoint is disabled.
+func (d Delivery) cancel(reason string) Delivery {
+	d.Status = Failed
+	d.LastError = reason
+	d.FailedAt = time.Now()
+
+	return d
+}
+
+// redeliver queues the delivery to be sent right away with a fresh set of attempts. Earlier
+// attempts stay in the log.
+func (d Delivery) redeliver() Delivery {
+	d.Status = Pending
+	d.Attempts = 0
+	d.NextAttemptAt = time.Now()
+	d.DeliveredAt = time.Time{}
+	d.FailedAt = time.Time{}
+
+	return d
+}
+
+// Attempt is a request sent for a delivery and how the endpoint responded to it.
+type Attempt struct {
+	ID         uuid.UUID
+	DeliveryID uuid.UUID
+	// ResponseCode is zero when the endpoint could not be reached.
+	ResponseCode int
+	ResponseBody string
+	Error        string
+	Duration     time.Duration
+	CreatedAt    time.Time
+}
+
+// logResponseBody returns the start of the body as text which can be stored and shown to the partner.
+func logResponseBody(b []byte) string {
+	if len(b) > maxResponseBody {
+		b = b[:maxResponseBody]
+	}
+
+	return strings.ReplaceAll(strings.ToValidUTF8(string(b), ""), "\x00", "")
+}
+
+// Request is what is sent to an endpoint.
+type Request struct {
+	URL     string
+	Headers map[string]string
+	Body    []byte
+}
+
+type Response struct {
+	StatusCode int
+	Body       []byte
+}
+
+// Sender sends requests to partner endpoints. It returns an error only when no response was
+// received, any response is returned whatever its status code.
+type Sender interface {
+	Send(ctx context.Context, r Request) (Response, error)
+}
+
+type Repository interface {
+	SaveEndpoint(ctx context.Context, e Endpoint) error
+	// GetEndpoint returns the endpoint, or ierr.WebhookEndpointNotFound.
+	GetEndpoint(ctx context.Context, id uuid.UUID) (Endpoint, error)
+	ListEndpoints(ctx context.Context, partnerID uuid.UUID) ([]Endpoint, error)
+	ListActiveEndpoints(ctx context.Context, partnerIDs []uuid.UUID) ([]Endpoint, error)
+	UpdateEndpoint(ctx context.Context, e Endpoint) error
+	// SaveDelivery ignores a delivery of an event the endpoint has already got, so an event
+	// published more than once is delivered once.
+	SaveDelivery(ctx context.Context, d Delivery) error
+	// LockDelivery locks the delivery, waiting for a sender working on it, or returns
+	// ierr.WebhookDeliveryNotFound. It must be called within a transaction.
+	LockDelivery(ctx context.Context, id uuid.UUID) (Delivery, error)
+	// LockDueDelivery locks the oldest pending delivery due at now, skipping deliveries locked by
+	// other senders. It returns ierr.WebhookDeliveryNotFound when there is none, it must be called
+	// within a transaction.
+	LockDueDelivery(ctx context.Context, now time.Time) (Delivery, error)
+	ListDeliveries(ctx context.Context, endpointID uuid.UUID) ([]Delivery, error)
+	UpdateDelivery(ctx context.Context, d Delivery) error
+	SaveAttempt(ctx context.Context, a Attempt) error
+	ListAttempts(ctx context.Context, deliveryIDs []uuid.UUID) ([]Attempt, error)
+}
+
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -1,37 +1,314 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"os"
 	"rekber/config"
+	"rekber/courier"
+	"rekber/disbursement"
 	"rekber/firebase"
 	"rekber/http"
+	attachmentHandlerHTTP "rekber/http/attachment"
+	transactionHandlerHTTP "rekber/http/transaction"
 	userHandlerHTTP "rekber/http/user"
+	webhookHandlerHTTP "rekber/http/webhook"
+	"rekber/inmemory"
+	attachmentService "rekber/internal/attachment"
+	ledgerService "rekber/internal/ledger"
+	outboxService "rekber/internal/outbox"
+	transactionService "rekber/internal/transaction"
 	userService "rekber/internal/user"
+	webhookService "rekber/internal/webhook"
+	"rekber/notification"
+	"rekber/payment"
 	"rekber/postgres"
+	attachmentRepository "rekber/postgres/attachment"
+	ledgerRepository "rekber/postgres/ledger"
+	otpRepository "rekber/postgres/otp"
+	outboxRepository "rekber/postgres/outbox"
+	tokenRepository "rekber/postgres/token"
+	transactionRepository "rekber/postgres/transaction"
 	userRepository "rekber/postgres/user"
+	webhookRepository "rekber/postgres/webhook"
+	"rekber/publisher"
+	"rekber/redis"
+	redisOTPRepository "rekber/redis/otp"
+	"rekber/storage"
+	"rekber/webhook"
+	"rekber/worker"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/jmoiron/sqlx"
+	goredis "github.com/redis/go-redis/v9"
 )
 
+const bodyLimitMargin = 1 << 20
+
 type HTTPHandler interface {
 	InitRouter(r fiber.Router)
 }
 
-func initHTTPHandlers(db *sqlx.DB) []HTTPHandler {
-	fbClient := firebase.NewClient(config.Get().Firebase.APIKey, firebase.WithAuth(config.Get().Firebase.AuthURL))
+var redisClient *goredis.Client
+
+// initRedisClient connects to redis on first use, so it is only required when a store is configured to use it.
+func initRedisClient() *goredis.Client {
+	if redisClient == nil {
+		redisClient = redis.InitClient(config.Get().Redis.Addr, config.Get().Redis.Password, config.Get().Redis.DB)
+	}
+
+	return redisClient
+}
+
+func initOTPProvider(db *sqlx.DB) userService.OTPProvider {
+	switch config.Get().OTP.Provider {
+	case "local":
+		return otpRepository.NewProvider(db, config.Get().OTP.Local.TTL)
+	case "firebase", "":
+		return firebase.NewClient(config.Get().Firebase.APIKey, firebase.WithAuth(config.Get().Firebase.AuthURL))
+	default:
+		log.Fatalf("unknown otp provider: %s", config.Get().OTP.Provider)
+		return nil
+	}
+}
+
+func initVerifiedOTPRepository(db *sqlx.DB) userService.VerifiedOTPRepository {
+	ttl := config.Get().OTP.VerifiedTTL
+	switch config.Get().OTP.VerifiedStore {
+	case "postgres", "":
+		return otpRepository.NewVerifiedOTPRepository(db, ttl)
+	case "redis":
+		return redisOTPRepository.NewVerifiedOTPRepository(initRedisClient(), ttl)
+	case "memory":
+		return inmemory.NewVerifiedOTPStore(ttl)
+	default:
+		log.Fatalf("unknown verified otp store: %s", config.Get().OTP.VerifiedStore)
+		return nil
+	}
+}
+
+func initOTPRateLimiter(db *sqlx.DB) userService.RateLimiter {
+	switch config.Get().OTP.RateLimit.Store {
+	case "postgres", "":
+		return otpRepository.NewRateLimiter(db)
+	case "redis":
+		return redisOTPRepository.NewRateLimiter(initRedisClient())
+	case "memory":
+		return inmemory.NewRateLimiter()
+	default:
+		log.Fatalf("unknown otp rate limit store: %s", config.Get().OTP.RateLimit.Store)
+		return nil
+	}
+}
+
+// disbursementProvider looks up bank accounts for sellers and transfers their payouts.
+type disbursementProvider interface {
+	userService.AccountInquirer
+	transactionService.Disburser
+}
+
+func initDisbursementProvider() disbursementProvider {
+	switch config.Get().Disbursement.Provider {
+	case "local", "":
+		var options []disbursement.LocalOptions
+		for _, a := range config.Get().Disbursement.Local.Accounts {
+			options = append(options, disbursement.WithAccount(a.BankCode, a.Number, a.HolderName))
+		}
+
+		return disbursement.NewLocalClient(options...)
+	default:
+		log.Fatalf("unknown disbursement provider: %s", config.Get().Disbursement.Provider)
+		return nil
+	}
+}
+
+func initPaymentProvider() transactionService.PaymentProvider {
+	switch config.Get().Payment.Provider {
+	case "local", "":
+		return payment.NewLocalClient(config.Get().Payment.CallbackSecret, config.Get().Payment.ChargeTTL)
+	default:
+		log.Fatalf("unknown payment provider: %s", config.Get().Payment.Provider)
+		return nil
+	}
+}
+
+func initNotifier() transactionService.Notifier {
+	switch config.Get().Notification.Provider {
+	case "log", "":
+		return notification.NewLogNotifier()
+	default:
+		log.Fatalf("unknown notification provider: %s", config.Get().Notification.Provider)
+		return nil
+	}
+}
+
+func initCourierTracker() transactionService.CourierTracker {
+	switch config.Get().Shipment.Courier.Provider {
+	case "local", "":
+		return courier.NewLocalTracker(config.Get().Shipment.Courier.Local.DeliverAfter)
+	default:
+		log.Fatalf("unknown courier provider: %s", config.Get().Shipment.Courier.Provider)
+		return nil
+	}
+}
+
+func initOutboxPublisher() outboxService.Publisher {
+	cfg := config.Get().Outbox.Publisher
+	switch cfg.Provider {
+	case "log", "":
+		return publisher.NewLogPublisher()
+	case "webhook":
+		return publisher.NewWebhookPublisher(cfg.Webhook.URL, cfg.Webhook.Timeout)
+	case "nats":
+		return publisher.NewNATSPublisher(cfg.NATS.Addr, cfg.NATS.SubjectPrefix, cfg.NATS.Timeout)
+	default:
+		log.Fatalf("unknown outbox publisher: %s", cfg.Provider)
+		return nil
+	}
+}
+
+func initAttachmentStorage() attachmentService.Storage {
+	cfg := config.Get().Attachment
+	switch cfg.Storage {
+	case "local", "":
+		return storage.NewLocalStorage(cfg.Local.Dir)
+	case "s3":
+		return storage.NewS3Storage(cfg.S3.Endpoint, cfg.S3.Region, cfg.S3.Bucket, cfg.S3.AccessKey, cfg.S3.SecretKey)
+	default:
+		log.Fatalf("unknown attachment storage: %s", cfg.Storage)
+		return nil
+	}
+}
+
+func initWebhookService(db *sqlx.DB) *webhookService.Service {
+	return webhookService.NewService(webhookRepository.NewRepository(db), webhook.NewHTTPSender(config.Get().Webhook.Timeout), postgres.NewTransactor(db))
+}
+
+// initOutboxService publishes to the configured publisher and queues deliveries to partner webhooks,
+// each as its own consumer so one failing does not block nor repeat the other.
+func initOutboxService(db *sqlx.DB, webhookSvc *webhookService.Service) *outboxService.Service {
+	publishers := map[string]outboxService.Publisher{
+		"broker":  initOutboxPublisher(),
+		"webhook": webhookSvc,
+	}
+
+	return outboxService.NewService(outboxRepository.NewRepository(db), publishers, postgres.NewTransactor(db))
+}
+
+func initTransactionService(db *sqlx.DB, outboxSvc *outboxService.Service) *transactionService.Service {
+	transactionRepo := transactionRepository.NewRepository(db)
+	return transactionService.NewService(transactionService.Dependencies{
+		Repository:         transactionRepo,
+		UserRepository:     userRepository.NewRepository(db),
+		PaymentRepository:  transactionRepo,
+		PaymentProvider:    initPaymentProvider(),
+		Transactor:         postgres.NewTransactor(db),
+		PayoutRepository:   transactionRepo,
+		Disburser:          initDisbursementProvider(),
+		Ledger:             ledgerService.NewService(ledgerRepository.NewRepository(db)),
+		Notifier:           initNotifier(),
+		RefundRepository:   transactionRepo,
+		ShipmentRepository: transactionRepo,
+		CourierTracker:     initCourierTracker(),
+		TimelineRepository: transactionRepo,
+		EventStore:         transactionRepo,
+		Outbox:             outboxSvc,
+		Deadlines:          deadlines(),
+		DisputeWindow:      config.Get().Dispute.ResponseWindow,
+	})
+}
+
+func deadlines() transactionService.Deadlines {
+	return transactionService.Deadlines{
+		Approval: config.Get().Expiry.ApprovalWindow,
+		Payment:  config.Get().Expiry.PaymentWindow,
+	}
+}
+
+func startWorkers(ctx context.Context, transactionSvc *transactionService.Service, outboxSvc *outboxService.Service, webhookSvc *webhookService.Service) {
+	go worker.Run(ctx, "payout", config.Get().Payout.Interval, func(ctx context.Context) error {
+		_, err := transactionSvc.ProcessPayouts(ctx, config.Get().Payout.MaxAttempts, config.Get().Payout.Backoff)
+		return err
+	})
+
+	go worker.Run(ctx, "refund", config.Get().Refund.Interval, func(ctx context.Context) error {
+		_, err := transactionSvc.ProcessRefunds(ctx, config.Get().Refund.MaxAttempts, config.Get().Refund.Backoff)
+		return err
+	})
+
+	go worker.Run(ctx, "expiry", config.Get().Expiry.Interval, func(ctx context.Context) error {
+		_, err := transactionSvc.ExpireOverdue(ctx, deadlines())
+		return err
+	})
+
+	go worker.Run(ctx, "release", config.Get().Inspection.Interval, func(ctx context.Context) error {
+		_, err := transactionSvc.AutoRelease(ctx, transactionService.Inspection{
+			Period:          config.Get().Inspection.Period,
+			RemindBefore:    config.Get().Inspection.RemindBefore,
+			DeliveryTimeout: config.Get().Inspection.DeliveryTimeout,
+		})
+		return err
+	})
+
+	go worker.Run(ctx, "dispute", config.Get().Dispute.Interval, func(ctx context.Context) error {
+		_, err := transactionSvc.EscalateOverdueDisputes(ctx)
+		return err
+	})
+
+	go worker.Run(ctx, "shipment", config.Get().Shipment.Interval, func(ctx context.Context) error {
+		_, err := transactionSvc.TrackShipments(ctx, config.Get().Shipment.CheckEvery)
+		return err
+	})
+
+	go worker.Run(ctx, "outbox", config.Get().Outbox.Interval, func(ctx context.Context) error {
+		_, err := outboxSvc.Relay(ctx, config.Get().Outbox.MaxAttempts, config.Get().Outbox.Backoff)
+		return err
+	})
+
+	go worker.Run(ctx, "webhook", config.Get().Webhook.Interval, func(ctx context.Context) error {
+		_, err := webhookSvc.Deliver(ctx, config.Get().Webhook.MaxAttempts, config.Get().Webhook.Backoff)
+		return err
+	})
+}
+
+func initHTTPHandlers(db *sqlx.DB, transactionSvc *transactionService.Service, webhookSvc *webhookService.Service) []HTTPHandler {
 	userRepo := userRepository.NewRepository(db)
-	userSvc := userService.NewService(userRepo, fbClient)
-	userHandler := userHandlerHTTP.NewHandler(userSvc)
+	tokenRepo := tokenRepository.NewRepository(db)
+	revocationCache := inmemory.NewRevocationCache(tokenRepo, config.Get().JWT.RevocationCacheTTL)
+	authMiddleware := http.NewAuthMiddleware(revocationCache)
+
+	userSvc := userService.NewService(userRepo, initOTPProvider(db), initVerifiedOTPRepository(db), tokenRepo, revocationCache, initOTPRateLimiter(db), userRepo, initDisbursementProvider())
+	userHandler := userHandlerHTTP.NewHandler(userSvc, authMiddleware)
+
+	transactionHandler := transactionHandlerHTTP.NewHandler(transactionSvc, authMiddleware)
+
+	attachmentSvc := attachmentService.NewService(attachmentRepository.NewRepository(db), initAttachmentStorage(), config.Get().Attachment.MaxSize)
+	attachmentHandler := attachmentHandlerHTTP.NewHandler(attachmentSvc, transactionSvc, authMiddleware)
+
+	webhookHandler := webhookHandlerHTTP.NewHandler(webhookSvc, authMiddleware)
 
 	return []HTTPHandler{
 		userHandler,
+		transactionHandler,
+		attachmentHandler,
+		webhookHandler,
 	}
 }
 
+// rebuildProjections rebuilds the transactions table from the event store, it is run with the
+// rebuild-projections argument while the app is not serving.
+func rebuildProjections(transactionSvc *transactionService.Service) {
+	rebuilt, err := transactionSvc.RebuildProjections(context.Background())
+	if err != nil {
+		log.Fatalf("failed to rebuild projections after %d transactions: %v", rebuilt, err)
+	}
+
+	log.Printf("rebuilt %d transactions from their events", rebuilt)
+}
+
 func main() {
 	config.SetFromFile("development")
 
@@ -44,11 +321,24 @@ func main() {
 		config.Get().PSQL.SSLMode,
 	)
 
+	webhookSvc := initWebhookService(db)
+	outboxSvc := initOutboxService(db, webhookSvc)
+	transactionSvc := initTransactionService(db, outboxSvc)
+	if len(os.Args) > 1 && os.Args[1] == "rebuild-projections" {
+		rebuildProjections(transactionSvc)
+		return
+	}
+
 	app := fiber.New(fiber.Config{
 		// Override default error handler
 		ErrorHandler: http.ErrorHandler,
+		// leaves room for the multipart envelope around the largest attachment
+		BodyLimit: int(config.Get().Attachment.MaxSize) + bodyLimitMargin,
 	})
-	app.Use(logger.New())
+	app.Use(http.NewRequestIDMiddleware())
+	app.Use(logger.New(logger.Config{
+		Format: "[${time}] ${status} - ${latency} ${method} ${path} ${respHeader:X-Request-ID}\n",
+	}))
 	app.Get("/health", func(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
 			"data": "OK",
@@ -58,7 +348,9 @@ func main() {
 	api := app.Group("/api")
 	v1 := api.Group("/v1")
 
-	httpHandlers := initHTTPHandlers(db)
+	startWorkers(context.Background(), transactionSvc, outboxSvc, webhookSvc)
+
+	httpHandlers := initHTTPHandlers(db, transactionSvc, webhookSvc)
 	for _, v := range httpHandlers {
 		v.InitRouter(v1)
 	}
@@ -0,0 +1,20 @@
+package notification
+
+import (
+	"context"
+	"log"
+	"rekber/internal/transaction"
+)
+
+// LogNotifier is a notifier for development and testing, it logs the notification instead of
+// sending it to the user.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, n transaction.Notification) error {
+	log.Printf("notify user %s of %s on transaction %s: %s", n.UserID, n.Kind, n.TransactionID, n.Message)
+	return nil
+}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
@@ -0,0 +1,108 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"rekber/ierr"
+	"rekber/internal/transaction"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const virtualAccountDigits = 12
+
+// LocalClient is a stub payment provider for development and testing. It creates charges without
+// calling any provider and accepts callbacks signed with the shared secret, use Sign to simulate one.
+type LocalClient struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+// Callback is the body of a payment callback sent by the provider.
+type Callback struct {
+	ExternalID string    `json:"external_id"`
+	Amount     int64     `json:"amount"`
+	Currency   string    `json:"currency"`
+	Status     string    `json:"status"`
+	PaidAt     time.Time `json:"paid_at"`
+}
+
+func (c *LocalClient) CreateCharge(ctx context.Context, p transaction.Payment) (transaction.Charge, error) {
+	charge := transaction.Charge{
+		ExternalID: "local-" + uuid.NewString(),
+		ExpiredAt:  time.Now().Add(c.ttl),
+	}
+	if !p.ExpiredAt.IsZero() && p.ExpiredAt.Before(charge.ExpiredAt) {
+		charge.ExpiredAt = p.ExpiredAt
+	}
+
+	switch p.Method {
+	case transaction.VirtualAccount:
+		number, err := randomDigits(virtualAccountDigits)
+		if err != nil {
+			return transaction.Charge{}, fmt.Errorf("failed to generate virtual account number: %w", err)
+		}
+
+		charge.VirtualAccountNumber = p.BankCode + number
+	case transaction.QRIS:
+		charge.QRString = fmt.Sprintf("LOCALQRIS|%s|%d|%s", charge.ExternalID, p.Amount.Amount, p.Amount.Currency)
+	default:
+		return transaction.Charge{}, ierr.PaymentMethodNotValid{Method: string(p.Method)}
+	}
+
+	return charge, nil
+}
+
+func (c *LocalClient) ParseCallback(body []byte, signature string) (transaction.PaymentCallback, error) {
+	if !hmac.Equal([]byte(c.Sign(body)), []byte(signature)) {
+		return transaction.PaymentCallback{}, ierr.PaymentSignatureNotValid{}
+	}
+
+	var cb Callback
+	if err := json.Unmarshal(body, &cb); err != nil {
+		return transaction.PaymentCallback{}, fmt.Errorf("failed to decode callback: %w", err)
+	}
+
+	return transaction.PaymentCallback{
+		ExternalID: cb.ExternalID,
+		Amount:     transaction.NewMoney(cb.Amount, transaction.Currency(cb.Currency)),
+		Paid:       cb.Status == "paid",
+		PaidAt:     cb.PaidAt,
+	}, nil
+}
+
+// Refund accepts every refund without calling any provider.
+func (c *LocalClient) Refund(ctx context.Context, r transaction.Refund) (string, error) {
+	return "local-refund-" + r.ID.String(), nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the body, the signature the provider sends along a callback.
+func (c *LocalClient) Sign(body []byte) string {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func randomDigits(n int) (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+	v, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", n, v), nil
+}
+
+func NewLocalClient(secret string, ttl time.Duration) *LocalClient {
+	return &LocalClient{
+		secret: []byte(secret),
+		ttl:    ttl,
+	}
+}
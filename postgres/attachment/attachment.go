@@ -0,0 +1,88 @@
+package attachment
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"rekber/ierr"
+	"rekber/internal/attachment"
+	"rekber/postgres"
+	"rekber/postgres/model"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const insertAttachmentQuery = `INSERT INTO attachments (id, transaction_id, uploaded_by, purpose, file_name, content_type, size, storage_key, created_at)
+	VALUES (:id, :transaction_id, :uploaded_by, :purpose, :file_name, :content_type, :size, :storage_key, :created_at)`
+
+type Repository struct {
+	db *sqlx.DB
+}
+
+func (r Repository) Save(ctx context.Context, a attachment.Attachment) error {
+	m := model.Attachment{
+		ID:            a.ID,
+		TransactionID: a.TransactionID,
+		UploadedBy:    a.UploadedBy,
+		Purpose:       string(a.Purpose),
+		FileName:      a.FileName,
+		ContentType:   a.ContentType,
+		Size:          a.Size,
+		StorageKey:    a.StorageKey,
+		CreatedAt:     a.CreatedAt,
+	}
+	if _, err := postgres.Conn(ctx, r.db).NamedExecContext(ctx, insertAttachmentQuery, m); err != nil {
+		return fmt.Errorf("failed to insert attachment: %w", err)
+	}
+
+	return nil
+}
+
+func (r Repository) Get(ctx context.Context, transactionID, id uuid.UUID) (attachment.Attachment, error) {
+	var m model.Attachment
+	if err := postgres.Conn(ctx, r.db).GetContext(ctx, &m, "SELECT * FROM attachments WHERE id = $1 AND transaction_id = $2", id, transactionID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return attachment.Attachment{}, ierr.AttachmentNotFound{ID: id}
+		}
+
+		return attachment.Attachment{}, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	return toEntity(m), nil
+}
+
+func (r Repository) ListByTransactionID(ctx context.Context, transactionID uuid.UUID) ([]attachment.Attachment, error) {
+	var attachments []model.Attachment
+	if err := postgres.Conn(ctx, r.db).SelectContext(ctx, &attachments, "SELECT * FROM attachments WHERE transaction_id = $1 ORDER BY created_at", transactionID); err != nil {
+		return nil, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	result := make([]attachment.Attachment, 0, len(attachments))
+	for _, m := range attachments {
+		result = append(result, toEntity(m))
+	}
+
+	return result, nil
+}
+
+func toEntity(m model.Attachment) attachment.Attachment {
+	return attachment.Attachment{
+		ID:            m.ID,
+		TransactionID: m.TransactionID,
+		UploadedBy:    m.UploadedBy,
+		Purpose:       attachment.Purpose(m.Purpose),
+		FileName:      m.FileName,
+		ContentType:   m.ContentType,
+		Size:          m.Size,
+		StorageKey:    m.StorageKey,
+		CreatedAt:     m.CreatedAt,
+	}
+}
+
+func NewRepository(db *sqlx.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
@@ -0,0 +1,128 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"rekber/internal/ledger"
+	"rekber/postgres"
+	"rekber/postgres/model"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	insertEntryQuery = `INSERT INTO ledger_entries (id, transaction_id, kind, currency, created_at)
+		VALUES (:id, :transaction_id, :kind, :currency, :created_at)`
+
+	insertLineQuery = `INSERT INTO ledger_lines (entry_id, account, debit, credit)
+		VALUES (:entry_id, :account, :debit, :credit)`
+
+	balanceQuery = `SELECT l.account, e.currency, SUM(l.debit)::BIGINT AS debit, SUM(l.credit)::BIGINT AS credit
+		FROM ledger_lines l JOIN ledger_entries e ON e.id = l.entry_id`
+)
+
+type Repository struct {
+	db *sqlx.DB
+}
+
+func (r Repository) Save(ctx context.Context, e ledger.Entry) error {
+	return postgres.NewTransactor(r.db).WithinTransaction(ctx, func(ctx context.Context) error {
+		conn := postgres.Conn(ctx, r.db)
+		entry := model.LedgerEntry{
+			ID:            e.ID,
+			TransactionID: e.TransactionID,
+			Kind:          string(e.Kind),
+			Currency:      e.Currency,
+			CreatedAt:     e.CreatedAt,
+		}
+		if _, err := conn.NamedExecContext(ctx, insertEntryQuery, entry); err != nil {
+			return fmt.Errorf("failed to insert ledger entry: %w", err)
+		}
+
+		for _, l := range e.Lines {
+			line := model.LedgerLine{
+				EntryID: e.ID,
+				Account: string(l.Account),
+				Debit:   l.Debit,
+				Credit:  l.Credit,
+			}
+			if _, err := conn.NamedExecContext(ctx, insertLineQuery, line); err != nil {
+				return fmt.Errorf("failed to insert ledger line: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r Repository) ListEntries(ctx context.Context, transactionID uuid.UUID) ([]ledger.Entry, error) {
+	conn := postgres.Conn(ctx, r.db)
+
+	var entries []model.LedgerEntry
+	if err := conn.SelectContext(ctx, &entries, "SELECT * FROM ledger_entries WHERE transaction_id = $1 ORDER BY created_at", transactionID); err != nil {
+		return nil, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	var lines []model.LedgerLine
+	query := "SELECT l.* FROM ledger_lines l JOIN ledger_entries e ON e.id = l.entry_id WHERE e.transaction_id = $1 ORDER BY l.id"
+	if err := conn.SelectContext(ctx, &lines, query, transactionID); err != nil {
+		return nil, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	linesByEntry := make(map[uuid.UUID][]ledger.Line)
+	for _, l := range lines {
+		linesByEntry[l.EntryID] = append(linesByEntry[l.EntryID], ledger.Line{
+			Account: ledger.Account(l.Account),
+			Debit:   l.Debit,
+			Credit:  l.Credit,
+		})
+	}
+
+	result := make([]ledger.Entry, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, ledger.Entry{
+			ID:            e.ID,
+			TransactionID: e.TransactionID,
+			Kind:          ledger.Kind(e.Kind),
+			Currency:      e.Currency,
+			Lines:         linesByEntry[e.ID],
+			CreatedAt:     e.CreatedAt,
+		})
+	}
+
+	return result, nil
+}
+
+func (r Repository) ListBalances(ctx context.Context, transactionID uuid.UUID) ([]ledger.Balance, error) {
+	return r.listBalances(ctx, balanceQuery+" WHERE e.transaction_id = $1 GROUP BY l.account, e.currency ORDER BY l.account", transactionID)
+}
+
+func (r Repository) ListTotalBalances(ctx context.Context) ([]ledger.Balance, error) {
+	return r.listBalances(ctx, balanceQuery+" GROUP BY l.account, e.currency ORDER BY l.account, e.currency")
+}
+
+func (r Repository) listBalances(ctx context.Context, query string, args ...interface{}) ([]ledger.Balance, error) {
+	var balances []model.LedgerBalance
+	if err := postgres.Conn(ctx, r.db).SelectContext(ctx, &balances, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	result := make([]ledger.Balance, 0, len(balances))
+	for _, b := range balances {
+		result = append(result, ledger.Balance{
+			Account:  ledger.Account(b.Account),
+			Currency: b.Currency,
+			Debit:    b.Debit,
+			Credit:   b.Credit,
+		})
+	}
+
+	return result, nil
+}
+
+func NewRepository(db *sqlx.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS transactions
\ No newline at end of file
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS transactions(
+   id UUID DEFAULT uuid_generate_v4() PRIMARY KEY,
+   buyer_id UUID NOT NULL REFERENCES users(id),
+   seller_id UUID NOT NULL REFERENCES users(id),
+   currency VARCHAR(3) NOT NULL,
+   item_total BIGINT NOT NULL CHECK (item_total > 0),
+   escrow_fee BIGINT NOT NULL CHECK (escrow_fee >= 0),
+   seller_net BIGINT NOT NULL CHECK (seller_net > 0),
+   created_by SMALLINT NOT NULL,
+   created_at TIMESTAMP DEFAULT NOW(),
+   accepted_by SMALLINT DEFAULT NULL,
+   accepted_at TIMESTAMP DEFAULT NULL,
+   rejected_by SMALLINT DEFAULT NULL,
+   rejected_at TIMESTAMP DEFAULT NULL,
+   rejected_reason TEXT DEFAULT NULL,
+   paid_at TIMESTAMP DEFAULT NULL,
+   done_by_seller_at TIMESTAMP DEFAULT NULL,
+   success_at TIMESTAMP DEFAULT NULL,
+   status SMALLINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS transactions_buyer_id_idx ON transactions(buyer_id);
+CREATE INDEX IF NOT EXISTS transactions_seller_id_idx ON transactions(seller_id);
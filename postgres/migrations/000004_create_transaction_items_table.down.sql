@@ -0,0 +1 @@
+DROP TABLE IF EXISTS transaction_items
\ No newline at end of file
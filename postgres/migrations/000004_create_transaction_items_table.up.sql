@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS transaction_items(
+   id UUID DEFAULT uuid_generate_v4() PRIMARY KEY,
+   transaction_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+   position SMALLINT NOT NULL,
+   name VARCHAR(255) NOT NULL,
+   description TEXT NOT NULL DEFAULT '',
+   quantity BIGINT NOT NULL CHECK (quantity > 0),
+   price BIGINT NOT NULL CHECK (price > 0),
+   currency VARCHAR(3) NOT NULL,
+   UNIQUE (transaction_id, position)
+);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS refresh_tokens(
+   id UUID PRIMARY KEY,
+   family_id UUID NOT NULL,
+   user_id UUID NOT NULL REFERENCES users(id),
+   expired_at TIMESTAMP NOT NULL,
+   rotated_at TIMESTAMP DEFAULT NULL,
+   revoked_at TIMESTAMP DEFAULT NULL,
+   created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS refresh_tokens_family_id_idx ON refresh_tokens(family_id);
+CREATE INDEX IF NOT EXISTS refresh_tokens_user_id_idx ON refresh_tokens(user_id);
@@ -0,0 +1,12 @@
+-- a row either revokes a single access token (token_id) or every access token
+-- of the user issued before issued_before (log out from all devices)
+CREATE TABLE IF NOT EXISTS revoked_access_tokens(
+   id UUID DEFAULT uuid_generate_v4() PRIMARY KEY,
+   token_id UUID UNIQUE DEFAULT NULL,
+   user_id UUID NOT NULL REFERENCES users(id),
+   issued_before TIMESTAMP DEFAULT NULL,
+   expired_at TIMESTAMP NOT NULL,
+   created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS revoked_access_tokens_user_id_idx ON revoked_access_tokens(user_id);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS otp_codes
\ No newline at end of file
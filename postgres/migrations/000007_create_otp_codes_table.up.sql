@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS otp_codes(
+   session_info UUID PRIMARY KEY,
+   phone_number VARCHAR(50) NOT NULL,
+   code_hash VARCHAR(64) NOT NULL,
+   expired_at TIMESTAMP NOT NULL,
+   verified_at TIMESTAMP DEFAULT NULL,
+   created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS otp_codes_phone_number_idx ON otp_codes(phone_number);
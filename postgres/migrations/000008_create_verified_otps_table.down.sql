@@ -0,0 +1 @@
+DROP TABLE IF EXISTS verified_otps
\ No newline at end of file
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS verified_otps(
+   phone_number VARCHAR(50) NOT NULL,
+   state SMALLINT NOT NULL,
+   expired_at TIMESTAMP NOT NULL,
+   created_at TIMESTAMP DEFAULT NOW(),
+   PRIMARY KEY (phone_number, state)
+);
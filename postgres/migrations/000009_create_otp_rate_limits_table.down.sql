@@ -0,0 +1 @@
+DROP TABLE IF EXISTS otp_rate_limits
\ No newline at end of file
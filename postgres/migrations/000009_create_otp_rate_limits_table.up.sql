@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS otp_rate_limits(
+   key VARCHAR(255) PRIMARY KEY,
+   hits INTEGER NOT NULL,
+   reset_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS banks
\ No newline at end of file
@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS banks(
+   code VARCHAR(10) PRIMARY KEY,
+   name VARCHAR(100) NOT NULL
+);
+
+-- bank codes are the clearing codes issued by Bank Indonesia
+INSERT INTO banks (code, name) VALUES
+   ('002', 'Bank Rakyat Indonesia'),
+   ('008', 'Bank Mandiri'),
+   ('009', 'Bank Negara Indonesia'),
+   ('011', 'Bank Danamon'),
+   ('013', 'Bank Permata'),
+   ('014', 'Bank Central Asia'),
+   ('016', 'Maybank Indonesia'),
+   ('019', 'Bank Panin'),
+   ('022', 'Bank CIMB Niaga'),
+   ('028', 'Bank OCBC NISP'),
+   ('110', 'Bank BJB'),
+   ('111', 'Bank DKI'),
+   ('147', 'Bank Muamalat'),
+   ('200', 'Bank Tabungan Negara'),
+   ('213', 'Bank SMBC Indonesia'),
+   ('426', 'Bank Mega'),
+   ('441', 'KB Bank'),
+   ('451', 'Bank Syariah Indonesia'),
+   ('490', 'Bank Neo Commerce'),
+   ('501', 'Bank Digital BCA'),
+   ('535', 'SeaBank Indonesia'),
+   ('542', 'Bank Jago')
+ON CONFLICT (code) DO NOTHING;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS bank_accounts
\ No newline at end of file
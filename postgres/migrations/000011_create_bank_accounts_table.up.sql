@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS bank_accounts(
+   id UUID PRIMARY KEY,
+   user_id UUID NOT NULL REFERENCES users (id),
+   bank_code VARCHAR(10) NOT NULL REFERENCES banks (code),
+   number VARCHAR(50) NOT NULL,
+   name VARCHAR(100) NOT NULL,
+   is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+   created_at TIMESTAMP DEFAULT NOW(),
+   UNIQUE (user_id, bank_code, number)
+);
+
+-- a user has at most one primary bank account
+CREATE UNIQUE INDEX IF NOT EXISTS bank_accounts_primary_idx ON bank_accounts (user_id) WHERE is_primary;
@@ -0,0 +1 @@
+ALTER TABLE bank_accounts DROP COLUMN IF EXISTS verified_at
\ No newline at end of file
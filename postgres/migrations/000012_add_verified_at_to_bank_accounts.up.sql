@@ -0,0 +1 @@
+ALTER TABLE bank_accounts ADD COLUMN IF NOT EXISTS verified_at TIMESTAMP DEFAULT NULL;
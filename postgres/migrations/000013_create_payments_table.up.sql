@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS payments(
+   id UUID PRIMARY KEY,
+   transaction_id UUID NOT NULL REFERENCES transactions (id),
+   method VARCHAR(20) NOT NULL,
+   bank_code VARCHAR(10) NOT NULL DEFAULT '',
+   external_id VARCHAR(255) UNIQUE NOT NULL,
+   virtual_account_number VARCHAR(50) NOT NULL DEFAULT '',
+   qr_string TEXT NOT NULL DEFAULT '',
+   amount BIGINT NOT NULL,
+   currency VARCHAR(3) NOT NULL,
+   status VARCHAR(20) NOT NULL,
+   expired_at TIMESTAMP NOT NULL,
+   paid_at TIMESTAMP DEFAULT NULL,
+   created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS payments_transaction_id_idx ON payments (transaction_id);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS payouts
\ No newline at end of file
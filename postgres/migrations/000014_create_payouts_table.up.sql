@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS payouts(
+   id UUID PRIMARY KEY,
+   transaction_id UUID UNIQUE NOT NULL REFERENCES transactions (id),
+   seller_id UUID NOT NULL REFERENCES users (id),
+   bank_account_id UUID DEFAULT NULL,
+   bank_code VARCHAR(10) NOT NULL DEFAULT '',
+   account_number VARCHAR(50) NOT NULL DEFAULT '',
+   account_name VARCHAR(100) NOT NULL DEFAULT '',
+   amount BIGINT NOT NULL,
+   currency VARCHAR(3) NOT NULL,
+   status VARCHAR(20) NOT NULL,
+   attempts INTEGER NOT NULL DEFAULT 0,
+   next_attempt_at TIMESTAMP NOT NULL,
+   last_error TEXT NOT NULL DEFAULT '',
+   external_id VARCHAR(255) NOT NULL DEFAULT '',
+   succeeded_at TIMESTAMP DEFAULT NULL,
+   failed_at TIMESTAMP DEFAULT NULL,
+   created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS payouts_due_idx ON payouts (next_attempt_at) WHERE status = 'pending';
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS ledger_entries(
+   id UUID PRIMARY KEY,
+   transaction_id UUID NOT NULL REFERENCES transactions (id),
+   kind VARCHAR(20) NOT NULL,
+   currency VARCHAR(3) NOT NULL,
+   created_at TIMESTAMP DEFAULT NOW(),
+   UNIQUE (transaction_id, kind)
+);
+
+CREATE TABLE IF NOT EXISTS ledger_lines(
+   id BIGSERIAL PRIMARY KEY,
+   entry_id UUID NOT NULL REFERENCES ledger_entries (id),
+   account VARCHAR(30) NOT NULL,
+   debit BIGINT NOT NULL DEFAULT 0 CHECK (debit >= 0),
+   credit BIGINT NOT NULL DEFAULT 0 CHECK (credit >= 0),
+   CHECK ((debit = 0) <> (credit = 0))
+);
+
+CREATE INDEX IF NOT EXISTS ledger_lines_entry_id_idx ON ledger_lines (entry_id);
+
+-- posted entries are immutable, corrections are made by posting another entry
+CREATE OR REPLACE FUNCTION ledger_immutable() RETURNS TRIGGER AS $$
+BEGIN
+   RAISE EXCEPTION 'ledger is append only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER ledger_entries_immutable BEFORE UPDATE OR DELETE ON ledger_entries
+   FOR EACH ROW EXECUTE FUNCTION ledger_immutable();
+
+CREATE TRIGGER ledger_lines_immutable BEFORE UPDATE OR DELETE ON ledger_lines
+   FOR EACH ROW EXECUTE FUNCTION ledger_immutable();
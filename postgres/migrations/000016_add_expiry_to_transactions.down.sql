@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS transactions_waiting_idx;
+ALTER TABLE transactions DROP COLUMN IF EXISTS expired_reason;
+ALTER TABLE transactions DROP COLUMN IF EXISTS expired_at;
\ No newline at end of file
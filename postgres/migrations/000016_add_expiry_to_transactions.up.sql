@@ -0,0 +1,4 @@
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS expired_at TIMESTAMP DEFAULT NULL;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS expired_reason TEXT DEFAULT NULL;
+
+CREATE INDEX IF NOT EXISTS transactions_waiting_idx ON transactions (status) WHERE status IN (1, 2);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS transactions_done_by_seller_idx;
+ALTER TABLE transactions DROP COLUMN IF EXISTS release_reminded_at;
+ALTER TABLE transactions DROP COLUMN IF EXISTS success_by;
\ No newline at end of file
@@ -0,0 +1,7 @@
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS success_by SMALLINT DEFAULT NULL;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS release_reminded_at TIMESTAMP DEFAULT NULL;
+
+-- every transaction which succeeded so far was confirmed by its buyer
+UPDATE transactions SET success_by = 1 WHERE status = 7 AND success_by IS NULL;
+
+CREATE INDEX IF NOT EXISTS transactions_done_by_seller_idx ON transactions (done_by_seller_at) WHERE status = 6;
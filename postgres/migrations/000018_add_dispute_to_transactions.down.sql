@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS refunds;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS refund_amount;
+ALTER TABLE transactions DROP COLUMN IF EXISTS resolution_note;
+ALTER TABLE transactions DROP COLUMN IF EXISTS resolved_by;
+ALTER TABLE transactions DROP COLUMN IF EXISTS resolved_at;
+ALTER TABLE transactions DROP COLUMN IF EXISTS dispute_responded_at;
+ALTER TABLE transactions DROP COLUMN IF EXISTS dispute_response;
+ALTER TABLE transactions DROP COLUMN IF EXISTS dispute_deadline;
+ALTER TABLE transactions DROP COLUMN IF EXISTS dispute_reason;
+ALTER TABLE transactions DROP COLUMN IF EXISTS disputed_at;
+
+ALTER TABLE users DROP COLUMN IF EXISTS is_admin;
\ No newline at end of file
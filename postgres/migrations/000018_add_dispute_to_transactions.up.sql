@@ -0,0 +1,31 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_admin BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS disputed_at TIMESTAMP DEFAULT NULL;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS dispute_reason TEXT DEFAULT NULL;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS dispute_deadline TIMESTAMP DEFAULT NULL;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS dispute_response TEXT DEFAULT NULL;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS dispute_responded_at TIMESTAMP DEFAULT NULL;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS resolved_at TIMESTAMP DEFAULT NULL;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS resolved_by UUID DEFAULT NULL REFERENCES users (id);
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS resolution_note TEXT DEFAULT NULL;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS refund_amount BIGINT NOT NULL DEFAULT 0 CHECK (refund_amount >= 0);
+
+CREATE TABLE IF NOT EXISTS refunds(
+   id UUID PRIMARY KEY,
+   transaction_id UUID UNIQUE NOT NULL REFERENCES transactions (id),
+   buyer_id UUID NOT NULL REFERENCES users (id),
+   payment_id UUID NOT NULL REFERENCES payments (id),
+   payment_external_id VARCHAR(255) NOT NULL,
+   amount BIGINT NOT NULL,
+   currency VARCHAR(3) NOT NULL,
+   status VARCHAR(20) NOT NULL,
+   attempts INTEGER NOT NULL DEFAULT 0,
+   next_attempt_at TIMESTAMP NOT NULL,
+   last_error TEXT NOT NULL DEFAULT '',
+   external_id VARCHAR(255) NOT NULL DEFAULT '',
+   succeeded_at TIMESTAMP DEFAULT NULL,
+   failed_at TIMESTAMP DEFAULT NULL,
+   created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS refunds_due_idx ON refunds (next_attempt_at) WHERE status = 'pending';
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS attachments
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS attachments(
+   id UUID PRIMARY KEY,
+   transaction_id UUID NOT NULL REFERENCES transactions (id),
+   uploaded_by UUID NOT NULL REFERENCES users (id),
+   purpose VARCHAR(20) NOT NULL,
+   file_name VARCHAR(255) NOT NULL,
+   content_type VARCHAR(100) NOT NULL,
+   size BIGINT NOT NULL CHECK (size > 0),
+   storage_key VARCHAR(255) UNIQUE NOT NULL,
+   created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS attachments_transaction_id_idx ON attachments (transaction_id);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS shipments;
+DROP INDEX IF EXISTS transactions_inspection_started_idx;
+CREATE INDEX IF NOT EXISTS transactions_done_by_seller_idx ON transactions (done_by_seller_at) WHERE status = 6;
+ALTER TABLE transactions DROP COLUMN IF EXISTS inspection_started_at;
\ No newline at end of file
@@ -0,0 +1,22 @@
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS inspection_started_at TIMESTAMP DEFAULT NULL;
+
+-- transactions done so far were not shipped, their inspection started when the seller marked them done
+UPDATE transactions SET inspection_started_at = done_by_seller_at WHERE done_by_seller_at IS NOT NULL AND inspection_started_at IS NULL;
+
+DROP INDEX IF EXISTS transactions_done_by_seller_idx;
+CREATE INDEX IF NOT EXISTS transactions_inspection_started_idx ON transactions (inspection_started_at) WHERE status = 6;
+
+CREATE TABLE IF NOT EXISTS shipments(
+   id UUID PRIMARY KEY,
+   transaction_id UUID UNIQUE NOT NULL REFERENCES transactions (id),
+   courier_code VARCHAR(20) NOT NULL,
+   airway_bill VARCHAR(50) NOT NULL,
+   status VARCHAR(20) NOT NULL,
+   events JSONB NOT NULL DEFAULT '[]',
+   delivered_at TIMESTAMP DEFAULT NULL,
+   last_checked_at TIMESTAMP DEFAULT NULL,
+   last_error TEXT NOT NULL DEFAULT '',
+   created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS shipments_in_transit_idx ON shipments (last_checked_at) WHERE status = 'in_transit';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS transaction_events;
+DROP FUNCTION IF EXISTS transaction_events_immutable;
\ No newline at end of file
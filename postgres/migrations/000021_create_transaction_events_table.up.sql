@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS transaction_events(
+   seq BIGSERIAL UNIQUE NOT NULL,
+   id UUID PRIMARY KEY,
+   transaction_id UUID NOT NULL REFERENCES transactions (id),
+   from_status SMALLINT DEFAULT NULL,
+   to_status SMALLINT NOT NULL,
+   action SMALLINT NOT NULL,
+   actor SMALLINT NOT NULL,
+   actor_user_id UUID DEFAULT NULL REFERENCES users (id),
+   reason TEXT DEFAULT NULL,
+   request_id VARCHAR(64) DEFAULT NULL,
+   created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS transaction_events_transaction_id_idx ON transaction_events (transaction_id, created_at);
+
+-- recorded events are the audit trail, they are never changed or removed
+CREATE OR REPLACE FUNCTION transaction_events_immutable() RETURNS TRIGGER AS $$
+BEGIN
+   RAISE EXCEPTION 'transaction events are append only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER transaction_events_immutable BEFORE UPDATE OR DELETE ON transaction_events
+   FOR EACH ROW EXECUTE FUNCTION transaction_events_immutable();
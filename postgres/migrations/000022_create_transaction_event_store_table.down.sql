@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS transaction_event_store;
+DROP FUNCTION IF EXISTS transaction_event_store_immutable;
+ALTER TABLE transactions DROP COLUMN IF EXISTS version;
\ No newline at end of file
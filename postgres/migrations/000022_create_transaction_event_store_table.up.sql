@@ -0,0 +1,21 @@
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS transaction_event_store(
+   transaction_id UUID NOT NULL,
+   version INT NOT NULL CHECK (version > 0),
+   type VARCHAR(30) NOT NULL,
+   data JSONB NOT NULL,
+   occurred_at TIMESTAMP NOT NULL,
+   recorded_at TIMESTAMP DEFAULT NOW(),
+   PRIMARY KEY (transaction_id, version)
+);
+
+-- the transactions table is a projection of the event store, events are never changed or removed
+CREATE OR REPLACE FUNCTION transaction_event_store_immutable() RETURNS TRIGGER AS $$
+BEGIN
+   RAISE EXCEPTION 'transaction event store is append only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER transaction_event_store_immutable BEFORE UPDATE OR DELETE ON transaction_event_store
+   FOR EACH ROW EXECUTE FUNCTION transaction_event_store_immutable();
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS outbox_messages(
+   id UUID PRIMARY KEY,
+   topic VARCHAR(100) NOT NULL,
+   key VARCHAR(100) NOT NULL,
+   payload JSONB NOT NULL,
+   status VARCHAR(20) NOT NULL,
+   attempts INT NOT NULL DEFAULT 0,
+   next_attempt_at TIMESTAMP NOT NULL,
+   last_error TEXT NOT NULL DEFAULT '',
+   published_at TIMESTAMP DEFAULT NULL,
+   failed_at TIMESTAMP DEFAULT NULL,
+   created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS outbox_messages_pending_idx ON outbox_messages (next_attempt_at) WHERE status = 'pending';
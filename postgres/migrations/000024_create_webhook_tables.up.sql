@@ -0,0 +1,43 @@
+CREATE TABLE IF NOT EXISTS webhook_endpoints(
+   id UUID PRIMARY KEY,
+   partner_id UUID NOT NULL REFERENCES users (id),
+   url VARCHAR(2048) NOT NULL,
+   secret VARCHAR(100) NOT NULL,
+   created_at TIMESTAMP DEFAULT NOW(),
+   disabled_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS webhook_endpoints_partner_id_idx ON webhook_endpoints (partner_id);
+
+-- the payload is stored as sent rather than as JSONB, so its signature can be verified on every attempt
+CREATE TABLE IF NOT EXISTS webhook_deliveries(
+   id UUID PRIMARY KEY,
+   endpoint_id UUID NOT NULL REFERENCES webhook_endpoints (id),
+   event_id UUID NOT NULL,
+   event_type VARCHAR(100) NOT NULL,
+   payload BYTEA NOT NULL,
+   status VARCHAR(20) NOT NULL,
+   attempts INT NOT NULL DEFAULT 0,
+   next_attempt_at TIMESTAMP NOT NULL,
+   last_response_code INT NOT NULL DEFAULT 0,
+   last_error TEXT NOT NULL DEFAULT '',
+   delivered_at TIMESTAMP DEFAULT NULL,
+   failed_at TIMESTAMP DEFAULT NULL,
+   created_at TIMESTAMP DEFAULT NOW(),
+   UNIQUE (endpoint_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_pending_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS webhook_deliveries_endpoint_id_idx ON webhook_deliveries (endpoint_id, created_at);
+
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts(
+   id UUID PRIMARY KEY,
+   delivery_id UUID NOT NULL REFERENCES webhook_deliveries (id),
+   response_code INT NOT NULL DEFAULT 0,
+   response_body TEXT NOT NULL DEFAULT '',
+   error TEXT NOT NULL DEFAULT '',
+   duration_ms BIGINT NOT NULL,
+   created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_attempts_delivery_id_idx ON webhook_delivery_attempts (delivery_id);
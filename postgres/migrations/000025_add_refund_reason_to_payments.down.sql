@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS payments_refund_reason_idx;
+ALTER TABLE payments DROP COLUMN IF EXISTS refund_reason
\ No newline at end of file
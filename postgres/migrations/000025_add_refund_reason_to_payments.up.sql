@@ -0,0 +1,2 @@
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS refund_reason TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS payments_refund_reason_idx ON payments (paid_at) WHERE refund_reason <> '';
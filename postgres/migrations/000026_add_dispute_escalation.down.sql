@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS transactions_dispute_deadline_idx;
+ALTER TABLE transactions DROP COLUMN IF EXISTS dispute_escalated_at
\ No newline at end of file
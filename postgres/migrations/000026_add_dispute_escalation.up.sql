@@ -0,0 +1,4 @@
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS dispute_escalated_at TIMESTAMP DEFAULT NULL;
+
+-- status 8 is disputed
+CREATE INDEX IF NOT EXISTS transactions_dispute_deadline_idx ON transactions (dispute_deadline) WHERE status = 8;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS transactions_undelivered_idx
\ No newline at end of file
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS transactions_undelivered_idx ON transactions (done_by_seller_at) WHERE status = 6 AND inspection_started_at IS NULL;
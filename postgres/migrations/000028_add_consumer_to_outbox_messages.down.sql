@@ -0,0 +1,4 @@
+DELETE FROM outbox_messages WHERE consumer <> 'broker';
+ALTER TABLE outbox_messages DROP CONSTRAINT IF EXISTS outbox_messages_pkey;
+ALTER TABLE outbox_messages ADD PRIMARY KEY (id);
+ALTER TABLE outbox_messages DROP COLUMN IF EXISTS consumer
\ No newline at end of file
@@ -0,0 +1,6 @@
+ALTER TABLE outbox_messages ADD COLUMN IF NOT EXISTS consumer VARCHAR(50) NOT NULL DEFAULT 'broker';
+ALTER TABLE outbox_messages DROP CONSTRAINT IF EXISTS outbox_messages_pkey;
+ALTER TABLE outbox_messages ADD PRIMARY KEY (id, consumer);
+INSERT INTO outbox_messages (id, consumer, topic, key, payload, status, attempts, next_attempt_at, last_error, created_at)
+   SELECT id, 'webhook', topic, key, payload, status, 0, next_attempt_at, '', created_at FROM outbox_messages WHERE status = 'pending';
+ALTER TABLE outbox_messages ALTER COLUMN consumer DROP DEFAULT;
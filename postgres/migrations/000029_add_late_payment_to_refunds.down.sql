@@ -0,0 +1,5 @@
+DELETE FROM refunds WHERE late_payment;
+DROP INDEX IF EXISTS refunds_payment_id_late_idx;
+DROP INDEX IF EXISTS refunds_transaction_id_idx;
+ALTER TABLE refunds ADD CONSTRAINT refunds_transaction_id_key UNIQUE (transaction_id);
+ALTER TABLE refunds DROP COLUMN IF EXISTS late_payment;
\ No newline at end of file
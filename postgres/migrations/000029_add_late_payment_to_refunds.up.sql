@@ -0,0 +1,7 @@
+ALTER TABLE refunds ADD COLUMN IF NOT EXISTS late_payment BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE refunds DROP CONSTRAINT IF EXISTS refunds_transaction_id_key;
+CREATE UNIQUE INDEX IF NOT EXISTS refunds_transaction_id_idx ON refunds (transaction_id) WHERE NOT late_payment;
+CREATE UNIQUE INDEX IF NOT EXISTS refunds_payment_id_late_idx ON refunds (payment_id) WHERE late_payment;
+INSERT INTO refunds (id, transaction_id, buyer_id, payment_id, payment_external_id, amount, currency, late_payment, status, attempts, next_attempt_at, last_error, external_id, created_at)
+   SELECT uuid_generate_v4(), p.transaction_id, t.buyer_id, p.id, p.external_id, p.amount, p.currency, TRUE, 'pending', 0, NOW(), '', '', NOW()
+   FROM payments p JOIN transactions t ON t.id = p.transaction_id WHERE p.refund_reason <> '';
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Attachment struct {
+	ID            uuid.UUID `db:"id"`
+	TransactionID uuid.UUID `db:"transaction_id"`
+	UploadedBy    uuid.UUID `db:"uploaded_by"`
+	Purpose       string    `db:"purpose"`
+	FileName      string    `db:"file_name"`
+	ContentType   string    `db:"content_type"`
+	Size          int64     `db:"size"`
+	StorageKey    string    `db:"storage_key"`
+	CreatedAt     time.Time `db:"created_at"`
+}
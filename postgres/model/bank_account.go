@@ -0,0 +1,25 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Bank struct {
+	Code string `db:"code"`
+	Name string `db:"name"`
+}
+
+type BankAccount struct {
+	ID         uuid.UUID    `db:"id"`
+	UserID     uuid.UUID    `db:"user_id"`
+	BankCode   string       `db:"bank_code"`
+	BankName   string       `db:"bank_name"`
+	Number     string       `db:"number"`
+	Name       string       `db:"name"`
+	IsPrimary  bool         `db:"is_primary"`
+	VerifiedAt sql.NullTime `db:"verified_at"`
+	CreatedAt  time.Time    `db:"created_at"`
+}
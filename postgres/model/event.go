@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type StoredEvent struct {
+	TransactionID uuid.UUID `db:"transaction_id"`
+	Version       int       `db:"version"`
+	Type          string    `db:"type"`
+	Data          []byte    `db:"data"`
+	OccurredAt    time.Time `db:"occurred_at"`
+	RecordedAt    time.Time `db:"recorded_at"`
+}
+
+// EventData is the data column of transaction_event_store.
+type EventData struct {
+	Actor          int16                `json:"actor"`
+	UserID         uuid.NullUUID        `json:"user_id"`
+	Reason         string               `json:"reason,omitempty"`
+	RefundAmount   int64                `json:"refund_amount,omitempty"`
+	RefundCurrency string               `json:"refund_currency,omitempty"`
+	CourierCode    string               `json:"courier_code,omitempty"`
+	AirwayBill     string               `json:"airway_bill,omitempty"`
+	WindowSeconds  int64                `json:"window_seconds,omitempty"`
+	DeliveredAt    *time.Time           `json:"delivered_at,omitempty"`
+	Snapshot       *TransactionSnapshot `json:"snapshot,omitempty"`
+}
+
+// TransactionSnapshot is the whole transaction as its row and items.
+type TransactionSnapshot struct {
+	Transaction Transaction       `json:"transaction"`
+	Items       []TransactionItem `json:"items"`
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type LedgerEntry struct {
+	ID            uuid.UUID `db:"id"`
+	TransactionID uuid.UUID `db:"transaction_id"`
+	Kind          string    `db:"kind"`
+	Currency      string    `db:"currency"`
+	CreatedAt     time.Time `db:"created_at"`
+}
+
+type LedgerLine struct {
+	ID      int64     `db:"id"`
+	EntryID uuid.UUID `db:"entry_id"`
+	Account string    `db:"account"`
+	Debit   int64     `db:"debit"`
+	Credit  int64     `db:"credit"`
+}
+
+type LedgerBalance struct {
+	Account  string `db:"account"`
+	Currency string `db:"currency"`
+	Debit    int64  `db:"debit"`
+	Credit   int64  `db:"credit"`
+}
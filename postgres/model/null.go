@@ -0,0 +1,27 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+func NewNullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{
+		Time:  t,
+		Valid: !t.IsZero(),
+	}
+}
+
+func NewNullInt16(i int16) sql.NullInt16 {
+	return sql.NullInt16{
+		Int16: i,
+		Valid: i != 0,
+	}
+}
+
+func NewNullString(s string) sql.NullString {
+	return sql.NullString{
+		String: s,
+		Valid:  s != "",
+	}
+}
@@ -0,0 +1,17 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OTPCode struct {
+	SessionInfo uuid.UUID    `db:"session_info"`
+	PhoneNumber string       `db:"phone_number"`
+	CodeHash    string       `db:"code_hash"`
+	ExpiredAt   time.Time    `db:"expired_at"`
+	VerifiedAt  sql.NullTime `db:"verified_at"`
+	CreatedAt   time.Time    `db:"created_at"`
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OutboxMessage struct {
+	ID            uuid.UUID    `db:"id"`
+	Consumer      string       `db:"consumer"`
+	Topic         string       `db:"topic"`
+	Key           string       `db:"key"`
+	Payload       []byte       `db:"payload"`
+	Status        string       `db:"status"`
+	Attempts      int          `db:"attempts"`
+	NextAttemptAt time.Time    `db:"next_attempt_at"`
+	LastError     string       `db:"last_error"`
+	PublishedAt   sql.NullTime `db:"published_at"`
+	FailedAt      sql.NullTime `db:"failed_at"`
+	CreatedAt     time.Time    `db:"created_at"`
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Payment struct {
+	ID                   uuid.UUID    `db:"id"`
+	TransactionID        uuid.UUID    `db:"transaction_id"`
+	Method               string       `db:"method"`
+	BankCode             string       `db:"bank_code"`
+	ExternalID           string       `db:"external_id"`
+	VirtualAccountNumber string       `db:"virtual_account_number"`
+	QRString             string       `db:"qr_string"`
+	Amount               int64        `db:"amount"`
+	Currency             string       `db:"currency"`
+	Status               string       `db:"status"`
+	ExpiredAt            time.Time    `db:"expired_at"`
+	PaidAt               sql.NullTime `db:"paid_at"`
+	RefundReason         string       `db:"refund_reason"`
+	CreatedAt            time.Time    `db:"created_at"`
+}
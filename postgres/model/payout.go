@@ -0,0 +1,28 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Payout struct {
+	ID            uuid.UUID     `db:"id"`
+	TransactionID uuid.UUID     `db:"transaction_id"`
+	SellerID      uuid.UUID     `db:"seller_id"`
+	BankAccountID uuid.NullUUID `db:"bank_account_id"`
+	BankCode      string        `db:"bank_code"`
+	AccountNumber string        `db:"account_number"`
+	AccountName   string        `db:"account_name"`
+	Amount        int64         `db:"amount"`
+	Currency      string        `db:"currency"`
+	Status        string        `db:"status"`
+	Attempts      int           `db:"attempts"`
+	NextAttemptAt time.Time     `db:"next_attempt_at"`
+	LastError     string        `db:"last_error"`
+	ExternalID    string        `db:"external_id"`
+	SucceededAt   sql.NullTime  `db:"succeeded_at"`
+	FailedAt      sql.NullTime  `db:"failed_at"`
+	CreatedAt     time.Time     `db:"created_at"`
+}
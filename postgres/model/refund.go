@@ -0,0 +1,27 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Refund struct {
+	ID                uuid.UUID    `db:"id"`
+	TransactionID     uuid.UUID    `db:"transaction_id"`
+	BuyerID           uuid.UUID    `db:"buyer_id"`
+	PaymentID         uuid.UUID    `db:"payment_id"`
+	PaymentExternalID string       `db:"payment_external_id"`
+	Amount            int64        `db:"amount"`
+	Currency          string       `db:"currency"`
+	LatePayment       bool         `db:"late_payment"`
+	Status            string       `db:"status"`
+	Attempts          int          `db:"attempts"`
+	NextAttemptAt     time.Time    `db:"next_attempt_at"`
+	LastError         string       `db:"last_error"`
+	ExternalID        string       `db:"external_id"`
+	SucceededAt       sql.NullTime `db:"succeeded_at"`
+	FailedAt          sql.NullTime `db:"failed_at"`
+	CreatedAt         time.Time    `db:"created_at"`
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Shipment struct {
+	ID            uuid.UUID    `db:"id"`
+	TransactionID uuid.UUID    `db:"transaction_id"`
+	CourierCode   string       `db:"courier_code"`
+	AirwayBill    string       `db:"airway_bill"`
+	Status        string       `db:"status"`
+	Events        []byte       `db:"events"`
+	DeliveredAt   sql.NullTime `db:"delivered_at"`
+	LastCheckedAt sql.NullTime `db:"last_checked_at"`
+	LastError     string       `db:"last_error"`
+	CreatedAt     time.Time    `db:"created_at"`
+}
+
+// TrackingEvent is an element of the events column of shipments.
+type TrackingEvent struct {
+	Status      string    `json:"status"`
+	Description string    `json:"description"`
+	Location    string    `json:"location,omitempty"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
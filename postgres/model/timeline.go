@@ -0,0 +1,22 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TransactionEvent struct {
+	Seq           int64          `db:"seq"`
+	ID            uuid.UUID      `db:"id"`
+	TransactionID uuid.UUID      `db:"transaction_id"`
+	FromStatus    sql.NullInt16  `db:"from_status"`
+	ToStatus      int16          `db:"to_status"`
+	Action        int16          `db:"action"`
+	Actor         int16          `db:"actor"`
+	ActorUserID   uuid.NullUUID  `db:"actor_user_id"`
+	Reason        sql.NullString `db:"reason"`
+	RequestID     sql.NullString `db:"request_id"`
+	CreatedAt     time.Time      `db:"created_at"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RefreshToken struct {
+	ID        uuid.UUID    `db:"id"`
+	FamilyID  uuid.UUID    `db:"family_id"`
+	UserID    uuid.UUID    `db:"user_id"`
+	ExpiredAt time.Time    `db:"expired_at"`
+	RotatedAt sql.NullTime `db:"rotated_at"`
+	RevokedAt sql.NullTime `db:"revoked_at"`
+	CreatedAt time.Time    `db:"created_at"`
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Transaction struct {
+	ID                  uuid.UUID      `db:"id"`
+	BuyerID             uuid.UUID      `db:"buyer_id"`
+	SellerID            uuid.UUID      `db:"seller_id"`
+	Currency            string         `db:"currency"`
+	ItemTotal           int64          `db:"item_total"`
+	EscrowFee           int64          `db:"escrow_fee"`
+	SellerNet           int64          `db:"seller_net"`
+	CreatedBy           int16          `db:"created_by"`
+	CreatedAt           time.Time      `db:"created_at"`
+	AcceptedBy          sql.NullInt16  `db:"accepted_by"`
+	AcceptedAt          sql.NullTime   `db:"accepted_at"`
+	RejectedBy          sql.NullInt16  `db:"rejected_by"`
+	RejectedAt          sql.NullTime   `db:"rejected_at"`
+	RejectedReason      sql.NullString `db:"rejected_reason"`
+	PaidAt              sql.NullTime   `db:"paid_at"`
+	ExpiredAt           sql.NullTime   `db:"expired_at"`
+	ExpiredReason       sql.NullString `db:"expired_reason"`
+	DoneBySellerAt      sql.NullTime   `db:"done_by_seller_at"`
+	SuccessAt           sql.NullTime   `db:"success_at"`
+	SuccessBy           sql.NullInt16  `db:"success_by"`
+	InspectionStartedAt sql.NullTime   `db:"inspection_started_at"`
+	ReleaseRemindedAt   sql.NullTime   `db:"release_reminded_at"`
+	DisputedAt          sql.NullTime   `db:"disputed_at"`
+	DisputeReason       sql.NullString `db:"dispute_reason"`
+	DisputeDeadline     sql.NullTime   `db:"dispute_deadline"`
+	DisputeResponse     sql.NullString `db:"dispute_response"`
+	DisputeRespondedAt  sql.NullTime   `db:"dispute_responded_at"`
+	DisputeEscalatedAt  sql.NullTime   `db:"dispute_escalated_at"`
+	ResolvedAt          sql.NullTime   `db:"resolved_at"`
+	ResolvedBy          uuid.NullUUID  `db:"resolved_by"`
+	ResolutionNote      sql.NullString `db:"resolution_note"`
+	RefundAmount        int64          `db:"refund_amount"`
+	Status              int16          `db:"status"`
+	Version             int            `db:"version"`
+}
+
+type TransactionItem struct {
+	ID            uuid.UUID `db:"id"`
+	TransactionID uuid.UUID `db:"transaction_id"`
+	Position      int16     `db:"position"`
+	Name          string    `db:"name"`
+	Description   string    `db:"description"`
+	Quantity      int64     `db:"quantity"`
+	Price         int64     `db:"price"`
+	Currency      string    `db:"currency"`
+}
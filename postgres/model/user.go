@@ -11,5 +11,6 @@ type User struct {
 	Name                  string    `db:"name"`
 	PhoneNumber           string    `db:"phone_number"`
 	PhoneNumberVerifiedAt time.Time `db:"phone_number_verified_at"`
+	IsAdmin               bool      `db:"is_admin"`
 	CreatedAt             time.Time `db:"created_at"`
 }
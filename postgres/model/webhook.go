@@ -0,0 +1,43 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WebhookEndpoint struct {
+	ID         uuid.UUID    `db:"id"`
+	PartnerID  uuid.UUID    `db:"partner_id"`
+	URL        string       `db:"url"`
+	Secret     string       `db:"secret"`
+	CreatedAt  time.Time    `db:"created_at"`
+	DisabledAt sql.NullTime `db:"disabled_at"`
+}
+
+type WebhookDelivery struct {
+	ID               uuid.UUID    `db:"id"`
+	EndpointID       uuid.UUID    `db:"endpoint_id"`
+	EventID          uuid.UUID    `db:"event_id"`
+	EventType        string       `db:"event_type"`
+	Payload          []byte       `db:"payload"`
+	Status           string       `db:"status"`
+	Attempts         int          `db:"attempts"`
+	NextAttemptAt    time.Time    `db:"next_attempt_at"`
+	LastResponseCode int          `db:"last_response_code"`
+	LastError        string       `db:"last_error"`
+	DeliveredAt      sql.NullTime `db:"delivered_at"`
+	FailedAt         sql.NullTime `db:"failed_at"`
+	CreatedAt        time.Time    `db:"created_at"`
+}
+
+type WebhookAttempt struct {
+	ID           uuid.UUID `db:"id"`
+	DeliveryID   uuid.UUID `db:"delivery_id"`
+	ResponseCode int       `db:"response_code"`
+	ResponseBody string    `db:"response_body"`
+	Error        string    `db:"error"`
+	DurationMs   int64     `db:"duration_ms"`
+	CreatedAt    time.Time `db:"created_at"`
+}
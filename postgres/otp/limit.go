@@ -0,0 +1,48 @@
+package otp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// RateLimiter keeps OTP rate limit counters in PostgreSQL so they are shared between replicas.
+type RateLimiter struct {
+	db *sqlx.DB
+}
+
+func (l *RateLimiter) Hit(ctx context.Context, key string, window time.Duration) (int, time.Time, error) {
+	now := time.Now()
+	// an ended window starts over instead of being incremented, the upsert keeps it atomic
+	query := `INSERT INTO otp_rate_limits (key, hits, reset_at) VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE SET
+			hits = CASE WHEN otp_rate_limits.reset_at <= $3 THEN 1 ELSE otp_rate_limits.hits + 1 END,
+			reset_at = CASE WHEN otp_rate_limits.reset_at <= $3 THEN EXCLUDED.reset_at ELSE otp_rate_limits.reset_at END
+		RETURNING hits, reset_at`
+
+	var (
+		hits    int
+		resetAt time.Time
+	)
+	if err := l.db.QueryRowxContext(ctx, query, key, now.Add(window), now).Scan(&hits, &resetAt); err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to count hit: %w", err)
+	}
+
+	return hits, resetAt, nil
+}
+
+func (l *RateLimiter) Reset(ctx context.Context, key string) error {
+	if _, err := l.db.ExecContext(ctx, "DELETE FROM otp_rate_limits WHERE key = $1", key); err != nil {
+		return fmt.Errorf("failed to reset hits: %w", err)
+	}
+
+	return nil
+}
+
+func NewRateLimiter(db *sqlx.DB) *RateLimiter {
+	return &RateLimiter{
+		db: db,
+	}
+}
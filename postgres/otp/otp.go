@@ -0,0 +1,143 @@
+package otp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"rekber/ierr"
+	"rekber/postgres/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const codeLength = 6
+
+// Provider is a local OTP provider for development and testing. It generates the
+// code itself and keeps it hashed in PostgreSQL instead of sending an SMS, the code
+// is handed to the onSend hook which logs it by default.
+type Provider struct {
+	db     *sqlx.DB
+	ttl    time.Duration
+	onSend func(phoneNumber, code string)
+}
+
+func (p *Provider) SendOTP(ctx context.Context, phoneNumber, captcha string) (string, error) {
+	code, err := generateCode()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate otp code: %w", err)
+	}
+
+	sessionInfo := uuid.New()
+	otpCode := model.OTPCode{
+		SessionInfo: sessionInfo,
+		PhoneNumber: phoneNumber,
+		CodeHash:    hash(sessionInfo, code),
+		ExpiredAt:   time.Now().Add(p.ttl),
+		CreatedAt:   time.Now(),
+	}
+
+	if _, err := p.db.NamedExecContext(ctx, "INSERT INTO otp_codes (session_info, phone_number, code_hash, expired_at, created_at) VALUES (:session_info, :phone_number, :code_hash, :expired_at, :created_at)", otpCode); err != nil {
+		return "", fmt.Errorf("failed to insert otp code: %w", err)
+	}
+
+	p.onSend(phoneNumber, code)
+
+	return sessionInfo.String(), nil
+}
+
+func (p *Provider) VerifyOTP(ctx context.Context, phoneNumber, otp, sessionInfo string) error {
+	invalidOTP := ierr.InvalidOTP{PhoneNumber: phoneNumber, OTP: otp}
+
+	id, err := uuid.Parse(sessionInfo)
+	if err != nil {
+		return invalidOTP
+	}
+
+	var otpCode model.OTPCode
+	if err := p.db.GetContext(ctx, &otpCode, "SELECT * FROM otp_codes WHERE session_info = $1 AND phone_number = $2", id, phoneNumber); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return invalidOTP
+		}
+
+		return fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	if otpCode.VerifiedAt.Valid || time.Now().After(otpCode.ExpiredAt) {
+		return ierr.OTPExpired{PhoneNumber: phoneNumber}
+	}
+
+	if subtle.ConstantTimeCompare([]byte(otpCode.CodeHash), []byte(hash(id, otp))) != 1 {
+		return invalidOTP
+	}
+
+	// the code can only be used once, the condition guards against concurrent verification
+	res, err := p.db.ExecContext(ctx, "UPDATE otp_codes SET verified_at = $2 WHERE session_info = $1 AND verified_at IS NULL", id, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to update otp code: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return ierr.OTPExpired{PhoneNumber: phoneNumber}
+	}
+
+	return nil
+}
+
+func generateCode() (string, error) {
+	max := big.NewInt(1)
+	for i := 0; i < codeLength; i++ {
+		max.Mul(max, big.NewInt(10))
+	}
+
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", codeLength, n.Int64()), nil
+}
+
+// hash salts the code with its session so the same code in two sessions has different hashes.
+func hash(sessionInfo uuid.UUID, code string) string {
+	sum := sha256.Sum256([]byte(sessionInfo.String() + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
+
+func NewProvider(db *sqlx.DB, ttl time.Duration, options ...Options) *Provider {
+	p := &Provider{
+		db:  db,
+		ttl: ttl,
+		onSend: func(phoneNumber, code string) {
+			log.Printf("otp code for %s is %s", phoneNumber, code)
+		},
+	}
+
+	for _, opt := range options {
+		opt(p)
+	}
+
+	return p
+}
+
+type Options func(p *Provider)
+
+// WithOnSend replaces logging the code, e.g. to capture it in end to end tests.
+func WithOnSend(fn func(phoneNumber, code string)) Options {
+	return func(p *Provider) {
+		p.onSend = fn
+	}
+}
@@ -0,0 +1,54 @@
+package otp
+
+import (
+	"context"
+	"fmt"
+	"rekber/ierr"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// VerifiedOTPRepository keeps verified OTP in PostgreSQL so it is shared between replicas.
+type VerifiedOTPRepository struct {
+	db  *sqlx.DB
+	ttl time.Duration
+}
+
+func (r *VerifiedOTPRepository) SaveVerifiedOTP(ctx context.Context, phoneNumber string, state int) error {
+	now := time.Now()
+	query := `INSERT INTO verified_otps (phone_number, state, expired_at, created_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (phone_number, state) DO UPDATE SET expired_at = EXCLUDED.expired_at, created_at = EXCLUDED.created_at`
+
+	if _, err := r.db.ExecContext(ctx, query, phoneNumber, state, now.Add(r.ttl), now); err != nil {
+		return fmt.Errorf("failed to save verified otp: %w", err)
+	}
+
+	return nil
+}
+
+func (r *VerifiedOTPRepository) ConsumeVerifiedOTP(ctx context.Context, phoneNumber string, state int) error {
+	// deleting the row is what consumes it, so two concurrent requests cannot both use the same grant
+	res, err := r.db.ExecContext(ctx, "DELETE FROM verified_otps WHERE phone_number = $1 AND state = $2 AND expired_at > $3", phoneNumber, state, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to consume verified otp: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return ierr.UserForbiddenAccess{PhoneNumber: phoneNumber}
+	}
+
+	return nil
+}
+
+func NewVerifiedOTPRepository(db *sqlx.DB, ttl time.Duration) *VerifiedOTPRepository {
+	return &VerifiedOTPRepository{
+		db:  db,
+		ttl: ttl,
+	}
+}
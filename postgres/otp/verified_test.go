@@ -0,0 +1,93 @@
+package otp
+
+import (
+	"context"
+	"errors"
+	"os"
+	"rekber/ierr"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+// testDB connects to the database in TEST_POSTGRES_URL, migrated up, or skips the test.
+func testDB(t *testing.T) *sqlx.DB {
+	url := os.Getenv("TEST_POSTGRES_URL")
+	if url == "" {
+		t.Skip("TEST_POSTGRES_URL is not set")
+	}
+
+	db, err := sqlx.Connect("postgres", url)
+	if err != nil {
+		t.Fatalf("failed to connect to postgreSQL: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func TestVerifiedOTPRepository_ConsumeVerifiedOTP(t *testing.T) {
+	db := testDB(t)
+
+	timeNow := time.Date(2023, 9, 30, 21, 0, 0, 0, time.UTC)
+	now := timeNow
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time {
+		return now
+	})
+	defer patches.Reset()
+
+	phoneNumber := "+6281234567890"
+	forbidden := ierr.UserForbiddenAccess{PhoneNumber: phoneNumber}
+
+	tests := []struct {
+		name      string
+		saveState int
+		state     int
+		elapsed   time.Duration
+		wantErrs  []error
+	}{
+		{
+			name:      "verified otp is consumed only once",
+			saveState: 1,
+			state:     1,
+			elapsed:   4 * time.Minute,
+			wantErrs:  []error{nil, forbidden},
+		},
+		{
+			name:      "expired verified otp is refused",
+			saveState: 1,
+			state:     1,
+			elapsed:   5*time.Minute + time.Second,
+			wantErrs:  []error{forbidden, forbidden},
+		},
+		{
+			name:      "verified otp of another state is refused",
+			saveState: 1,
+			state:     2,
+			wantErrs:  []error{forbidden},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewVerifiedOTPRepository(db, 5*time.Minute)
+			if _, err := db.Exec("DELETE FROM verified_otps WHERE phone_number = $1", phoneNumber); err != nil {
+				t.Fatalf("failed to clean verified otps: %v", err)
+			}
+
+			now = timeNow
+			if err := r.SaveVerifiedOTP(context.Background(), phoneNumber, tt.saveState); err != nil {
+				t.Fatalf("VerifiedOTPRepository.SaveVerifiedOTP() error = %v", err)
+			}
+
+			now = timeNow.Add(tt.elapsed)
+			for i, wantErr := range tt.wantErrs {
+				if err := r.ConsumeVerifiedOTP(context.Background(), phoneNumber, tt.state); !errors.Is(err, wantErr) {
+					t.Errorf("VerifiedOTPRepository.ConsumeVerifiedOTP() #%d error = %v, wantErr %v", i, err, wantErr)
+				}
+			}
+		})
+	}
+}
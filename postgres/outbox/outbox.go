@@ -0,0 +1,100 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"rekber/ierr"
+	"rekber/internal/outbox"
+	"rekber/postgres"
+	"rekber/postgres/model"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const (
+	insertMessageQuery = `INSERT INTO outbox_messages (id, consumer, topic, key, payload, status, attempts, next_attempt_at, last_error, published_at, failed_at, created_at)
+		VALUES (:id, :consumer, :topic, :key, :payload, :status, :attempts, :next_attempt_at, :last_error, :published_at, :failed_at, :created_at)`
+
+	updateMessageQuery = `UPDATE outbox_messages SET
+		status = :status, attempts = :attempts, next_attempt_at = :next_attempt_at, last_error = :last_error,
+		published_at = :published_at, failed_at = :failed_at
+		WHERE id = :id AND consumer = :consumer`
+)
+
+type Repository struct {
+	db *sqlx.DB
+}
+
+func (r Repository) Save(ctx context.Context, m outbox.Message) error {
+	if _, err := postgres.Conn(ctx, r.db).NamedExecContext(ctx, insertMessageQuery, toModel(m)); err != nil {
+		return fmt.Errorf("failed to insert outbox message: %w", err)
+	}
+
+	return nil
+}
+
+func (r Repository) LockDue(ctx context.Context, now time.Time, consumers []string) (outbox.Message, error) {
+	var m model.OutboxMessage
+	query := "SELECT * FROM outbox_messages WHERE status = 'pending' AND next_attempt_at <= $1 AND consumer = ANY($2) ORDER BY next_attempt_at, created_at LIMIT 1 FOR UPDATE SKIP LOCKED"
+	if err := postgres.Conn(ctx, r.db).GetContext(ctx, &m, query, now, pq.Array(consumers)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return outbox.Message{}, ierr.OutboxMessageNotFound{}
+		}
+
+		return outbox.Message{}, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	return toEntity(m), nil
+}
+
+func (r Repository) Update(ctx context.Context, m outbox.Message) error {
+	if _, err := postgres.Conn(ctx, r.db).NamedExecContext(ctx, updateMessageQuery, toModel(m)); err != nil {
+		return fmt.Errorf("failed to update outbox message: %w", err)
+	}
+
+	return nil
+}
+
+func toModel(m outbox.Message) model.OutboxMessage {
+	return model.OutboxMessage{
+		ID:            m.ID,
+		Consumer:      m.Consumer,
+		Topic:         m.Topic,
+		Key:           m.Key,
+		Payload:       m.Payload,
+		Status:        string(m.Status),
+		Attempts:      m.Attempts,
+		NextAttemptAt: m.NextAttemptAt,
+		LastError:     m.LastError,
+		PublishedAt:   model.NewNullTime(m.PublishedAt),
+		FailedAt:      model.NewNullTime(m.FailedAt),
+		CreatedAt:     m.CreatedAt,
+	}
+}
+
+func toEntity(m model.OutboxMessage) outbox.Message {
+	return outbox.Message{
+		ID:            m.ID,
+		Consumer:      m.Consumer,
+		Topic:         m.Topic,
+		Key:           m.Key,
+		Payload:       m.Payload,
+		Status:        outbox.Status(m.Status),
+		Attempts:      m.Attempts,
+		NextAttemptAt: m.NextAttemptAt,
+		LastError:     m.LastError,
+		PublishedAt:   m.PublishedAt.Time,
+		FailedAt:      m.FailedAt.Time,
+		CreatedAt:     m.CreatedAt,
+	}
+}
+
+func NewRepository(db *sqlx.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
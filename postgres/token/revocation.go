@@ -0,0 +1,38 @@
+package token
+
+import (
+	"context"
+	"fmt"
+	"rekber/config"
+	"rekber/internal/user"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (r Repository) Revoke(ctx context.Context, t user.AccessToken) error {
+	if _, err := r.db.ExecContext(ctx, "INSERT INTO revoked_access_tokens (token_id, user_id, expired_at) VALUES ($1, $2, $3) ON CONFLICT (token_id) DO NOTHING", t.ID, t.UserID, t.ExpiredAt); err != nil {
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+
+	return nil
+}
+
+func (r Repository) RevokeAllBefore(ctx context.Context, userID uuid.UUID, before time.Time) error {
+	// every access token issued before is expired by then, so the row is not needed anymore
+	expiredAt := before.Add(config.Get().JWT.AccessToken.Duration)
+	if _, err := r.db.ExecContext(ctx, "INSERT INTO revoked_access_tokens (user_id, issued_before, expired_at) VALUES ($1, $2, $3)", userID, before, expiredAt); err != nil {
+		return fmt.Errorf("failed to revoke access tokens: %w", err)
+	}
+
+	return nil
+}
+
+func (r Repository) IsRevoked(ctx context.Context, t user.AccessToken) (bool, error) {
+	var revoked bool
+	if err := r.db.GetContext(ctx, &revoked, "SELECT EXISTS(SELECT 1 FROM revoked_access_tokens WHERE token_id = $1 OR (user_id = $2 AND issued_before >= $3))", t.ID, t.UserID, t.IssuedAt); err != nil {
+		return false, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	return revoked, nil
+}
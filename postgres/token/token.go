@@ -0,0 +1,117 @@
+package token
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"rekber/ierr"
+	"rekber/internal/user"
+	"rekber/postgres/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const insertRefreshTokenQuery = `INSERT INTO refresh_tokens (id, family_id, user_id, expired_at, rotated_at, revoked_at, created_at)
+	VALUES (:id, :family_id, :user_id, :expired_at, :rotated_at, :revoked_at, :created_at)`
+
+type Repository struct {
+	db *sqlx.DB
+}
+
+func (r Repository) Save(ctx context.Context, rt user.RefreshToken) error {
+	if _, err := r.db.NamedExecContext(ctx, insertRefreshTokenQuery, toRefreshTokenModel(rt)); err != nil {
+		return fmt.Errorf("failed to insert refresh token: %w", err)
+	}
+
+	return nil
+}
+
+func (r Repository) GetByID(ctx context.Context, id uuid.UUID) (user.RefreshToken, error) {
+	var rt model.RefreshToken
+	if err := r.db.GetContext(ctx, &rt, "SELECT * FROM refresh_tokens WHERE id = $1", id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return user.RefreshToken{}, ierr.JWTError{}
+		}
+
+		return user.RefreshToken{}, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	return user.RefreshToken{
+		ID:        rt.ID,
+		FamilyID:  rt.FamilyID,
+		UserID:    rt.UserID,
+		ExpiredAt: rt.ExpiredAt,
+		RotatedAt: rt.RotatedAt.Time,
+		RevokedAt: rt.RevokedAt.Time,
+		CreatedAt: rt.CreatedAt,
+	}, nil
+}
+
+func (r Repository) Rotate(ctx context.Context, oldID uuid.UUID, rt user.RefreshToken) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "UPDATE refresh_tokens SET rotated_at = $2 WHERE id = $1 AND rotated_at IS NULL AND revoked_at IS NULL", oldID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return ierr.RefreshTokenReused{}
+	}
+
+	if _, err := tx.NamedExecContext(ctx, insertRefreshTokenQuery, toRefreshTokenModel(rt)); err != nil {
+		return fmt.Errorf("failed to insert refresh token: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r Repository) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx, "UPDATE refresh_tokens SET revoked_at = $2 WHERE family_id = $1 AND revoked_at IS NULL", familyID, time.Now()); err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+
+	return nil
+}
+
+func (r Repository) RevokeByUserID(ctx context.Context, userID uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx, "UPDATE refresh_tokens SET revoked_at = $2 WHERE user_id = $1 AND revoked_at IS NULL", userID, time.Now()); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	return nil
+}
+
+func toRefreshTokenModel(rt user.RefreshToken) model.RefreshToken {
+	return model.RefreshToken{
+		ID:        rt.ID,
+		FamilyID:  rt.FamilyID,
+		UserID:    rt.UserID,
+		ExpiredAt: rt.ExpiredAt,
+		RotatedAt: model.NewNullTime(rt.RotatedAt),
+		RevokedAt: model.NewNullTime(rt.RevokedAt),
+		CreatedAt: rt.CreatedAt,
+	}
+}
+
+func NewRepository(db *sqlx.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
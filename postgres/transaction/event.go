@@ -0,0 +1,82 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"rekber/ierr"
+	"rekber/internal/transaction"
+	"rekber/postgres"
+	"rekber/postgres/model"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const insertEventQuery = `INSERT INTO transaction_event_store (transaction_id, version, type, data, occurred_at)
+	VALUES (:transaction_id, :version, :type, :data, :occurred_at)`
+
+// Append inserts the events after checking expectedVersion is the last version of the
+// transaction. The primary key on the transaction and the version catches a writer which appends
+// between the check and the insert.
+func (r Repository) Append(ctx context.Context, transactionID uuid.UUID, expectedVersion int, events ...transaction.Event) error {
+	return postgres.NewTransactor(r.db).WithinTransaction(ctx, func(ctx context.Context) error {
+		conn := postgres.Conn(ctx, r.db)
+
+		var last int
+		if err := conn.GetContext(ctx, &last, "SELECT COALESCE(MAX(version), 0) FROM transaction_event_store WHERE transaction_id = $1", transactionID); err != nil {
+			return fmt.Errorf("failed to query from database: %w", err)
+		}
+
+		if last != expectedVersion {
+			return ierr.EventVersionConflict{TransactionID: transactionID, Version: expectedVersion + 1}
+		}
+
+		for _, e := range events {
+			m, err := toStoredEventModel(e)
+			if err != nil {
+				return err
+			}
+
+			if _, err := conn.NamedExecContext(ctx, insertEventQuery, m); err != nil {
+				var pqErr *pq.Error
+				if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+					return ierr.EventVersionConflict{TransactionID: transactionID, Version: e.Version}
+				}
+
+				return fmt.Errorf("failed to insert event: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r Repository) Load(ctx context.Context, transactionID uuid.UUID) ([]transaction.Event, error) {
+	var events []model.StoredEvent
+	query := "SELECT * FROM transaction_event_store WHERE transaction_id = $1 ORDER BY version"
+	if err := postgres.Conn(ctx, r.db).SelectContext(ctx, &events, query, transactionID); err != nil {
+		return nil, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	result := make([]transaction.Event, 0, len(events))
+	for _, e := range events {
+		event, err := toEventEntity(e)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, event)
+	}
+
+	return result, nil
+}
+
+func (r Repository) ListTransactionIDs(ctx context.Context) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := postgres.Conn(ctx, r.db).SelectContext(ctx, &ids, "SELECT transaction_id FROM transaction_event_store WHERE version = 1 ORDER BY occurred_at"); err != nil {
+		return nil, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,408 @@
+package transaction
+
+import (
+	"encoding/json"
+	"fmt"
+	"rekber/internal/transaction"
+	"rekber/postgres/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func toModel(t transaction.Transaction) model.Transaction {
+	return model.Transaction{
+		ID:                  t.ID,
+		BuyerID:             t.Buyer.ID,
+		SellerID:            t.Seller.ID,
+		Currency:            string(t.Breakdown.ItemTotal.Currency),
+		ItemTotal:           t.Breakdown.ItemTotal.Amount,
+		EscrowFee:           t.Breakdown.EscrowFee.Amount,
+		SellerNet:           t.Breakdown.SellerNet.Amount,
+		CreatedBy:           int16(t.CreatedBy),
+		CreatedAt:           t.CreatedAt,
+		AcceptedBy:          model.NewNullInt16(int16(t.AcceptedBy)),
+		AcceptedAt:          model.NewNullTime(t.AcceptedAt),
+		RejectedBy:          model.NewNullInt16(int16(t.RejectedBy)),
+		RejectedAt:          model.NewNullTime(t.RejectedAt),
+		RejectedReason:      model.NewNullString(t.RejectedReason),
+		PaidAt:              model.NewNullTime(t.PaidAt),
+		ExpiredAt:           model.NewNullTime(t.ExpiredAt),
+		ExpiredReason:       model.NewNullString(t.ExpiredReason),
+		DoneBySellerAt:      model.NewNullTime(t.DoneBySellerAt),
+		SuccessAt:           model.NewNullTime(t.SuccessAt),
+		SuccessBy:           model.NewNullInt16(int16(t.SuccessBy)),
+		InspectionStartedAt: model.NewNullTime(t.InspectionStartedAt),
+		ReleaseRemindedAt:   model.NewNullTime(t.ReleaseRemindedAt),
+		DisputedAt:          model.NewNullTime(t.DisputedAt),
+		DisputeReason:       model.NewNullString(t.DisputeReason),
+		DisputeDeadline:     model.NewNullTime(t.DisputeDeadline),
+		DisputeResponse:     model.NewNullString(t.DisputeResponse),
+		DisputeRespondedAt:  model.NewNullTime(t.DisputeRespondedAt),
+		DisputeEscalatedAt:  model.NewNullTime(t.DisputeEscalatedAt),
+		ResolvedAt:          model.NewNullTime(t.ResolvedAt),
+		ResolvedBy:          uuid.NullUUID{UUID: t.ResolvedBy, Valid: t.ResolvedBy != uuid.Nil},
+		ResolutionNote:      model.NewNullString(t.ResolutionNote),
+		RefundAmount:        t.RefundAmount.Amount,
+		Status:              int16(t.Status),
+		Version:             t.Version,
+	}
+}
+
+func toItemModels(t transaction.Transaction) []model.TransactionItem {
+	items := make([]model.TransactionItem, 0, len(t.Items))
+	for i, item := range t.Items {
+		items = append(items, model.TransactionItem{
+			ID:            uuid.New(),
+			TransactionID: t.ID,
+			Position:      int16(i),
+			Name:          item.Name,
+			Description:   item.Description,
+			Quantity:      item.Quantity,
+			Price:         item.Price.Amount,
+			Currency:      string(item.Price.Currency),
+		})
+	}
+
+	return items
+}
+
+func toEntity(m model.Transaction, items []model.TransactionItem) transaction.Transaction {
+	currency := transaction.Currency(m.Currency)
+
+	trxItems := make([]transaction.Item, 0, len(items))
+	for _, item := range items {
+		trxItems = append(trxItems, transaction.Item{
+			Name:        item.Name,
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			Price:       transaction.NewMoney(item.Price, transaction.Currency(item.Currency)),
+		})
+	}
+
+	return transaction.Transaction{
+		ID: m.ID,
+		Seller: transaction.Seller{
+			ID: m.SellerID,
+		},
+		Buyer: transaction.Buyer{
+			ID: m.BuyerID,
+		},
+		Items: trxItems,
+		Breakdown: transaction.Breakdown{
+			ItemTotal: transaction.NewMoney(m.ItemTotal, currency),
+			EscrowFee: transaction.NewMoney(m.EscrowFee, currency),
+			SellerNet: transaction.NewMoney(m.SellerNet, currency),
+		},
+		CreatedBy:           transaction.Actors(m.CreatedBy),
+		CreatedAt:           m.CreatedAt,
+		AcceptedAt:          m.AcceptedAt.Time,
+		AcceptedBy:          transaction.Actors(m.AcceptedBy.Int16),
+		RejectedAt:          m.RejectedAt.Time,
+		RejectedBy:          transaction.Actors(m.RejectedBy.Int16),
+		RejectedReason:      m.RejectedReason.String,
+		PaidAt:              m.PaidAt.Time,
+		ExpiredAt:           m.ExpiredAt.Time,
+		ExpiredReason:       m.ExpiredReason.String,
+		SuccessAt:           m.SuccessAt.Time,
+		SuccessBy:           transaction.Actors(m.SuccessBy.Int16),
+		InspectionStartedAt: m.InspectionStartedAt.Time,
+		ReleaseRemindedAt:   m.ReleaseRemindedAt.Time,
+		DisputedAt:          m.DisputedAt.Time,
+		DisputeReason:       m.DisputeReason.String,
+		DisputeDeadline:     m.DisputeDeadline.Time,
+		DisputeResponse:     m.DisputeResponse.String,
+		DisputeRespondedAt:  m.DisputeRespondedAt.Time,
+		DisputeEscalatedAt:  m.DisputeEscalatedAt.Time,
+		ResolvedAt:          m.ResolvedAt.Time,
+		ResolvedBy:          m.ResolvedBy.UUID,
+		ResolutionNote:      m.ResolutionNote.String,
+		RefundAmount:        transaction.NewMoney(m.RefundAmount, currency),
+		DoneBySellerAt:      m.DoneBySellerAt.Time,
+		Status:              transaction.Status(m.Status),
+		Version:             m.Version,
+	}
+}
+
+func toPaymentModel(p transaction.Payment) model.Payment {
+	return model.Payment{
+		ID:                   p.ID,
+		TransactionID:        p.TransactionID,
+		Method:               string(p.Method),
+		BankCode:             p.BankCode,
+		ExternalID:           p.ExternalID,
+		VirtualAccountNumber: p.VirtualAccountNumber,
+		QRString:             p.QRString,
+		Amount:               p.Amount.Amount,
+		Currency:             string(p.Amount.Currency),
+		Status:               string(p.Status),
+		ExpiredAt:            p.ExpiredAt,
+		PaidAt:               model.NewNullTime(p.PaidAt),
+		RefundReason:         p.RefundReason,
+		CreatedAt:            p.CreatedAt,
+	}
+}
+
+func toPaymentEntity(p model.Payment) transaction.Payment {
+	return transaction.Payment{
+		ID:                   p.ID,
+		TransactionID:        p.TransactionID,
+		Method:               transaction.PaymentMethod(p.Method),
+		BankCode:             p.BankCode,
+		ExternalID:           p.ExternalID,
+		VirtualAccountNumber: p.VirtualAccountNumber,
+		QRString:             p.QRString,
+		Amount:               transaction.NewMoney(p.Amount, transaction.Currency(p.Currency)),
+		Status:               transaction.PaymentStatus(p.Status),
+		ExpiredAt:            p.ExpiredAt,
+		PaidAt:               p.PaidAt.Time,
+		RefundReason:         p.RefundReason,
+		CreatedAt:            p.CreatedAt,
+	}
+}
+
+func toPayoutModel(p transaction.Payout) model.Payout {
+	return model.Payout{
+		ID:            p.ID,
+		TransactionID: p.TransactionID,
+		SellerID:      p.SellerID,
+		BankAccountID: uuid.NullUUID{UUID: p.BankAccountID, Valid: p.BankAccountID != uuid.Nil},
+		BankCode:      p.BankCode,
+		AccountNumber: p.AccountNumber,
+		AccountName:   p.AccountName,
+		Amount:        p.Amount.Amount,
+		Currency:      string(p.Amount.Currency),
+		Status:        string(p.Status),
+		Attempts:      p.Attempts,
+		NextAttemptAt: p.NextAttemptAt,
+		LastError:     p.LastError,
+		ExternalID:    p.ExternalID,
+		SucceededAt:   model.NewNullTime(p.SucceededAt),
+		FailedAt:      model.NewNullTime(p.FailedAt),
+		CreatedAt:     p.CreatedAt,
+	}
+}
+
+func toPayoutEntity(p model.Payout) transaction.Payout {
+	return transaction.Payout{
+		ID:            p.ID,
+		TransactionID: p.TransactionID,
+		SellerID:      p.SellerID,
+		BankAccountID: p.BankAccountID.UUID,
+		BankCode:      p.BankCode,
+		AccountNumber: p.AccountNumber,
+		AccountName:   p.AccountName,
+		Amount:        transaction.NewMoney(p.Amount, transaction.Currency(p.Currency)),
+		Retry: transaction.Retry{
+			Status:        transaction.RetryStatus(p.Status),
+			Attempts:      p.Attempts,
+			NextAttemptAt: p.NextAttemptAt,
+			LastError:     p.LastError,
+			ExternalID:    p.ExternalID,
+			SucceededAt:   p.SucceededAt.Time,
+			FailedAt:      p.FailedAt.Time,
+		},
+		CreatedAt: p.CreatedAt,
+	}
+}
+
+func toRefundModel(r transaction.Refund) model.Refund {
+	return model.Refund{
+		ID:                r.ID,
+		TransactionID:     r.TransactionID,
+		BuyerID:           r.BuyerID,
+		PaymentID:         r.PaymentID,
+		PaymentExternalID: r.PaymentExternalID,
+		Amount:            r.Amount.Amount,
+		Currency:          string(r.Amount.Currency),
+		LatePayment:       r.LatePayment,
+		Status:            string(r.Status),
+		Attempts:          r.Attempts,
+		NextAttemptAt:     r.NextAttemptAt,
+		LastError:         r.LastError,
+		ExternalID:        r.ExternalID,
+		SucceededAt:       model.NewNullTime(r.SucceededAt),
+		FailedAt:          model.NewNullTime(r.FailedAt),
+		CreatedAt:         r.CreatedAt,
+	}
+}
+
+func toRefundEntity(r model.Refund) transaction.Refund {
+	return transaction.Refund{
+		ID:                r.ID,
+		TransactionID:     r.TransactionID,
+		BuyerID:           r.BuyerID,
+		PaymentID:         r.PaymentID,
+		PaymentExternalID: r.PaymentExternalID,
+		Amount:            transaction.NewMoney(r.Amount, transaction.Currency(r.Currency)),
+		LatePayment:       r.LatePayment,
+		Retry: transaction.Retry{
+			Status:        transaction.RetryStatus(r.Status),
+			Attempts:      r.Attempts,
+			NextAttemptAt: r.NextAttemptAt,
+			LastError:     r.LastError,
+			ExternalID:    r.ExternalID,
+			SucceededAt:   r.SucceededAt.Time,
+			FailedAt:      r.FailedAt.Time,
+		},
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+func toShipmentModel(s transaction.Shipment) (model.Shipment, error) {
+	events := make([]model.TrackingEvent, 0, len(s.Events))
+	for _, e := range s.Events {
+		events = append(events, model.TrackingEvent{
+			Status:      e.Status,
+			Description: e.Description,
+			Location:    e.Location,
+			OccurredAt:  e.OccurredAt,
+		})
+	}
+
+	b, err := json.Marshal(events)
+	if err != nil {
+		return model.Shipment{}, fmt.Errorf("failed to marshal tracking events: %w", err)
+	}
+
+	return model.Shipment{
+		ID:            s.ID,
+		TransactionID: s.TransactionID,
+		CourierCode:   s.CourierCode,
+		AirwayBill:    s.AirwayBill,
+		Status:        string(s.Status),
+		Events:        b,
+		DeliveredAt:   model.NewNullTime(s.DeliveredAt),
+		LastCheckedAt: model.NewNullTime(s.LastCheckedAt),
+		LastError:     s.LastError,
+		CreatedAt:     s.CreatedAt,
+	}, nil
+}
+
+func toShipmentEntity(s model.Shipment) (transaction.Shipment, error) {
+	var events []model.TrackingEvent
+	if err := json.Unmarshal(s.Events, &events); err != nil {
+		return transaction.Shipment{}, fmt.Errorf("failed to unmarshal tracking events: %w", err)
+	}
+
+	trackingEvents := make([]transaction.TrackingEvent, 0, len(events))
+	for _, e := range events {
+		trackingEvents = append(trackingEvents, transaction.TrackingEvent{
+			Status:      e.Status,
+			Description: e.Description,
+			Location:    e.Location,
+			OccurredAt:  e.OccurredAt,
+		})
+	}
+
+	return transaction.Shipment{
+		ID:            s.ID,
+		TransactionID: s.TransactionID,
+		Shipping: transaction.Shipping{
+			CourierCode: s.CourierCode,
+			AirwayBill:  s.AirwayBill,
+		},
+		Status:        transaction.ShipmentStatus(s.Status),
+		Events:        trackingEvents,
+		DeliveredAt:   s.DeliveredAt.Time,
+		LastCheckedAt: s.LastCheckedAt.Time,
+		LastError:     s.LastError,
+		CreatedAt:     s.CreatedAt,
+	}, nil
+}
+
+func toTransactionEventModel(e transaction.TimelineEvent) model.TransactionEvent {
+	return model.TransactionEvent{
+		ID:            e.ID,
+		TransactionID: e.TransactionID,
+		FromStatus:    model.NewNullInt16(int16(e.From)),
+		ToStatus:      int16(e.To),
+		Action:        int16(e.Action),
+		Actor:         int16(e.Actor),
+		ActorUserID:   uuid.NullUUID{UUID: e.ActorUserID, Valid: e.ActorUserID != uuid.Nil},
+		Reason:        model.NewNullString(e.Reason),
+		RequestID:     model.NewNullString(e.RequestID),
+		CreatedAt:     e.CreatedAt,
+	}
+}
+
+func toTimelineEventEntity(e model.TransactionEvent) transaction.TimelineEvent {
+	return transaction.TimelineEvent{
+		ID:            e.ID,
+		TransactionID: e.TransactionID,
+		From:          transaction.Status(e.FromStatus.Int16),
+		To:            transaction.Status(e.ToStatus),
+		Action:        transaction.Action(e.Action),
+		Actor:         transaction.Actors(e.Actor),
+		ActorUserID:   e.ActorUserID.UUID,
+		Reason:        e.Reason.String,
+		RequestID:     e.RequestID.String,
+		CreatedAt:     e.CreatedAt,
+	}
+}
+
+func toStoredEventModel(e transaction.Event) (model.StoredEvent, error) {
+	data := model.EventData{
+		Actor:          int16(e.Actor),
+		UserID:         uuid.NullUUID{UUID: e.UserID, Valid: e.UserID != uuid.Nil},
+		Reason:         e.Reason,
+		RefundAmount:   e.Refund.Amount,
+		RefundCurrency: string(e.Refund.Currency),
+		CourierCode:    e.Shipping.CourierCode,
+		AirwayBill:     e.Shipping.AirwayBill,
+		WindowSeconds:  int64(e.Window / time.Second),
+	}
+	if !e.DeliveredAt.IsZero() {
+		data.DeliveredAt = &e.DeliveredAt
+	}
+	if e.Snapshot.ID != uuid.Nil {
+		data.Snapshot = &model.TransactionSnapshot{
+			Transaction: toModel(e.Snapshot),
+			Items:       toItemModels(e.Snapshot),
+		}
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return model.StoredEvent{}, fmt.Errorf("failed to marshal event data: %w", err)
+	}
+
+	return model.StoredEvent{
+		TransactionID: e.TransactionID,
+		Version:       e.Version,
+		Type:          string(e.Type),
+		Data:          b,
+		OccurredAt:    e.OccurredAt,
+	}, nil
+}
+
+func toEventEntity(e model.StoredEvent) (transaction.Event, error) {
+	var data model.EventData
+	if err := json.Unmarshal(e.Data, &data); err != nil {
+		return transaction.Event{}, fmt.Errorf("failed to unmarshal event data: %w", err)
+	}
+
+	event := transaction.Event{
+		TransactionID: e.TransactionID,
+		Version:       e.Version,
+		Type:          transaction.EventType(e.Type),
+		Actor:         transaction.Actors(data.Actor),
+		UserID:        data.UserID.UUID,
+		Reason:        data.Reason,
+		Refund:        transaction.NewMoney(data.RefundAmount, transaction.Currency(data.RefundCurrency)),
+		Shipping: transaction.Shipping{
+			CourierCode: data.CourierCode,
+			AirwayBill:  data.AirwayBill,
+		},
+		Window:     time.Duration(data.WindowSeconds) * time.Second,
+		OccurredAt: e.OccurredAt,
+	}
+	if data.DeliveredAt != nil {
+		event.DeliveredAt = *data.DeliveredAt
+	}
+	if data.Snapshot != nil {
+		event.Snapshot = toEntity(data.Snapshot.Transaction, data.Snapshot.Items)
+	}
+
+	return event, nil
+}
@@ -0,0 +1,116 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"rekber/ierr"
+	"rekber/internal/transaction"
+	"rekber/postgres"
+	"rekber/postgres/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const insertPaymentQuery = `INSERT INTO payments (id, transaction_id, method, bank_code, external_id, virtual_account_number, qr_string, amount, currency, status, expired_at, paid_at, refund_reason, created_at)
+	VALUES (:id, :transaction_id, :method, :bank_code, :external_id, :virtual_account_number, :qr_string, :amount, :currency, :status, :expired_at, :paid_at, :refund_reason, :created_at)`
+
+func (r Repository) SavePayment(ctx context.Context, p transaction.Payment) error {
+	if _, err := postgres.Conn(ctx, r.db).NamedExecContext(ctx, insertPaymentQuery, toPaymentModel(p)); err != nil {
+		return fmt.Errorf("failed to insert payment: %w", err)
+	}
+
+	return nil
+}
+
+func (r Repository) GetPendingPayment(ctx context.Context, transactionID uuid.UUID) (transaction.Payment, error) {
+	var p model.Payment
+	query := "SELECT * FROM payments WHERE transaction_id = $1 AND status = 'pending' AND expired_at > $2 ORDER BY created_at DESC LIMIT 1"
+	if err := postgres.Conn(ctx, r.db).GetContext(ctx, &p, query, transactionID, time.Now()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return transaction.Payment{}, ierr.PaymentNotFound{TransactionID: transactionID}
+		}
+
+		return transaction.Payment{}, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	return toPaymentEntity(p), nil
+}
+
+func (r Repository) GetPaymentByExternalID(ctx context.Context, externalID string) (transaction.Payment, error) {
+	var p model.Payment
+	if err := postgres.Conn(ctx, r.db).GetContext(ctx, &p, "SELECT * FROM payments WHERE external_id = $1", externalID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return transaction.Payment{}, ierr.PaymentNotFound{ExternalID: externalID}
+		}
+
+		return transaction.Payment{}, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	return toPaymentEntity(p), nil
+}
+
+// GetPaidPayment returns the paid payment of the transaction which is not flagged for refund, a
+// transaction is paid by one payment only.
+func (r Repository) GetPaidPayment(ctx context.Context, transactionID uuid.UUID) (transaction.Payment, error) {
+	var p model.Payment
+	query := "SELECT * FROM payments WHERE transaction_id = $1 AND status = 'paid' AND refund_reason = '' ORDER BY paid_at LIMIT 1"
+	if err := postgres.Conn(ctx, r.db).GetContext(ctx, &p, query, transactionID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return transaction.Payment{}, ierr.PaymentNotFound{TransactionID: transactionID}
+		}
+
+		return transaction.Payment{}, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	return toPaymentEntity(p), nil
+}
+
+func (r Repository) MarkPaymentPaid(ctx context.Context, id uuid.UUID, paidAt time.Time) (bool, error) {
+	res, err := postgres.Conn(ctx, r.db).ExecContext(ctx, "UPDATE payments SET status = 'paid', paid_at = $2 WHERE id = $1 AND status IN ('pending', 'expired')", id, paidAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to update payment: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+func (r Repository) ExpirePendingPayments(ctx context.Context, transactionID, except uuid.UUID) error {
+	query := "UPDATE payments SET status = 'expired' WHERE transaction_id = $1 AND id <> $2 AND status = 'pending'"
+	if _, err := postgres.Conn(ctx, r.db).ExecContext(ctx, query, transactionID, except); err != nil {
+		return fmt.Errorf("failed to update payments: %w", err)
+	}
+
+	return nil
+}
+
+func (r Repository) FlagPaymentRefund(ctx context.Context, id uuid.UUID, reason string) error {
+	if _, err := postgres.Conn(ctx, r.db).ExecContext(ctx, "UPDATE payments SET refund_reason = $2 WHERE id = $1", id, reason); err != nil {
+		return fmt.Errorf("failed to update payment: %w", err)
+	}
+
+	return nil
+}
+
+func (r Repository) ListRefundDuePayments(ctx context.Context) ([]transaction.Payment, error) {
+	var payments []model.Payment
+	query := `SELECT p.* FROM payments p LEFT JOIN refunds r ON r.payment_id = p.id
+		WHERE p.refund_reason <> '' AND r.succeeded_at IS NULL ORDER BY p.paid_at`
+	if err := postgres.Conn(ctx, r.db).SelectContext(ctx, &payments, query); err != nil {
+		return nil, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	result := make([]transaction.Payment, 0, len(payments))
+	for _, p := range payments {
+		result = append(result, toPaymentEntity(p))
+	}
+
+	return result, nil
+}
@@ -0,0 +1,93 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"rekber/ierr"
+	"rekber/internal/transaction"
+	"rekber/postgres"
+	"rekber/postgres/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	insertPayoutQuery = `INSERT INTO payouts (id, transaction_id, seller_id, bank_account_id, bank_code, account_number, account_name, amount, currency, status, attempts, next_attempt_at, last_error, external_id, succeeded_at, failed_at, created_at)
+		VALUES (:id, :transaction_id, :seller_id, :bank_account_id, :bank_code, :account_number, :account_name, :amount, :currency, :status, :attempts, :next_attempt_at, :last_error, :external_id, :succeeded_at, :failed_at, :created_at)`
+
+	updatePayoutQuery = `UPDATE payouts SET
+		bank_account_id = :bank_account_id, bank_code = :bank_code, account_number = :account_number, account_name = :account_name,
+		status = :status, attempts = :attempts, next_attempt_at = :next_attempt_at, last_error = :last_error,
+		external_id = :external_id, succeeded_at = :succeeded_at, failed_at = :failed_at
+		WHERE id = :id`
+)
+
+func (r Repository) SavePayout(ctx context.Context, p transaction.Payout) error {
+	if _, err := postgres.Conn(ctx, r.db).NamedExecContext(ctx, insertPayoutQuery, toPayoutModel(p)); err != nil {
+		return fmt.Errorf("failed to insert payout: %w", err)
+	}
+
+	return nil
+}
+
+func (r Repository) GetPayout(ctx context.Context, transactionID uuid.UUID) (transaction.Payout, error) {
+	var p model.Payout
+	if err := postgres.Conn(ctx, r.db).GetContext(ctx, &p, "SELECT * FROM payouts WHERE transaction_id = $1", transactionID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return transaction.Payout{}, ierr.PayoutNotFound{TransactionID: transactionID}
+		}
+
+		return transaction.Payout{}, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	return toPayoutEntity(p), nil
+}
+
+func (r Repository) ListPayouts(ctx context.Context, transactionIDs []uuid.UUID) ([]transaction.Payout, error) {
+	if len(transactionIDs) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(transactionIDs))
+	for _, id := range transactionIDs {
+		ids = append(ids, id.String())
+	}
+
+	var payouts []model.Payout
+	if err := postgres.Conn(ctx, r.db).SelectContext(ctx, &payouts, "SELECT * FROM payouts WHERE transaction_id = ANY($1)", pq.Array(ids)); err != nil {
+		return nil, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	result := make([]transaction.Payout, 0, len(payouts))
+	for _, p := range payouts {
+		result = append(result, toPayoutEntity(p))
+	}
+
+	return result, nil
+}
+
+func (r Repository) LockDuePayout(ctx context.Context, now time.Time) (transaction.Payout, error) {
+	var p model.Payout
+	query := "SELECT * FROM payouts WHERE status = 'pending' AND next_attempt_at <= $1 ORDER BY next_attempt_at LIMIT 1 FOR UPDATE SKIP LOCKED"
+	if err := postgres.Conn(ctx, r.db).GetContext(ctx, &p, query, now); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return transaction.Payout{}, ierr.PayoutNotFound{}
+		}
+
+		return transaction.Payout{}, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	return toPayoutEntity(p), nil
+}
+
+func (r Repository) UpdatePayout(ctx context.Context, p transaction.Payout) error {
+	if _, err := postgres.Conn(ctx, r.db).NamedExecContext(ctx, updatePayoutQuery, toPayoutModel(p)); err != nil {
+		return fmt.Errorf("failed to update payout: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"rekber/ierr"
+	"rekber/internal/transaction"
+	"rekber/postgres"
+	"rekber/postgres/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	insertRefundQuery = `INSERT INTO refunds (id, transaction_id, buyer_id, payment_id, payment_external_id, amount, currency, late_payment, status, attempts, next_attempt_at, last_error, external_id, succeeded_at, failed_at, created_at)
+		VALUES (:id, :transaction_id, :buyer_id, :payment_id, :payment_external_id, :amount, :currency, :late_payment, :status, :attempts, :next_attempt_at, :last_error, :external_id, :succeeded_at, :failed_at, :created_at)`
+
+	updateRefundQuery = `UPDATE refunds SET
+		status = :status, attempts = :attempts, next_attempt_at = :next_attempt_at, last_error = :last_error,
+		external_id = :external_id, succeeded_at = :succeeded_at, failed_at = :failed_at
+		WHERE id = :id`
+)
+
+func (r Repository) SaveRefund(ctx context.Context, rf transaction.Refund) error {
+	if _, err := postgres.Conn(ctx, r.db).NamedExecContext(ctx, insertRefundQuery, toRefundModel(rf)); err != nil {
+		return fmt.Errorf("failed to insert refund: %w", err)
+	}
+
+	return nil
+}
+
+func (r Repository) GetRefund(ctx context.Context, transactionID uuid.UUID) (transaction.Refund, error) {
+	var rf model.Refund
+	if err := postgres.Conn(ctx, r.db).GetContext(ctx, &rf, "SELECT * FROM refunds WHERE transaction_id = $1 AND NOT late_payment", transactionID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return transaction.Refund{}, ierr.RefundNotFound{TransactionID: transactionID}
+		}
+
+		return transaction.Refund{}, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	return toRefundEntity(rf), nil
+}
+
+func (r Repository) ListRefunds(ctx context.Context, transactionIDs []uuid.UUID) ([]transaction.Refund, error) {
+	if len(transactionIDs) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(transactionIDs))
+	for _, id := range transactionIDs {
+		ids = append(ids, id.String())
+	}
+
+	var refunds []model.Refund
+	if err := postgres.Conn(ctx, r.db).SelectContext(ctx, &refunds, "SELECT * FROM refunds WHERE transaction_id = ANY($1) AND NOT late_payment", pq.Array(ids)); err != nil {
+		return nil, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	result := make([]transaction.Refund, 0, len(refunds))
+	for _, rf := range refunds {
+		result = append(result, toRefundEntity(rf))
+	}
+
+	return result, nil
+}
+
+func (r Repository) LockDueRefund(ctx context.Context, now time.Time) (transaction.Refund, error) {
+	var rf model.Refund
+	query := "SELECT * FROM refunds WHERE status = 'pending' AND next_attempt_at <= $1 ORDER BY next_attempt_at LIMIT 1 FOR UPDATE SKIP LOCKED"
+	if err := postgres.Conn(ctx, r.db).GetContext(ctx, &rf, query, now); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return transaction.Refund{}, ierr.RefundNotFound{}
+		}
+
+		return transaction.Refund{}, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	return toRefundEntity(rf), nil
+}
+
+func (r Repository) UpdateRefund(ctx context.Context, rf transaction.Refund) error {
+	if _, err := postgres.Conn(ctx, r.db).NamedExecContext(ctx, updateRefundQuery, toRefundModel(rf)); err != nil {
+		return fmt.Errorf("failed to update refund: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"rekber/ierr"
+	"rekber/internal/transaction"
+	"rekber/postgres"
+	"rekber/postgres/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	insertShipmentQuery = `INSERT INTO shipments (id, transaction_id, courier_code, airway_bill, status, events, delivered_at, last_checked_at, last_error, created_at)
+		VALUES (:id, :transaction_id, :courier_code, :airway_bill, :status, :events, :delivered_at, :last_checked_at, :last_error, :created_at)`
+
+	updateShipmentQuery = `UPDATE shipments SET
+		status = :status, events = :events, delivered_at = :delivered_at, last_checked_at = :last_checked_at, last_error = :last_error
+		WHERE id = :id`
+)
+
+func (r Repository) SaveShipment(ctx context.Context, s transaction.Shipment) error {
+	m, err := toShipmentModel(s)
+	if err != nil {
+		return err
+	}
+
+	if _, err := postgres.Conn(ctx, r.db).NamedExecContext(ctx, insertShipmentQuery, m); err != nil {
+		return fmt.Errorf("failed to insert shipment: %w", err)
+	}
+
+	return nil
+}
+
+func (r Repository) GetShipment(ctx context.Context, transactionID uuid.UUID) (transaction.Shipment, error) {
+	var s model.Shipment
+	if err := postgres.Conn(ctx, r.db).GetContext(ctx, &s, "SELECT * FROM shipments WHERE transaction_id = $1", transactionID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return transaction.Shipment{}, ierr.ShipmentNotFound{TransactionID: transactionID}
+		}
+
+		return transaction.Shipment{}, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	return toShipmentEntity(s)
+}
+
+func (r Repository) ListShipments(ctx context.Context, transactionIDs []uuid.UUID) ([]transaction.Shipment, error) {
+	if len(transactionIDs) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(transactionIDs))
+	for _, id := range transactionIDs {
+		ids = append(ids, id.String())
+	}
+
+	var shipments []model.Shipment
+	if err := postgres.Conn(ctx, r.db).SelectContext(ctx, &shipments, "SELECT * FROM shipments WHERE transaction_id = ANY($1)", pq.Array(ids)); err != nil {
+		return nil, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	result := make([]transaction.Shipment, 0, len(shipments))
+	for _, s := range shipments {
+		sh, err := toShipmentEntity(s)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, sh)
+	}
+
+	return result, nil
+}
+
+func (r Repository) LockDueShipment(ctx context.Context, checkedBefore time.Time) (transaction.Shipment, error) {
+	var s model.Shipment
+	// status 6 is done by seller, a transaction which left it or started the inspection does not
+	// wait for the courier anymore
+	query := `SELECT s.* FROM shipments s
+		JOIN transactions t ON t.id = s.transaction_id
+		WHERE s.status = 'in_transit' AND (s.last_checked_at IS NULL OR s.last_checked_at < $1)
+			AND t.status = 6 AND t.inspection_started_at IS NULL
+		ORDER BY s.last_checked_at NULLS FIRST LIMIT 1 FOR UPDATE OF s SKIP LOCKED`
+	if err := postgres.Conn(ctx, r.db).GetContext(ctx, &s, query, checkedBefore); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return transaction.Shipment{}, ierr.ShipmentNotFound{}
+		}
+
+		return transaction.Shipment{}, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	return toShipmentEntity(s)
+}
+
+func (r Repository) UpdateShipment(ctx context.Context, s transaction.Shipment) error {
+	m, err := toShipmentModel(s)
+	if err != nil {
+		return err
+	}
+
+	if _, err := postgres.Conn(ctx, r.db).NamedExecContext(ctx, updateShipmentQuery, m); err != nil {
+		return fmt.Errorf("failed to update shipment: %w", err)
+	}
+
+	return nil
+}
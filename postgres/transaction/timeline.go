@@ -0,0 +1,37 @@
+package transaction
+
+import (
+	"context"
+	"fmt"
+	"rekber/internal/transaction"
+	"rekber/postgres"
+	"rekber/postgres/model"
+
+	"github.com/google/uuid"
+)
+
+const insertTransactionEventQuery = `INSERT INTO transaction_events (id, transaction_id, from_status, to_status, action, actor, actor_user_id, reason, request_id, created_at)
+	VALUES (:id, :transaction_id, :from_status, :to_status, :action, :actor, :actor_user_id, :reason, :request_id, :created_at)`
+
+func (r Repository) SaveTimelineEvent(ctx context.Context, e transaction.TimelineEvent) error {
+	if _, err := postgres.Conn(ctx, r.db).NamedExecContext(ctx, insertTransactionEventQuery, toTransactionEventModel(e)); err != nil {
+		return fmt.Errorf("failed to insert transaction event: %w", err)
+	}
+
+	return nil
+}
+
+func (r Repository) ListTimelineEvents(ctx context.Context, transactionID uuid.UUID) ([]transaction.TimelineEvent, error) {
+	var events []model.TransactionEvent
+	query := "SELECT * FROM transaction_events WHERE transaction_id = $1 ORDER BY created_at, seq"
+	if err := postgres.Conn(ctx, r.db).SelectContext(ctx, &events, query, transactionID); err != nil {
+		return nil, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	result := make([]transaction.TimelineEvent, 0, len(events))
+	for _, e := range events {
+		result = append(result, toTimelineEventEntity(e))
+	}
+
+	return result, nil
+}
@@ -0,0 +1,266 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"rekber/ierr"
+	"rekber/internal/transaction"
+	"rekber/postgres"
+	"rekber/postgres/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const (
+	insertTransactionQuery = `INSERT INTO transactions (id, buyer_id, seller_id, currency, item_total, escrow_fee, seller_net, created_by, created_at, accepted_by, accepted_at, rejected_by, rejected_at, rejected_reason, paid_at, expired_at, expired_reason, done_by_seller_at, success_at, success_by, inspection_started_at, release_reminded_at,
+		disputed_at, dispute_reason, dispute_deadline, dispute_response, dispute_responded_at, dispute_escalated_at, resolved_at, resolved_by, resolution_note, refund_amount, status, version)
+		VALUES (:id, :buyer_id, :seller_id, :currency, :item_total, :escrow_fee, :seller_net, :created_by, :created_at, :accepted_by, :accepted_at, :rejected_by, :rejected_at, :rejected_reason, :paid_at, :expired_at, :expired_reason, :done_by_seller_at, :success_at, :success_by, :inspection_started_at, :release_reminded_at,
+		:disputed_at, :dispute_reason, :dispute_deadline, :dispute_response, :dispute_responded_at, :dispute_escalated_at, :resolved_at, :resolved_by, :resolution_note, :refund_amount, :status, :version)`
+
+	insertTransactionItemQuery = `INSERT INTO transaction_items (id, transaction_id, position, name, description, quantity, price, currency)
+		VALUES (:id, :transaction_id, :position, :name, :description, :quantity, :price, :currency)`
+
+	// setTransactionColumns are the columns which change during the life of a transaction.
+	setTransactionColumns = `
+		accepted_by = :accepted_by, accepted_at = :accepted_at,
+		rejected_by = :rejected_by, rejected_at = :rejected_at, rejected_reason = :rejected_reason,
+		paid_at = :paid_at, expired_at = :expired_at, expired_reason = :expired_reason, done_by_seller_at = :done_by_seller_at, success_at = :success_at,
+		success_by = :success_by, inspection_started_at = :inspection_started_at, release_reminded_at = :release_reminded_at,
+		disputed_at = :disputed_at, dispute_reason = :dispute_reason, dispute_deadline = :dispute_deadline,
+		dispute_response = :dispute_response, dispute_responded_at = :dispute_responded_at, dispute_escalated_at = :dispute_escalated_at,
+		resolved_at = :resolved_at, resolved_by = :resolved_by, resolution_note = :resolution_note, refund_amount = :refund_amount,
+		status = :status, version = :version`
+
+	// updateTransactionQuery only updates the row when the status is still the one the caller read,
+	// so two concurrent actions on the same transaction cannot both win.
+	updateTransactionQuery = `UPDATE transactions SET` + setTransactionColumns + `
+		WHERE id = :id AND status = :last_status`
+
+	projectTransactionQuery = insertTransactionQuery + `
+		ON CONFLICT (id) DO UPDATE SET` + setTransactionColumns
+)
+
+type Repository struct {
+	db *sqlx.DB
+}
+
+func (r Repository) Save(ctx context.Context, t transaction.Transaction) error {
+	return postgres.NewTransactor(r.db).WithinTransaction(ctx, func(ctx context.Context) error {
+		conn := postgres.Conn(ctx, r.db)
+		if _, err := conn.NamedExecContext(ctx, insertTransactionQuery, toModel(t)); err != nil {
+			return fmt.Errorf("failed to insert transaction: %w", err)
+		}
+
+		for _, item := range toItemModels(t) {
+			if _, err := conn.NamedExecContext(ctx, insertTransactionItemQuery, item); err != nil {
+				return fmt.Errorf("failed to insert transaction item: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r Repository) GetByID(ctx context.Context, id uuid.UUID) (transaction.Transaction, error) {
+	var trx model.Transaction
+	if err := postgres.Conn(ctx, r.db).GetContext(ctx, &trx, "SELECT * FROM transactions WHERE id = $1", id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return transaction.Transaction{}, ierr.TransactionNotFound{ID: id}
+		}
+
+		return transaction.Transaction{}, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	items, err := r.getItems(ctx, id)
+	if err != nil {
+		return transaction.Transaction{}, err
+	}
+
+	return toEntity(trx, items[id]), nil
+}
+
+// Update saves the new state of the transaction only if its status in the database is still lastStatus.
+func (r Repository) Update(ctx context.Context, t transaction.Transaction, lastStatus transaction.Status) error {
+	arg := struct {
+		model.Transaction
+		LastStatus int16 `db:"last_status"`
+	}{
+		Transaction: toModel(t),
+		LastStatus:  int16(lastStatus),
+	}
+
+	res, err := postgres.Conn(ctx, r.db).NamedExecContext(ctx, updateTransactionQuery, arg)
+	if err != nil {
+		return fmt.Errorf("failed to update transaction: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return ierr.TransactionUpdateConflict{ID: t.ID}
+	}
+
+	return nil
+}
+
+// ListByUserID returns transactions where the user is either the buyer or the seller, newest first.
+func (r Repository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]transaction.Transaction, error) {
+	return r.list(ctx, "SELECT * FROM transactions WHERE buyer_id = $1 OR seller_id = $1 ORDER BY created_at DESC", userID)
+}
+
+func (r Repository) LockOverdue(ctx context.Context, approvalCutoff, paymentCutoff time.Time) (transaction.Transaction, error) {
+	// status 1 is waiting for approval and 2 is waiting for payment
+	query := `SELECT * FROM transactions
+		WHERE (status = 1 AND created_at < $1) OR (status = 2 AND accepted_at < $2)
+		LIMIT 1 FOR UPDATE SKIP LOCKED`
+
+	return r.lockOne(ctx, query, approvalCutoff, paymentCutoff)
+}
+
+func (r Repository) LockDueReminder(ctx context.Context, cutoff time.Time) (transaction.Transaction, error) {
+	// status 6 is done by seller
+	query := `SELECT * FROM transactions
+		WHERE status = 6 AND inspection_started_at < $1 AND release_reminded_at IS NULL
+		LIMIT 1 FOR UPDATE SKIP LOCKED`
+
+	return r.lockOne(ctx, query, cutoff)
+}
+
+func (r Repository) LockDueRelease(ctx context.Context, cutoff, remindedBefore time.Time) (transaction.Transaction, error) {
+	// status 6 is done by seller
+	query := `SELECT * FROM transactions
+		WHERE status = 6 AND inspection_started_at < $1 AND release_reminded_at <= $2
+		LIMIT 1 FOR UPDATE SKIP LOCKED`
+
+	return r.lockOne(ctx, query, cutoff, remindedBefore)
+}
+
+func (r Repository) LockUndelivered(ctx context.Context, doneBefore time.Time) (transaction.Transaction, error) {
+	// status 6 is done by seller
+	query := `SELECT * FROM transactions
+		WHERE status = 6 AND inspection_started_at IS NULL AND done_by_seller_at < $1
+		LIMIT 1 FOR UPDATE SKIP LOCKED`
+
+	return r.lockOne(ctx, query, doneBefore)
+}
+
+// ListUnrecorded returns the transactions without any event in the event store.
+func (r Repository) ListUnrecorded(ctx context.Context) ([]transaction.Transaction, error) {
+	return r.list(ctx, "SELECT * FROM transactions t WHERE NOT EXISTS (SELECT 1 FROM transaction_event_store e WHERE e.transaction_id = t.id)")
+}
+
+func (r Repository) LockOverdueDispute(ctx context.Context, now time.Time) (transaction.Transaction, error) {
+	// status 8 is disputed
+	query := `SELECT * FROM transactions
+		WHERE status = 8 AND dispute_responded_at IS NULL AND dispute_escalated_at IS NULL AND dispute_deadline < $1
+		LIMIT 1 FOR UPDATE SKIP LOCKED`
+
+	return r.lockOne(ctx, query, now)
+}
+
+func (r Repository) ListEscalatedDisputes(ctx context.Context) ([]transaction.Transaction, error) {
+	return r.list(ctx, "SELECT * FROM transactions WHERE status = 8 AND dispute_escalated_at IS NOT NULL ORDER BY dispute_escalated_at")
+}
+
+// Project overwrites the row and the items of the transaction, the row is inserted if it is missing.
+func (r Repository) Project(ctx context.Context, t transaction.Transaction) error {
+	return postgres.NewTransactor(r.db).WithinTransaction(ctx, func(ctx context.Context) error {
+		conn := postgres.Conn(ctx, r.db)
+		if _, err := conn.NamedExecContext(ctx, projectTransactionQuery, toModel(t)); err != nil {
+			return fmt.Errorf("failed to upsert transaction: %w", err)
+		}
+
+		if _, err := conn.ExecContext(ctx, "DELETE FROM transaction_items WHERE transaction_id = $1", t.ID); err != nil {
+			return fmt.Errorf("failed to delete transaction items: %w", err)
+		}
+
+		for _, item := range toItemModels(t) {
+			if _, err := conn.NamedExecContext(ctx, insertTransactionItemQuery, item); err != nil {
+				return fmt.Errorf("failed to insert transaction item: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// list returns the transactions selected by query with their items.
+func (r Repository) list(ctx context.Context, query string, args ...interface{}) ([]transaction.Transaction, error) {
+	var trxs []model.Transaction
+	if err := postgres.Conn(ctx, r.db).SelectContext(ctx, &trxs, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	ids := make([]uuid.UUID, 0, len(trxs))
+	for _, trx := range trxs {
+		ids = append(ids, trx.ID)
+	}
+
+	items, err := r.getItems(ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]transaction.Transaction, 0, len(trxs))
+	for _, trx := range trxs {
+		result = append(result, toEntity(trx, items[trx.ID]))
+	}
+
+	return result, nil
+}
+
+// lockOne locks the first transaction returned by query, or returns ierr.TransactionNotFound.
+func (r Repository) lockOne(ctx context.Context, query string, args ...interface{}) (transaction.Transaction, error) {
+	var trx model.Transaction
+	if err := postgres.Conn(ctx, r.db).GetContext(ctx, &trx, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return transaction.Transaction{}, ierr.TransactionNotFound{}
+		}
+
+		return transaction.Transaction{}, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	items, err := r.getItems(ctx, trx.ID)
+	if err != nil {
+		return transaction.Transaction{}, err
+	}
+
+	return toEntity(trx, items[trx.ID]), nil
+}
+
+func (r Repository) getItems(ctx context.Context, transactionIDs ...uuid.UUID) (map[uuid.UUID][]model.TransactionItem, error) {
+	result := make(map[uuid.UUID][]model.TransactionItem)
+	if len(transactionIDs) == 0 {
+		return result, nil
+	}
+
+	ids := make([]string, 0, len(transactionIDs))
+	for _, id := range transactionIDs {
+		ids = append(ids, id.String())
+	}
+
+	var items []model.TransactionItem
+	if err := postgres.Conn(ctx, r.db).SelectContext(ctx, &items, "SELECT * FROM transaction_items WHERE transaction_id = ANY($1) ORDER BY position", pq.Array(ids)); err != nil {
+		return nil, fmt.Errorf("failed to query transaction items from database: %w", err)
+	}
+
+	for _, item := range items {
+		result[item.TransactionID] = append(result[item.TransactionID], item)
+	}
+
+	return result, nil
+}
+
+func NewRepository(db *sqlx.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type txKey struct{}
+
+// Executor is implemented by both *sqlx.DB and *sqlx.Tx.
+type Executor interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error)
+}
+
+// Transactor runs a function within a database transaction. Repositories which get their
+// executor through Conn take part in the transaction when called with the context given to it.
+type Transactor struct {
+	db *sqlx.DB
+}
+
+// WithinTransaction commits when fn returns nil and rolls back otherwise. Nested calls join
+// the outer transaction.
+func (t Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := t.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Conn returns the transaction started by WithinTransaction if ctx carries one, otherwise db.
+func Conn(ctx context.Context, db *sqlx.DB) Executor {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return tx
+	}
+
+	return db
+}
+
+func NewTransactor(db *sqlx.DB) *Transactor {
+	return &Transactor{
+		db: db,
+	}
+}
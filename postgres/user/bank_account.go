@@ -0,0 +1,228 @@
+package User
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"rekber/ierr"
+	"rekber/internal/user"
+	"rekber/postgres/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const selectBankAccountQuery = `SELECT ba.id, ba.user_id, ba.bank_code, b.name AS bank_name, ba.number, ba.name, ba.is_primary, ba.verified_at, ba.created_at
+	FROM bank_accounts ba JOIN banks b ON b.code = ba.bank_code`
+
+func (u Repository) ListBanks(ctx context.Context) ([]user.Bank, error) {
+	var banks []model.Bank
+	if err := u.db.SelectContext(ctx, &banks, "SELECT * FROM banks ORDER BY code"); err != nil {
+		return nil, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	result := make([]user.Bank, 0, len(banks))
+	for _, b := range banks {
+		result = append(result, user.Bank{
+			Code: b.Code,
+			Name: b.Name,
+		})
+	}
+
+	return result, nil
+}
+
+func (u Repository) GetBank(ctx context.Context, code string) (user.Bank, error) {
+	var b model.Bank
+	if err := u.db.GetContext(ctx, &b, "SELECT * FROM banks WHERE code = $1", code); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return user.Bank{}, ierr.BankNotFound{Code: code}
+		}
+
+		return user.Bank{}, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	return user.Bank{
+		Code: b.Code,
+		Name: b.Name,
+	}, nil
+}
+
+func (u Repository) SaveBankAccount(ctx context.Context, b user.BankAccount) (user.BankAccount, error) {
+	tx, err := u.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return user.BankAccount{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// the user row lock serializes concurrent adds, so only the first one sees no bank account
+	if _, err := tx.ExecContext(ctx, "SELECT id FROM users WHERE id = $1 FOR UPDATE", b.UserID); err != nil {
+		return user.BankAccount{}, fmt.Errorf("failed to lock user: %w", err)
+	}
+
+	var exists bool
+	if err := tx.GetContext(ctx, &exists, "SELECT EXISTS (SELECT 1 FROM bank_accounts WHERE user_id = $1)", b.UserID); err != nil {
+		return user.BankAccount{}, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	b.IsPrimary = !exists
+	m := model.BankAccount{
+		ID:        b.ID,
+		UserID:    b.UserID,
+		BankCode:  b.Bank.Code,
+		Number:    b.Number,
+		Name:      b.Name,
+		IsPrimary: b.IsPrimary,
+		CreatedAt: b.CreatedAt,
+	}
+
+	if _, err := tx.NamedExecContext(ctx, "INSERT INTO bank_accounts (id, user_id, bank_code, number, name, is_primary, created_at) VALUES (:id, :user_id, :bank_code, :number, :name, :is_primary, :created_at)", m); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint != "bank_accounts_primary_idx" {
+			return user.BankAccount{}, ierr.BankAccountAlreadyExists{BankCode: b.Bank.Code, Number: b.Number}
+		}
+
+		return user.BankAccount{}, fmt.Errorf("failed to insert bank account: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return user.BankAccount{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return b, nil
+}
+
+func (u Repository) ListBankAccounts(ctx context.Context, userID uuid.UUID) ([]user.BankAccount, error) {
+	var accounts []model.BankAccount
+	if err := u.db.SelectContext(ctx, &accounts, selectBankAccountQuery+" WHERE ba.user_id = $1 ORDER BY ba.created_at", userID); err != nil {
+		return nil, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	result := make([]user.BankAccount, 0, len(accounts))
+	for _, b := range accounts {
+		result = append(result, toBankAccount(b))
+	}
+
+	return result, nil
+}
+
+func (u Repository) SetPrimaryBankAccount(ctx context.Context, userID, id uuid.UUID) error {
+	tx, err := u.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// the partial unique index allows one primary per user, so unset the current one first
+	if _, err := tx.ExecContext(ctx, "UPDATE bank_accounts SET is_primary = FALSE WHERE user_id = $1 AND is_primary AND id <> $2", userID, id); err != nil {
+		return fmt.Errorf("failed to unset primary bank account: %w", err)
+	}
+
+	res, err := tx.ExecContext(ctx, "UPDATE bank_accounts SET is_primary = TRUE WHERE user_id = $1 AND id = $2", userID, id)
+	if err != nil {
+		return fmt.Errorf("failed to set primary bank account: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return ierr.BankAccountNotFound{ID: id}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (u Repository) DeleteBankAccount(ctx context.Context, userID, id uuid.UUID) error {
+	res, err := u.db.ExecContext(ctx, "DELETE FROM bank_accounts WHERE user_id = $1 AND id = $2", userID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete bank account: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return ierr.BankAccountNotFound{ID: id}
+	}
+
+	return nil
+}
+
+func (u Repository) HasPendingPayout(ctx context.Context, id uuid.UUID) (bool, error) {
+	var pending bool
+	if err := u.db.GetContext(ctx, &pending, "SELECT EXISTS(SELECT 1 FROM payouts WHERE bank_account_id = $1 AND status = 'pending')", id); err != nil {
+		return false, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	return pending, nil
+}
+
+func (u Repository) HasUnsettledSale(ctx context.Context, userID uuid.UUID) (bool, error) {
+	var unsettled bool
+	// status 4 is paid, 6 done by seller and 8 disputed
+	query := "SELECT EXISTS(SELECT 1 FROM transactions WHERE seller_id = $1 AND status IN (4, 6, 8))"
+	if err := u.db.GetContext(ctx, &unsettled, query, userID); err != nil {
+		return false, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	return unsettled, nil
+}
+
+func (u Repository) MarkBankAccountVerified(ctx context.Context, userID, id uuid.UUID, verifiedAt time.Time) error {
+	res, err := u.db.ExecContext(ctx, "UPDATE bank_accounts SET verified_at = $3 WHERE user_id = $1 AND id = $2", userID, id, verifiedAt)
+	if err != nil {
+		return fmt.Errorf("failed to mark bank account verified: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return ierr.BankAccountNotFound{ID: id}
+	}
+
+	return nil
+}
+
+// primaryBankAccount returns the primary bank account of the user, or an empty one when there is none.
+func (u Repository) primaryBankAccount(ctx context.Context, userID uuid.UUID) (user.BankAccount, error) {
+	var b model.BankAccount
+	if err := u.db.GetContext(ctx, &b, selectBankAccountQuery+" WHERE ba.user_id = $1 AND ba.is_primary", userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return user.BankAccount{}, nil
+		}
+
+		return user.BankAccount{}, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	return toBankAccount(b), nil
+}
+
+func toBankAccount(b model.BankAccount) user.BankAccount {
+	return user.BankAccount{
+		ID:     b.ID,
+		UserID: b.UserID,
+		Number: b.Number,
+		Name:   b.Name,
+		Bank: user.Bank{
+			Code: b.BankCode,
+			Name: b.BankName,
+		},
+		IsPrimary:  b.IsPrimary,
+		VerifiedAt: b.VerifiedAt.Time,
+		CreatedAt:  b.CreatedAt,
+	}
+}
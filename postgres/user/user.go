@@ -9,7 +9,9 @@ import (
 	"rekber/internal/user"
 	"rekber/postgres/model"
 
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type Repository struct {
@@ -26,11 +28,44 @@ func (u Repository) GetByPhoneNumber(ctx context.Context, phoneNumber string) (u
 		return user.User{}, fmt.Errorf("failed to query from database: %w", err)
 	}
 
+	bankAccount, err := u.primaryBankAccount(ctx, usr.ID)
+	if err != nil {
+		return user.User{}, err
+	}
+
 	return user.User{
 		ID:                    usr.ID,
 		PhoneNumber:           usr.PhoneNumber,
 		Name:                  usr.Name,
 		PhoneNumberVerifiedAt: usr.PhoneNumberVerifiedAt,
+		BankAccount:           bankAccount,
+		IsAdmin:               usr.IsAdmin,
+		CreatedAt:             usr.CreatedAt,
+	}, nil
+}
+
+func (u Repository) GetByID(ctx context.Context, id uuid.UUID) (user.User, error) {
+	var usr model.User
+	if err := u.db.GetContext(ctx, &usr, "SELECT * FROM users WHERE id = $1", id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return user.User{}, ierr.UserIDNotFound{ID: id}
+		}
+
+		return user.User{}, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	bankAccount, err := u.primaryBankAccount(ctx, usr.ID)
+	if err != nil {
+		return user.User{}, err
+	}
+
+	return user.User{
+		ID:                    usr.ID,
+		PhoneNumber:           usr.PhoneNumber,
+		Name:                  usr.Name,
+		PhoneNumberVerifiedAt: usr.PhoneNumberVerifiedAt,
+		BankAccount:           bankAccount,
+		IsAdmin:               usr.IsAdmin,
 		CreatedAt:             usr.CreatedAt,
 	}, nil
 }
@@ -49,6 +84,12 @@ func (u Repository) Save(ctx context.Context, user user.User) error {
 	_, err := tx.NamedExecContext(ctx, "INSERT INTO users (id, name, phone_number, phone_number_verified_at, created_at) VALUES (:id, :name, :phone_number, :phone_number_verified_at, :created_at)", userModel)
 	if err != nil {
 		tx.Rollback()
+
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ierr.UserAlreadyExists{PhoneNumber: user.PhoneNumber}
+		}
+
 		return fmt.Errorf("failed to insert user: %w", err)
 	}
 
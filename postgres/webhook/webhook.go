@@ -0,0 +1,279 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"rekber/ierr"
+	"rekber/internal/webhook"
+	"rekber/postgres"
+	"rekber/postgres/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const (
+	insertEndpointQuery = `INSERT INTO webhook_endpoints (id, partner_id, url, secret, created_at, disabled_at)
+		VALUES (:id, :partner_id, :url, :secret, :created_at, :disabled_at)`
+
+	updateEndpointQuery = `UPDATE webhook_endpoints SET url = :url, disabled_at = :disabled_at WHERE id = :id`
+
+	insertDeliveryQuery = `INSERT INTO webhook_deliveries (id, endpoint_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_response_code, last_error, delivered_at, failed_at, created_at)
+		VALUES (:id, :endpoint_id, :event_id, :event_type, :payload, :status, :attempts, :next_attempt_at, :last_response_code, :last_error, :delivered_at, :failed_at, :created_at)
+		ON CONFLICT (endpoint_id, event_id) DO NOTHING`
+
+	updateDeliveryQuery = `UPDATE webhook_deliveries SET
+		status = :status, attempts = :attempts, next_attempt_at = :next_attempt_at, last_response_code = :last_response_code,
+		last_error = :last_error, delivered_at = :delivered_at, failed_at = :failed_at
+		WHERE id = :id`
+
+	insertAttemptQuery = `INSERT INTO webhook_delivery_attempts (id, delivery_id, response_code, response_body, error, duration_ms, created_at)
+		VALUES (:id, :delivery_id, :response_code, :response_body, :error, :duration_ms, :created_at)`
+
+	// listDeliveriesLimit is how many of the latest deliveries of an endpoint are listed.
+	listDeliveriesLimit = 100
+)
+
+type Repository struct {
+	db *sqlx.DB
+}
+
+func (r Repository) SaveEndpoint(ctx context.Context, e webhook.Endpoint) error {
+	if _, err := postgres.Conn(ctx, r.db).NamedExecContext(ctx, insertEndpointQuery, toEndpointModel(e)); err != nil {
+		return fmt.Errorf("failed to insert webhook endpoint: %w", err)
+	}
+
+	return nil
+}
+
+func (r Repository) GetEndpoint(ctx context.Context, id uuid.UUID) (webhook.Endpoint, error) {
+	var e model.WebhookEndpoint
+	if err := postgres.Conn(ctx, r.db).GetContext(ctx, &e, "SELECT * FROM webhook_endpoints WHERE id = $1", id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return webhook.Endpoint{}, ierr.WebhookEndpointNotFound{ID: id}
+		}
+
+		return webhook.Endpoint{}, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	return toEndpointEntity(e), nil
+}
+
+func (r Repository) ListEndpoints(ctx context.Context, partnerID uuid.UUID) ([]webhook.Endpoint, error) {
+	var endpoints []model.WebhookEndpoint
+	if err := postgres.Conn(ctx, r.db).SelectContext(ctx, &endpoints, "SELECT * FROM webhook_endpoints WHERE partner_id = $1 ORDER BY created_at", partnerID); err != nil {
+		return nil, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	return toEndpointEntities(endpoints), nil
+}
+
+func (r Repository) ListActiveEndpoints(ctx context.Context, partnerIDs []uuid.UUID) ([]webhook.Endpoint, error) {
+	if len(partnerIDs) == 0 {
+		return nil, nil
+	}
+
+	var endpoints []model.WebhookEndpoint
+	query := "SELECT * FROM webhook_endpoints WHERE partner_id = ANY($1) AND disabled_at IS NULL ORDER BY created_at"
+	if err := postgres.Conn(ctx, r.db).SelectContext(ctx, &endpoints, query, pq.Array(uuidStrings(partnerIDs))); err != nil {
+		return nil, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	return toEndpointEntities(endpoints), nil
+}
+
+func (r Repository) UpdateEndpoint(ctx context.Context, e webhook.Endpoint) error {
+	if _, err := postgres.Conn(ctx, r.db).NamedExecContext(ctx, updateEndpointQuery, toEndpointModel(e)); err != nil {
+		return fmt.Errorf("failed to update webhook endpoint: %w", err)
+	}
+
+	return nil
+}
+
+func (r Repository) SaveDelivery(ctx context.Context, d webhook.Delivery) error {
+	if _, err := postgres.Conn(ctx, r.db).NamedExecContext(ctx, insertDeliveryQuery, toDeliveryModel(d)); err != nil {
+		return fmt.Errorf("failed to insert webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+func (r Repository) LockDelivery(ctx context.Context, id uuid.UUID) (webhook.Delivery, error) {
+	var d model.WebhookDelivery
+	if err := postgres.Conn(ctx, r.db).GetContext(ctx, &d, "SELECT * FROM webhook_deliveries WHERE id = $1 FOR UPDATE", id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return webhook.Delivery{}, ierr.WebhookDeliveryNotFound{ID: id}
+		}
+
+		return webhook.Delivery{}, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	return toDeliveryEntity(d), nil
+}
+
+func (r Repository) LockDueDelivery(ctx context.Context, now time.Time) (webhook.Delivery, error) {
+	var d model.WebhookDelivery
+	query := "SELECT * FROM webhook_deliveries WHERE status = 'pending' AND next_attempt_at <= $1 ORDER BY next_attempt_at LIMIT 1 FOR UPDATE SKIP LOCKED"
+	if err := postgres.Conn(ctx, r.db).GetContext(ctx, &d, query, now); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return webhook.Delivery{}, ierr.WebhookDeliveryNotFound{}
+		}
+
+		return webhook.Delivery{}, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	return toDeliveryEntity(d), nil
+}
+
+func (r Repository) ListDeliveries(ctx context.Context, endpointID uuid.UUID) ([]webhook.Delivery, error) {
+	var deliveries []model.WebhookDelivery
+	query := "SELECT * FROM webhook_deliveries WHERE endpoint_id = $1 ORDER BY created_at DESC LIMIT $2"
+	if err := postgres.Conn(ctx, r.db).SelectContext(ctx, &deliveries, query, endpointID, listDeliveriesLimit); err != nil {
+		return nil, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	result := make([]webhook.Delivery, 0, len(deliveries))
+	for _, d := range deliveries {
+		result = append(result, toDeliveryEntity(d))
+	}
+
+	return result, nil
+}
+
+func (r Repository) UpdateDelivery(ctx context.Context, d webhook.Delivery) error {
+	if _, err := postgres.Conn(ctx, r.db).NamedExecContext(ctx, updateDeliveryQuery, toDeliveryModel(d)); err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+func (r Repository) SaveAttempt(ctx context.Context, a webhook.Attempt) error {
+	attempt := model.WebhookAttempt{
+		ID:           a.ID,
+		DeliveryID:   a.DeliveryID,
+		ResponseCode: a.ResponseCode,
+		ResponseBody: a.ResponseBody,
+		Error:        a.Error,
+		DurationMs:   a.Duration.Milliseconds(),
+		CreatedAt:    a.CreatedAt,
+	}
+	if _, err := postgres.Conn(ctx, r.db).NamedExecContext(ctx, insertAttemptQuery, attempt); err != nil {
+		return fmt.Errorf("failed to insert webhook delivery attempt: %w", err)
+	}
+
+	return nil
+}
+
+func (r Repository) ListAttempts(ctx context.Context, deliveryIDs []uuid.UUID) ([]webhook.Attempt, error) {
+	if len(deliveryIDs) == 0 {
+		return nil, nil
+	}
+
+	var attempts []model.WebhookAttempt
+	query := "SELECT * FROM webhook_delivery_attempts WHERE delivery_id = ANY($1) ORDER BY created_at"
+	if err := postgres.Conn(ctx, r.db).SelectContext(ctx, &attempts, query, pq.Array(uuidStrings(deliveryIDs))); err != nil {
+		return nil, fmt.Errorf("failed to query from database: %w", err)
+	}
+
+	result := make([]webhook.Attempt, 0, len(attempts))
+	for _, a := range attempts {
+		result = append(result, webhook.Attempt{
+			ID:           a.ID,
+			DeliveryID:   a.DeliveryID,
+			ResponseCode: a.ResponseCode,
+			ResponseBody: a.ResponseBody,
+			Error:        a.Error,
+			Duration:     time.Duration(a.DurationMs) * time.Millisecond,
+			CreatedAt:    a.CreatedAt,
+		})
+	}
+
+	return result, nil
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	result := make([]string, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, id.String())
+	}
+
+	return result
+}
+
+func toEndpointModel(e webhook.Endpoint) model.WebhookEndpoint {
+	return model.WebhookEndpoint{
+		ID:         e.ID,
+		PartnerID:  e.PartnerID,
+		URL:        e.URL,
+		Secret:     e.Secret,
+		CreatedAt:  e.CreatedAt,
+		DisabledAt: model.NewNullTime(e.DisabledAt),
+	}
+}
+
+func toEndpointEntity(e model.WebhookEndpoint) webhook.Endpoint {
+	return webhook.Endpoint{
+		ID:         e.ID,
+		PartnerID:  e.PartnerID,
+		URL:        e.URL,
+		Secret:     e.Secret,
+		CreatedAt:  e.CreatedAt,
+		DisabledAt: e.DisabledAt.Time,
+	}
+}
+
+func toEndpointEntities(endpoints []model.WebhookEndpoint) []webhook.Endpoint {
+	result := make([]webhook.Endpoint, 0, len(endpoints))
+	for _, e := range endpoints {
+		result = append(result, toEndpointEntity(e))
+	}
+
+	return result
+}
+
+func toDeliveryModel(d webhook.Delivery) model.WebhookDelivery {
+	return model.WebhookDelivery{
+		ID:               d.ID,
+		EndpointID:       d.EndpointID,
+		EventID:          d.EventID,
+		EventType:        d.EventType,
+		Payload:          d.Payload,
+		Status:           string(d.Status),
+		Attempts:         d.Attempts,
+		NextAttemptAt:    d.NextAttemptAt,
+		LastResponseCode: d.LastResponseCode,
+		LastError:        d.LastError,
+		DeliveredAt:      model.NewNullTime(d.DeliveredAt),
+		FailedAt:         model.NewNullTime(d.FailedAt),
+		CreatedAt:        d.CreatedAt,
+	}
+}
+
+func toDeliveryEntity(d model.WebhookDelivery) webhook.Delivery {
+	return webhook.Delivery{
+		ID:               d.ID,
+		EndpointID:       d.EndpointID,
+		EventID:          d.EventID,
+		EventType:        d.EventType,
+		Payload:          d.Payload,
+		Status:           webhook.DeliveryStatus(d.Status),
+		Attempts:         d.Attempts,
+		NextAttemptAt:    d.NextAttemptAt,
+		LastResponseCode: d.LastResponseCode,
+		LastError:        d.LastError,
+		DeliveredAt:      d.DeliveredAt.Time,
+		FailedAt:         d.FailedAt.Time,
+		CreatedAt:        d.CreatedAt,
+	}
+}
+
+func NewRepository(db *sqlx.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
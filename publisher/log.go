@@ -0,0 +1,20 @@
+package publisher
+
+import (
+	"context"
+	"log"
+	"rekber/internal/outbox"
+)
+
+// LogPublisher is a publisher for development and testing, it logs the message instead of
+// sending it anywhere.
+type LogPublisher struct{}
+
+func (LogPublisher) Publish(ctx context.Context, m outbox.Message) error {
+	log.Printf("publish message %s on %s with key %s: %s", m.ID, m.Topic, m.Key, m.Payload)
+	return nil
+}
+
+func NewLogPublisher() *LogPublisher {
+	return &LogPublisher{}
+}
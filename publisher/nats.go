@@ -0,0 +1,141 @@
+package publisher
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"rekber/internal/outbox"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NATSPublisher publishes every message to the subject prefix.topic of a NATS compatible server.
+// It speaks the plain text client protocol so no client library is needed, and waits for the PONG
+// after every PUB so a message is only marked published once the server has processed it.
+type NATSPublisher struct {
+	addr    string
+	prefix  string
+	timeout time.Duration
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func (p *NATSPublisher) Publish(ctx context.Context, m outbox.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.publish(ctx, m); err != nil {
+		// the connection is in an unknown state, the next message dials a new one
+		p.close()
+		return err
+	}
+
+	return nil
+}
+
+func (p *NATSPublisher) publish(ctx context.Context, m outbox.Message) error {
+	if p.conn == nil {
+		if err := p.connect(ctx); err != nil {
+			return err
+		}
+	}
+
+	deadline := time.Now().Add(p.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	p.conn.SetDeadline(deadline)
+
+	subject := m.Topic
+	if p.prefix != "" {
+		subject = p.prefix + "." + m.Topic
+	}
+
+	cmd := fmt.Sprintf("PUB %s %d\r\n%s\r\nPING\r\n", subject, len(m.Payload), m.Payload)
+	if _, err := p.conn.Write([]byte(cmd)); err != nil {
+		return fmt.Errorf("failed to write to nats: %w", err)
+	}
+
+	return p.waitPong()
+}
+
+func (p *NATSPublisher) connect(ctx context.Context) error {
+	d := net.Dialer{Timeout: p.timeout}
+	conn, err := d.DialContext(ctx, "tcp", p.addr)
+	if err != nil {
+		return fmt.Errorf("failed to dial nats: %w", err)
+	}
+
+	p.conn = conn
+	p.reader = bufio.NewReader(conn)
+	p.conn.SetDeadline(time.Now().Add(p.timeout))
+
+	line, err := p.reader.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("failed to read nats info: %w", err)
+	}
+
+	if !strings.HasPrefix(line, "INFO") {
+		return fmt.Errorf("unexpected nats greeting: %s", strings.TrimSpace(line))
+	}
+
+	options, err := json.Marshal(map[string]interface{}{
+		"verbose":  false,
+		"pedantic": false,
+		"name":     "rekber-outbox",
+		"lang":     "go",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal json: %w", err)
+	}
+
+	if _, err := fmt.Fprintf(p.conn, "CONNECT %s\r\n", options); err != nil {
+		return fmt.Errorf("failed to write to nats: %w", err)
+	}
+
+	return nil
+}
+
+// waitPong reads until the PONG answering our PING, answering the server's own PINGs on the way.
+func (p *NATSPublisher) waitPong() error {
+	for {
+		line, err := p.reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("failed to read from nats: %w", err)
+		}
+
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := p.conn.Write([]byte("PONG\r\n")); err != nil {
+				return fmt.Errorf("failed to write to nats: %w", err)
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("nats responded with %s", line)
+		}
+	}
+}
+
+func (p *NATSPublisher) close() {
+	if p.conn != nil {
+		p.conn.Close()
+	}
+
+	p.conn = nil
+	p.reader = nil
+}
+
+func NewNATSPublisher(addr, subjectPrefix string, timeout time.Duration) *NATSPublisher {
+	return &NATSPublisher{
+		addr:    addr,
+		prefix:  subjectPrefix,
+		timeout: timeout,
+	}
+}
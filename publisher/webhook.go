@@ -0,0 +1,52 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"rekber/internal/outbox"
+	"time"
+)
+
+// WebhookPublisher posts every message as JSON to a single URL. Any response other than 2xx is
+// a failed attempt, the receiver deduplicates retries by the X-Message-ID header.
+type WebhookPublisher struct {
+	url    string
+	client *http.Client
+}
+
+func (p *WebhookPublisher) Publish(ctx context.Context, m outbox.Message) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(m.Payload))
+	if err != nil {
+		return fmt.Errorf("failed to create new req with context: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Message-ID", m.ID.String())
+	req.Header.Set("X-Message-Topic", m.Topic)
+	req.Header.Set("X-Message-Key", m.Key)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// drained so the connection can be reused
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook responded with status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func NewWebhookPublisher(url string, timeout time.Duration) *WebhookPublisher {
+	return &WebhookPublisher{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
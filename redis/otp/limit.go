@@ -0,0 +1,47 @@
+package otp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// hitScript increments the counter and starts its window on the first hit in one round trip,
+// so a crash between the two cannot leave a counter without expiry.
+var hitScript = redis.NewScript(`
+local hits = redis.call("INCR", KEYS[1])
+if hits == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return {hits, redis.call("PTTL", KEYS[1])}
+`)
+
+// RateLimiter keeps OTP rate limit counters in Redis, each window ends by the key TTL.
+type RateLimiter struct {
+	client *redis.Client
+}
+
+func (l *RateLimiter) Hit(ctx context.Context, key string, window time.Duration) (int, time.Time, error) {
+	res, err := hitScript.Run(ctx, l.client, []string{key}, window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to count hit: %w", err)
+	}
+
+	return int(res[0]), time.Now().Add(time.Duration(res[1]) * time.Millisecond), nil
+}
+
+func (l *RateLimiter) Reset(ctx context.Context, key string) error {
+	if err := l.client.Del(ctx, key).Err(); err != nil {
+		return fmt.Errorf("failed to reset hits: %w", err)
+	}
+
+	return nil
+}
+
+func NewRateLimiter(client *redis.Client) *RateLimiter {
+	return &RateLimiter{
+		client: client,
+	}
+}
@@ -0,0 +1,50 @@
+package otp
+
+import (
+	"context"
+	"fmt"
+	"rekber/ierr"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// VerifiedOTPRepository keeps verified OTP in Redis or any server speaking its protocol,
+// the grant expires by the key TTL.
+type VerifiedOTPRepository struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func (r *VerifiedOTPRepository) SaveVerifiedOTP(ctx context.Context, phoneNumber string, state int) error {
+	if err := r.client.Set(ctx, key(phoneNumber, state), 1, r.ttl).Err(); err != nil {
+		return fmt.Errorf("failed to save verified otp: %w", err)
+	}
+
+	return nil
+}
+
+func (r *VerifiedOTPRepository) ConsumeVerifiedOTP(ctx context.Context, phoneNumber string, state int) error {
+	// DEL is atomic, only one of concurrent requests gets the key deleted
+	deleted, err := r.client.Del(ctx, key(phoneNumber, state)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to consume verified otp: %w", err)
+	}
+
+	if deleted == 0 {
+		return ierr.UserForbiddenAccess{PhoneNumber: phoneNumber}
+	}
+
+	return nil
+}
+
+func key(phoneNumber string, state int) string {
+	return fmt.Sprintf("verified_otp:%s:%d", phoneNumber, state)
+}
+
+func NewVerifiedOTPRepository(client *redis.Client, ttl time.Duration) *VerifiedOTPRepository {
+	return &VerifiedOTPRepository{
+		client: client,
+		ttl:    ttl,
+	}
+}
@@ -0,0 +1,83 @@
+package otp
+
+import (
+	"context"
+	"errors"
+	"os"
+	"rekber/ierr"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// testClient connects to the Redis server at TEST_REDIS_ADDR or skips the test.
+func testClient(t *testing.T) *redis.Client {
+	addr := os.Getenv("TEST_REDIS_ADDR")
+	if addr == "" {
+		t.Skip("TEST_REDIS_ADDR is not set")
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		t.Fatalf("failed to ping to redis: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
+
+func TestVerifiedOTPRepository_ConsumeVerifiedOTP(t *testing.T) {
+	client := testClient(t)
+
+	phoneNumber := "+6281234567890"
+	forbidden := ierr.UserForbiddenAccess{PhoneNumber: phoneNumber}
+
+	tests := []struct {
+		name      string
+		saveState int
+		state     int
+		elapsed   time.Duration
+		wantErrs  []error
+	}{
+		{
+			name:      "verified otp is consumed only once",
+			saveState: 1,
+			state:     1,
+			wantErrs:  []error{nil, forbidden},
+		},
+		{
+			name:      "expired verified otp is refused",
+			saveState: 1,
+			state:     1,
+			elapsed:   300 * time.Millisecond,
+			wantErrs:  []error{forbidden, forbidden},
+		},
+		{
+			name:      "verified otp of another state is refused",
+			saveState: 1,
+			state:     2,
+			wantErrs:  []error{forbidden},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the key expires on the server, so the test waits for it rather than patching the clock
+			r := NewVerifiedOTPRepository(client, 200*time.Millisecond)
+			if err := client.Del(context.Background(), key(phoneNumber, 1), key(phoneNumber, 2)).Err(); err != nil {
+				t.Fatalf("failed to clean verified otps: %v", err)
+			}
+
+			if err := r.SaveVerifiedOTP(context.Background(), phoneNumber, tt.saveState); err != nil {
+				t.Fatalf("VerifiedOTPRepository.SaveVerifiedOTP() error = %v", err)
+			}
+
+			time.Sleep(tt.elapsed)
+			for i, wantErr := range tt.wantErrs {
+				if err := r.ConsumeVerifiedOTP(context.Background(), phoneNumber, tt.state); !errors.Is(err, wantErr) {
+					t.Errorf("VerifiedOTPRepository.ConsumeVerifiedOTP() #%d error = %v, wantErr %v", i, err, wantErr)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package requestid
+
+import "context"
+
+type contextKey struct{}
+
+// Key is the context key of the request ID. Fiber keeps locals as values of the request context,
+// so the ID stored in locals under Key is found by FromContext too.
+var Key = contextKey{}
+
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, Key, id)
+}
+
+// FromContext returns the ID of the request ctx belongs to, it is empty for background jobs.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(Key).(string)
+	return id
+}
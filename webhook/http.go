@@ -0,0 +1,90 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"rekber/internal/webhook"
+	"syscall"
+	"time"
+)
+
+// responseLimit is how much of the response body is read, partners only need its start to debug.
+const responseLimit = 4 << 10
+
+// HTTPSender posts webhook payloads to partner endpoints.
+type HTTPSender struct {
+	client *http.Client
+}
+
+func (s *HTTPSender) Send(ctx context.Context, r webhook.Request) (webhook.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.URL, bytes.NewReader(r.Body))
+	if err != nil {
+		return webhook.Response{}, fmt.Errorf("failed to create new req with context: %w", err)
+	}
+
+	req.Header.Set("User-Agent", "rekber-webhook/1.0")
+	for k, v := range r.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return webhook.Response{}, fmt.Errorf("failed to do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, responseLimit))
+	if err != nil {
+		return webhook.Response{StatusCode: resp.StatusCode}, nil
+	}
+
+	return webhook.Response{StatusCode: resp.StatusCode, Body: body}, nil
+}
+
+// errNotPublicAddress is returned when an endpoint resolves to a non public address at send time,
+// e.g. its DNS record changed after the registration.
+var errNotPublicAddress = errors.New("endpoint does not resolve to a public address")
+
+// dialPublic refuses to connect to anything but a public address, it runs after the host is
+// resolved so it also covers DNS records changed after the endpoint is registered.
+func dialPublic(network, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("failed to split host port: %w", err)
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !webhook.IsPublicIP(ip) {
+		return fmt.Errorf("failed to dial %s: %w", address, errNotPublicAddress)
+	}
+
+	return nil
+}
+
+func NewHTTPSender(timeout time.Duration) *HTTPSender {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// a proxy would be dialed instead of the endpoint, skipping the address check
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   dialPublic,
+	}).DialContext
+
+	return &HTTPSender{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			// a redirect is a response of the endpoint, following it would post the signed payload
+			// somewhere the partner has not registered
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
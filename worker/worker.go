@@ -0,0 +1,26 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Run calls job every interval until ctx is done. An error is logged and the job is called
+// again on the next tick.
+func Run(ctx context.Context, name string, interval time.Duration, job func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := job(ctx); err != nil {
+			log.Printf("worker %s failed: %v", name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}